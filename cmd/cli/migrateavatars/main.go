@@ -0,0 +1,52 @@
+package main
+
+// Moves channel avatars saved as data urls in the database to blob storage. Safe to run more than once,
+// only channels with a data url and no blob storage avatar are migrated
+// example usage: go run cmd/cli/migrateavatars/main.go -batch=100
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/joincivil/civil-api-server/pkg/blobstore"
+	"github.com/joincivil/civil-api-server/pkg/channels"
+	"github.com/joincivil/civil-api-server/pkg/graphqlmain"
+	"github.com/joincivil/civil-api-server/pkg/images"
+	"github.com/joincivil/civil-api-server/pkg/utils"
+	"go.uber.org/fx"
+)
+
+// parsed along with the graphql config flags by graphqlmain.BuildConfig
+var batchSize = flag.Int("batch", 100, "number of channels to load at a time")
+
+func main() {
+	app := fx.New(
+		fx.Provide(
+			graphqlmain.NewGorm,
+			graphqlmain.BuildConfig,
+			blobstore.NewStoreFromConfig,
+			images.NewPipeline,
+			channels.NewDBPersister,
+			func(dbPersister *channels.DBPersister) channels.Persister {
+				return dbPersister
+			},
+			channels.NewAvatarMigrator,
+		),
+		fx.Invoke(func(migrator *channels.AvatarMigrator, config *utils.GraphQLConfig) {
+			fmt.Printf("DB Host: %v\n", config.PersisterPostgresAddress)
+			fmt.Printf("Blob store: %v\n", config.BlobstoreBackend)
+
+			migrated, failed, err := migrator.Migrate(*batchSize)
+			fmt.Printf("migrated %v avatars, %v failed\n", migrated, failed)
+			if err != nil {
+				fmt.Printf("error migrating avatars: %v\n", err)
+				os.Exit(1)
+			}
+
+			os.Exit(0)
+		}),
+	)
+
+	app.Run()
+}
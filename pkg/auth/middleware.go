@@ -32,7 +32,13 @@ type contextKey struct {
 // Middleware decodes the `authorization` header jwt token and puts into context
 // The authorization header must be of format
 // "Authorization: Bearer <JWT token>"
-func Middleware(jwt *utils.JwtTokenGenerator) func(http.Handler) http.Handler {
+// Tokens for users in `adminUserIDs` are marked as platform admins
+func Middleware(jwt *utils.JwtTokenGenerator, adminUserIDs []string) func(http.Handler) http.Handler {
+	admins := make(map[string]bool, len(adminUserIDs))
+	for _, userID := range adminUserIDs {
+		admins[userID] = true
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -45,7 +51,7 @@ func Middleware(jwt *utils.JwtTokenGenerator) func(http.Handler) http.Handler {
 				return
 			}
 
-			token, err := validateDecodeToken(jwt, authHeader)
+			token, err := validateDecodeToken(jwt, authHeader, admins)
 			if err != nil {
 				code, msg := parseValidationErrorToCodeMsg(err)
 
@@ -86,7 +92,7 @@ func parseValidationErrorToCodeMsg(err error) (string, string) {
 	return code, msg
 }
 
-func validateDecodeToken(jwt *utils.JwtTokenGenerator, authHeader string) (*Token, error) {
+func validateDecodeToken(jwt *utils.JwtTokenGenerator, authHeader string, admins map[string]bool) (*Token, error) {
 
 	tokenRune := []rune(authHeader)
 
@@ -102,9 +108,10 @@ func validateDecodeToken(jwt *utils.JwtTokenGenerator, authHeader string) (*Toke
 		return nil, err
 	}
 
+	sub := claims["sub"].(string)
 	token := Token{
-		Sub:     claims["sub"].(string),
-		IsAdmin: true,
+		Sub:     sub,
+		IsAdmin: admins[sub],
 	}
 	return &token, nil
 }
@@ -0,0 +1,71 @@
+package blobstore
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/joincivil/civil-api-server/pkg/utils"
+)
+
+const (
+	// BackendLocal stores blobs on the local filesystem
+	BackendLocal = "local"
+	// BackendS3 stores blobs in an S3 compatible bucket
+	BackendS3 = "s3"
+)
+
+var (
+	// ErrorNotFound is returned when a blob doesn't exist
+	ErrorNotFound = errors.New("blob not found")
+	// ErrorBadKey is returned when a key is empty or contains a segment that isn't allowed
+	ErrorBadKey = errors.New("bad blob key")
+	// ErrorUnknownBackend is returned when the configured backend isn't local or s3
+	ErrorUnknownBackend = errors.New("unknown blob store backend")
+
+	keySegmentRegexp = regexp.MustCompile(`^[A-Za-z0-9_\-.]+$`)
+)
+
+// Store saves blobs, such as images, by key and provides the URLs they are served from
+type Store interface {
+	Put(key string, contentType string, data []byte) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+	URL(key string) string
+}
+
+// NewStoreFromConfig builds the Store for the backend set in the graphql config
+func NewStoreFromConfig(config *utils.GraphQLConfig) (Store, error) {
+	switch config.BlobstoreBackend {
+	case BackendLocal, "":
+		return NewLocalStore(config.BlobstoreLocalDir, config.BlobstoreBaseURL), nil
+	case BackendS3:
+		return NewS3Store(S3Config{
+			Endpoint:        config.BlobstoreS3Endpoint,
+			Region:          config.BlobstoreS3Region,
+			Bucket:          config.BlobstoreS3Bucket,
+			AccessKeyID:     config.BlobstoreS3AccessKeyID,
+			SecretAccessKey: config.BlobstoreS3SecretAccessKey,
+			BaseURL:         config.BlobstoreBaseURL,
+		}, nil), nil
+	}
+	return nil, ErrorUnknownBackend
+}
+
+// validateKey checks a key is made of slash separated segments of letters, digits, dashes, underscores and dots,
+// none of which refer to a parent directory
+func validateKey(key string) error {
+	if key == "" {
+		return ErrorBadKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." || !keySegmentRegexp.MatchString(segment) {
+			return ErrorBadKey
+		}
+	}
+	return nil
+}
+
+func joinURL(baseURL string, key string) string {
+	return strings.TrimRight(baseURL, "/") + "/" + key
+}
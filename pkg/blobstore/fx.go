@@ -0,0 +1,10 @@
+package blobstore
+
+import "go.uber.org/fx"
+
+// BlobModule builds the blob store for the configured backend
+var BlobModule = fx.Options(
+	fx.Provide(
+		NewStoreFromConfig,
+	),
+)
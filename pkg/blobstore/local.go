@@ -0,0 +1,94 @@
+package blobstore
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore is a Store that keeps blobs as files in a directory, for development and single server deployments
+type LocalStore struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalStore builds a LocalStore that keeps blobs in `dir`, served from `baseURL`
+func NewLocalStore(dir string, baseURL string) *LocalStore {
+	return &LocalStore{dir: dir, baseURL: baseURL}
+}
+
+// Put saves a blob, replacing any blob with the same key
+func (s *LocalStore) Put(key string, contentType string, data []byte) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so that a partially written blob is never served
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // nolint: errcheck
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close() // nolint: errcheck, gosec
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get retrieves a blob
+func (s *LocalStore) Get(key string) ([]byte, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, ErrorNotFound
+	}
+	return data, err
+}
+
+// Delete removes a blob, deleting a blob that doesn't exist is not an error
+func (s *LocalStore) Delete(key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	err := os.Remove(s.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// URL returns the URL a blob is served from
+func (s *LocalStore) URL(key string) string {
+	return joinURL(s.baseURL, key)
+}
+
+// Handler serves the blobs in the store, it should be mounted with the path of the base URL stripped
+func (s *LocalStore) Handler() http.Handler {
+	files := http.FileServer(http.Dir(s.dir))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if validateKey(strings.TrimPrefix(r.URL.Path, "/")) != nil {
+			http.NotFound(w, r)
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+}
+
+func (s *LocalStore) path(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(key))
+}
@@ -0,0 +1,88 @@
+package blobstore_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/joincivil/civil-api-server/pkg/blobstore"
+)
+
+func newLocalStore(t *testing.T) (*blobstore.LocalStore, func()) {
+	dir, err := ioutil.TempDir("", "blobstore")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	return blobstore.NewLocalStore(dir, "http://localhost:8080/v1/blobs/"), func() {
+		os.RemoveAll(dir) // nolint: errcheck
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	store, cleanup := newLocalStore(t)
+	defer cleanup()
+
+	err := store.Put("boosts/1/cover.png", "image/png", []byte("cover"))
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	data, err := store.Get("boosts/1/cover.png")
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if string(data) != "cover" {
+		t.Fatalf("expected the stored blob, got %v", string(data))
+	}
+	if store.URL("boosts/1/cover.png") != "http://localhost:8080/v1/blobs/boosts/1/cover.png" {
+		t.Fatalf("unexpected blob URL: %v", store.URL("boosts/1/cover.png"))
+	}
+
+	if err = store.Delete("boosts/1/cover.png"); err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if _, err = store.Get("boosts/1/cover.png"); err != blobstore.ErrorNotFound {
+		t.Fatalf("was expecting not found error: %v", err)
+	}
+	if err = store.Delete("boosts/1/cover.png"); err != nil {
+		t.Fatalf("was not expecting an error deleting a missing blob: %v", err)
+	}
+
+	for _, key := range []string{"", "../secret", "boosts//cover.png", "boosts/./cover.png", "boosts/cover image.png"} {
+		if err = store.Put(key, "image/png", []byte("bad")); err != blobstore.ErrorBadKey {
+			t.Fatalf("was expecting bad key error for %q: %v", key, err)
+		}
+	}
+}
+
+func TestLocalStoreHandler(t *testing.T) {
+	store, cleanup := newLocalStore(t)
+	defer cleanup()
+
+	if err := store.Put("boosts/1/cover.png", "image/png", []byte("cover")); err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+
+	server := httptest.NewServer(store.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/boosts/1/cover.png")
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body) // nolint: errcheck
+	resp.Body.Close()                    // nolint: errcheck
+	if resp.StatusCode != http.StatusOK || string(body) != "cover" {
+		t.Fatalf("expected the blob to be served, got %v", resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/boosts/1/")
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("was not expecting directories to be listed, got %v", resp.StatusCode)
+	}
+}
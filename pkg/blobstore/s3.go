@@ -0,0 +1,214 @@
+package blobstore
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultS3Region  = "us-east-1"
+	defaultS3Timeout = 30 * time.Second
+
+	s3Service       = "s3"
+	s3SignAlgorithm = "AWS4-HMAC-SHA256"
+	s3DateFormat    = "20060102T150405Z"
+)
+
+// S3Config configures an S3Store
+type S3Config struct {
+	// Endpoint is the base URL of the S3 compatible API, such as https://s3.us-east-1.amazonaws.com
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// BaseURL is the base URL blobs are served from, such as a CDN in front of the bucket.
+	// If not set, blobs are served directly from the bucket
+	BaseURL string
+}
+
+// S3Store is a Store that keeps blobs in a bucket of an S3 compatible API, using path style requests
+// signed with AWS Signature Version 4
+type S3Store struct {
+	config S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Store builds an S3Store, using a default http.Client if `client` is nil
+func NewS3Store(config S3Config, client *http.Client) *S3Store {
+	if config.Region == "" {
+		config.Region = defaultS3Region
+	}
+	if client == nil {
+		client = &http.Client{Timeout: defaultS3Timeout}
+	}
+	return &S3Store{config: config, client: client, now: time.Now}
+}
+
+// Put saves a blob, replacing any blob with the same key
+func (s *S3Store) Put(key string, contentType string, data []byte) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, s.objectURL(key), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp)
+	}
+	return nil
+}
+
+// Get retrieves a blob
+func (s *S3Store) Get(key string) ([]byte, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrorNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, s3Error(resp)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// Delete removes a blob, deleting a blob that doesn't exist is not an error
+func (s *S3Store) Delete(key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	}
+	return s3Error(resp)
+}
+
+// URL returns the URL a blob is served from
+func (s *S3Store) URL(key string) string {
+	if s.config.BaseURL != "" {
+		return joinURL(s.config.BaseURL, key)
+	}
+	return s.objectURL(key)
+}
+
+func (s *S3Store) objectURL(key string) string {
+	return joinURL(s.config.Endpoint, s.config.Bucket+"/"+key)
+}
+
+func (s *S3Store) do(req *http.Request, payload []byte) (*http.Response, error) {
+	sum := sha256.Sum256(payload)
+	signRequest(req, hex.EncodeToString(sum[:]), s.config.Region, s.config.AccessKeyID, s.config.SecretAccessKey, s.now().UTC())
+	return s.client.Do(req)
+}
+
+// signRequest adds an AWS Signature Version 4 Authorization header to a request, signing the host and every
+// header already set. Requests are expected to have no query string
+func signRequest(req *http.Request, payloadHash string, region string, accessKeyID string, secretAccessKey string, t time.Time) {
+	amzDate := t.Format(s3DateFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		trimmed := make([]string, len(values))
+		for i, value := range values {
+			trimmed[i] = strings.TrimSpace(value)
+		}
+		headers[strings.ToLower(name)] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{t.Format("20060102"), region, s3Service, "aws4_request"}, "/")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{s3SignAlgorithm, amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), t.Format("20060102"))
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3SignAlgorithm, accessKeyID, scope, signedHeaders, signature))
+}
+
+func canonicalURI(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data)) // nolint: errcheck, gosec
+	return mac.Sum(nil)
+}
+
+func s3Error(resp *http.Response) error {
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024)) // nolint: errcheck
+	return fmt.Errorf("blob store responded with status %v: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,123 @@
+package channels
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+
+	log "github.com/golang/glog"
+	"github.com/joincivil/civil-api-server/pkg/images"
+	"github.com/vincent-petithory/dataurl"
+)
+
+const (
+	// AvatarVariantFull is the avatar as uploaded, 336x336
+	AvatarVariantFull = "full"
+	// AvatarVariantTiny100 is the avatar scaled down to 100x100
+	AvatarVariantTiny100 = "tiny100"
+	// AvatarVariantTiny72 is the avatar scaled down to 72x72
+	AvatarVariantTiny72 = "tiny72"
+
+	avatarSize         = 336
+	avatarKeyPrefix    = "avatars/"
+	defaultMigrateSize = 100
+)
+
+// AvatarVariants are the sizes avatars are saved to blob storage as
+var AvatarVariants = []images.Variant{
+	{Name: AvatarVariantFull, MaxWidth: avatarSize, MaxHeight: avatarSize},
+	{Name: AvatarVariantTiny100, MaxWidth: 100, MaxHeight: 100},
+	{Name: AvatarVariantTiny72, MaxWidth: 72, MaxHeight: 72},
+}
+
+// AvatarURL returns the URL of a variant of the channel's avatar, or nil if the channel has no avatar.
+// Channels whose avatar hasn't been migrated to blob storage return their data url instead
+func (s *Service) AvatarURL(channel *Channel, variant string) *string {
+	if channel.Avatar != nil {
+		if stored := channel.Avatar.Variant(variant); stored != nil {
+			url := s.imagePipeline.URL(stored)
+			return &url
+		}
+		return nil
+	}
+
+	var dataURL string
+	switch variant {
+	case AvatarVariantFull:
+		dataURL = channel.AvatarDataURL
+	case AvatarVariantTiny100:
+		dataURL = channel.Tiny100AvatarDataURL
+	case AvatarVariantTiny72:
+		dataURL = channel.Tiny72AvatarDataURL
+	}
+	if dataURL == "" {
+		return nil
+	}
+	return &dataURL
+}
+
+// storeAvatar saves the variants of an avatar to blob storage. Keys are addressed by the hash of the
+// image data, so blobs may be shared between channels and are never deleted when an avatar is replaced
+func storeAvatar(imagePipeline *images.Pipeline, src image.Image, decodedDataURL *dataurl.DataURL) (*images.Image, error) {
+	format := images.FormatPNG
+	if decodedDataURL.Subtype != "png" {
+		format = images.FormatJPEG
+	}
+	hash := sha256.Sum256(decodedDataURL.Data)
+	return imagePipeline.StoreShared(avatarKeyPrefix+hex.EncodeToString(hash[:]), src, format, AvatarVariants)
+}
+
+// AvatarMigrator moves channel avatars saved as data urls in the database to blob storage
+type AvatarMigrator struct {
+	persister     Persister
+	imagePipeline *images.Pipeline
+}
+
+// NewAvatarMigrator builds a new AvatarMigrator
+func NewAvatarMigrator(persister Persister, imagePipeline *images.Pipeline) *AvatarMigrator {
+	return &AvatarMigrator{persister: persister, imagePipeline: imagePipeline}
+}
+
+// Migrate saves the avatar data url of every channel to blob storage and clears the data urls,
+// working through channels in batches of `batchSize`. Avatars that fail to migrate are logged and
+// left as data urls, so the migration can be run again. Returns the number of channels migrated and failed
+func (m *AvatarMigrator) Migrate(batchSize int) (int, int, error) {
+	if batchSize <= 0 {
+		batchSize = defaultMigrateSize
+	}
+
+	var migrated, failed int
+	var afterID string
+	for {
+		chs, err := m.persister.GetChannelsWithAvatarDataURL(afterID, batchSize)
+		if err != nil {
+			return migrated, failed, err
+		}
+		if len(chs) == 0 {
+			return migrated, failed, nil
+		}
+
+		for _, ch := range chs {
+			afterID = ch.ID
+			if err := m.migrateChannel(ch); err != nil {
+				log.Errorf("error migrating avatar of channel %v: %v", ch.ID, err)
+				failed++
+				continue
+			}
+			migrated++
+		}
+	}
+}
+
+func (m *AvatarMigrator) migrateChannel(ch *Channel) error {
+	src, decodedDataURL, err := getImageAndDecodedDataURLFromDataURL(ch.AvatarDataURL)
+	if err != nil {
+		return err
+	}
+	avatar, err := storeAvatar(m.imagePipeline, *src, decodedDataURL)
+	if err != nil {
+		return err
+	}
+	_, err = m.persister.MigrateAvatar(ch.ID, avatar)
+	return err
+}
@@ -27,4 +27,6 @@ var (
 	ErrorsInvalidInput = errors.New("invalid input")
 	// ErrorStripeIssue is returned when a stripe service returns an error
 	ErrorStripeIssue = errors.New("error with stripe request")
+	// ErrorCannotFollowChannelType is returned when a user tries to follow a channel that is not a newsroom or group
+	ErrorCannotFollowChannelType = errors.New("only newsroom and group channels can be followed")
 )
@@ -3,6 +3,7 @@ package channels
 import (
 	"time"
 
+	"github.com/joincivil/civil-api-server/pkg/images"
 	uuid "github.com/satori/go.uuid"
 )
 
@@ -92,10 +93,17 @@ type Channel struct {
 	StripeAccountID             string
 	EmailAddress                string
 	IsAwaitingEmailConfirmation bool
-	AvatarDataURL               string
-	Tiny100AvatarDataURL        string // avatar data url scaled down to width 100
-	Tiny72AvatarDataURL         string // avatar data url scaled down to width 72 height 72
+	Avatar                      *images.Image `gorm:"type:jsonb"` // avatar saved to blob storage, see AvatarVariants
+	AvatarDataURL               string        // Deprecated: avatars are saved to blob storage, kept until existing data urls are migrated
+	Tiny100AvatarDataURL        string        // Deprecated: avatar data url scaled down to width 100
+	Tiny72AvatarDataURL         string        // Deprecated: avatar data url scaled down to width 72 height 72
 	StripeCustomerID            string
+	SuspendedAt                 *time.Time // set when platform moderators suspend the channel from posting
+}
+
+// IsSuspended returns whether the channel has been suspended from posting
+func (c *Channel) IsSuspended() bool {
+	return c.SuspendedAt != nil
 }
 
 // BeforeCreate is a GORM hook that sets the ID before it its persisted
@@ -123,3 +131,25 @@ func (c *ChannelMember) BeforeCreate() (err error) {
 	c.ID = id.String()
 	return
 }
+
+// ChannelFollow records a User following a Channel
+type ChannelFollow struct {
+	ID        string `gorm:"type:uuid;primary_key"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time
+	ChannelID string `gorm:"type:uuid;not null;index:idx_chanfollow_channel_id;unique_index:idx_chanfollow_channel_user"`
+	UserID    string `gorm:"type:uuid;not null;index:idx_chanfollow_user_id;unique_index:idx_chanfollow_channel_user"`
+}
+
+// TableName returns the gorm table name for ChannelFollow
+func (ChannelFollow) TableName() string {
+	return "channel_follows"
+}
+
+// BeforeCreate is a GORM hook that sets the ID before it its persisted
+func (c *ChannelFollow) BeforeCreate() (err error) {
+	id := uuid.NewV4()
+	c.ID = id.String()
+	return
+}
@@ -1,5 +1,9 @@
 package channels
 
+import (
+	"github.com/joincivil/civil-api-server/pkg/images"
+)
+
 // Persister defines the methods needed to persister Channels
 type Persister interface {
 	CreateChannel(input CreateChannelInput) (*Channel, error)
@@ -17,9 +21,16 @@ type Persister interface {
 	SetEmailAddress(userID string, channelID string, emailAddress string) (*Channel, error)
 	SetIsAwaitingEmailConfirmation(channelID string, isAwaiting bool) (*Channel, error)
 	SetStripeAccountID(userID string, channelID string, stripeAccountID string) (*Channel, error)
-	SetAvatarDataURL(userID string, channelID string, avatarDataURL string) (*Channel, error)
-	SetTiny72AvatarDataURL(userID string, channelID string, tiny72AvatarDataURL string) error
+	SetAvatar(userID string, channelID string, avatar *images.Image) (*Channel, error)
+	MigrateAvatar(channelID string, avatar *images.Image) (*Channel, error)
+	GetChannelsWithAvatarDataURL(afterID string, limit int) ([]*Channel, error)
 	SetStripeCustomerID(channelID string, stripeCustomerID string) (*Channel, error)
 	ClearStripeCustomerID(userID string, channelID string) (*Channel, error)
+	SetSuspended(channelID string, suspended bool) (*Channel, error)
 	GetChannelAdminUserChannels(channelID string) ([]*Channel, error)
+	CreateChannelFollow(channelID string, userID string) (*ChannelFollow, error)
+	DeleteChannelFollow(channelID string, userID string) error
+	IsFollowingChannel(channelID string, userID string) (bool, error)
+	GetChannelFollowerCount(channelID string) (int, error)
+	GetFollowedChannelIDs(userID string) ([]string, error)
 }
@@ -2,9 +2,11 @@ package channels
 
 import (
 	"github.com/jinzhu/gorm"
+	"github.com/joincivil/civil-api-server/pkg/images"
 	"github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
 	"strings"
+	"time"
 )
 
 // DBPersister implements the Persister interface using GORM
@@ -342,12 +344,12 @@ func (p *DBPersister) SetEmailAddress(userID string, channelID string, emailAddr
 	return ch, nil
 }
 
-// SetAvatarDataURL updates the avatar data url for the channel
-func (p *DBPersister) SetAvatarDataURL(userID string, channelID string, avatarDataURL string) (*Channel, error) {
+// SetAvatar updates the avatar for the channel, clearing its deprecated avatar data urls
+func (p *DBPersister) SetAvatar(userID string, channelID string, avatar *images.Image) (*Channel, error) {
 	// get channel
 	ch, err := p.GetChannel(channelID)
 	if err != nil {
-		return nil, errors.Wrap(err, "error setting avatar data url, could not get channel")
+		return nil, errors.Wrap(err, "error setting avatar, could not get channel")
 	}
 
 	// make sure the user requesting is an admin
@@ -355,39 +357,56 @@ func (p *DBPersister) SetAvatarDataURL(userID string, channelID string, avatarDa
 	if err == ErrorUnauthorized {
 		return nil, ErrorUnauthorized
 	} else if err != nil {
-		return nil, errors.Wrap(err, "error setting avatar data url, not an admin")
+		return nil, errors.Wrap(err, "error setting avatar, not an admin")
 	}
 
-	err = p.db.Model(ch).Update(Channel{AvatarDataURL: avatarDataURL}).Error
+	return p.setAvatar(ch, avatar)
+}
+
+// MigrateAvatar updates the avatar for a channel whose avatar was migrated from a data url to blob storage
+// should only be used by the avatar migration
+func (p *DBPersister) MigrateAvatar(channelID string, avatar *images.Image) (*Channel, error) {
+	ch, err := p.GetChannel(channelID)
 	if err != nil {
-		return nil, errors.Wrap(err, "error setting email")
+		return nil, errors.Wrap(err, "error migrating avatar, could not get channel")
 	}
 
-	return ch, nil
+	return p.setAvatar(ch, avatar)
 }
 
-// SetTiny72AvatarDataURL updates the tiny72 avatar data url for the channel
-func (p *DBPersister) SetTiny72AvatarDataURL(userID string, channelID string, avatarDataURL string) error {
-	// get channel
-	ch, err := p.GetChannel(channelID)
+func (p *DBPersister) setAvatar(ch *Channel, avatar *images.Image) (*Channel, error) {
+	// a map is used so the data urls are cleared, gorm skips blank fields when updating with a struct
+	err := p.db.Model(ch).Updates(map[string]interface{}{
+		"avatar":                  avatar,
+		"avatar_data_url":         "",
+		"tiny100_avatar_data_url": "",
+		"tiny72_avatar_data_url":  "",
+	}).Error
 	if err != nil {
-		return errors.Wrap(err, "error setting avatar data url, could not get channel")
+		return nil, errors.Wrap(err, "error setting avatar")
 	}
 
-	// make sure the user requesting is an admin
-	err = p.requireAdmin(userID, channelID)
-	if err == ErrorUnauthorized {
-		return ErrorUnauthorized
-	} else if err != nil {
-		return errors.Wrap(err, "error setting avatar data url, not an admin")
-	}
+	ch.Avatar = avatar
+	ch.AvatarDataURL = ""
+	ch.Tiny100AvatarDataURL = ""
+	ch.Tiny72AvatarDataURL = ""
+	return ch, nil
+}
 
-	err = p.db.Model(ch).Update(Channel{Tiny72AvatarDataURL: avatarDataURL}).Error
+// GetChannelsWithAvatarDataURL retrieves channels, ordered by id, whose avatar is still saved as a data url
+// returning at most `limit` channels with an id greater than `afterID`
+func (p *DBPersister) GetChannelsWithAvatarDataURL(afterID string, limit int) ([]*Channel, error) {
+	var chs []*Channel
+	stmt := p.db.Where("avatar_data_url <> '' AND avatar IS NULL")
+	if afterID != "" {
+		stmt = stmt.Where("id > ?", afterID)
+	}
+	err := stmt.Order("id").Limit(limit).Find(&chs).Error
 	if err != nil {
-		return errors.Wrap(err, "error setting email")
+		return nil, errors.Wrap(err, "error getting channels with avatar data urls")
 	}
 
-	return nil
+	return chs, nil
 }
 
 // SetStripeCustomerID updates the stripe customer id for the channel
@@ -407,6 +426,26 @@ func (p *DBPersister) SetStripeCustomerID(channelID string, stripeCustomerID str
 	return ch, nil
 }
 
+// SetSuspended suspends the channel from posting, or lifts the suspension
+func (p *DBPersister) SetSuspended(channelID string, suspended bool) (*Channel, error) {
+	ch, err := p.GetChannel(channelID)
+	if err != nil {
+		return nil, errors.Wrap(err, "error setting channel suspension")
+	}
+
+	var suspendedAt *time.Time
+	if suspended {
+		now := time.Now()
+		suspendedAt = &now
+	}
+	err = p.db.Model(ch).Update("suspended_at", suspendedAt).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "error setting channel suspension")
+	}
+
+	return ch, nil
+}
+
 // ClearStripeCustomerID clears the stripe customer id for the channel
 func (p *DBPersister) ClearStripeCustomerID(userID string, channelID string) (*Channel, error) {
 	// get channel
@@ -503,3 +542,61 @@ func (p *DBPersister) requireAdmin(userID string, channelID string) error {
 
 	return nil
 }
+
+// CreateChannelFollow records the user as a follower of the channel, returning the existing follow if there is one
+func (p *DBPersister) CreateChannelFollow(channelID string, userID string) (*ChannelFollow, error) {
+	follow := &ChannelFollow{}
+	err := p.db.Where(&ChannelFollow{ChannelID: channelID, UserID: userID}).
+		Attrs(ChannelFollow{ChannelID: channelID, UserID: userID}).
+		FirstOrCreate(follow).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating channel follow")
+	}
+
+	return follow, nil
+}
+
+// DeleteChannelFollow removes the user as a follower of the channel
+// this uses the `Unscoped` Delete function to remove entry from DB, rather than just
+// setting deleted_at
+func (p *DBPersister) DeleteChannelFollow(channelID string, userID string) error {
+	err := p.db.Unscoped().Where(&ChannelFollow{ChannelID: channelID, UserID: userID}).Delete(&ChannelFollow{}).Error
+	if err != nil {
+		return errors.Wrap(err, "error deleting channel follow")
+	}
+
+	return nil
+}
+
+// IsFollowingChannel returns whether the user follows the channel
+func (p *DBPersister) IsFollowingChannel(channelID string, userID string) (bool, error) {
+	var count int
+	err := p.db.Model(&ChannelFollow{}).Where(&ChannelFollow{ChannelID: channelID, UserID: userID}).Count(&count).Error
+	if err != nil {
+		return false, errors.Wrap(err, "error checking channel follow")
+	}
+
+	return count > 0, nil
+}
+
+// GetChannelFollowerCount returns the number of users following the channel
+func (p *DBPersister) GetChannelFollowerCount(channelID string) (int, error) {
+	var count int
+	err := p.db.Model(&ChannelFollow{}).Where(&ChannelFollow{ChannelID: channelID}).Count(&count).Error
+	if err != nil {
+		return 0, errors.Wrap(err, "error counting channel followers")
+	}
+
+	return count, nil
+}
+
+// GetFollowedChannelIDs returns the IDs of the channels the user follows
+func (p *DBPersister) GetFollowedChannelIDs(userID string) ([]string, error) {
+	var channelIDs []string
+	err := p.db.Model(&ChannelFollow{}).Where(&ChannelFollow{UserID: userID}).Pluck("channel_id", &channelIDs).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting followed channels")
+	}
+
+	return channelIDs, nil
+}
@@ -2,10 +2,12 @@ package channels_test
 
 import (
 	"github.com/joincivil/civil-api-server/pkg/channels"
+	"github.com/joincivil/civil-api-server/pkg/images"
 	"github.com/joincivil/civil-api-server/pkg/testruntime"
 	"github.com/joincivil/civil-api-server/pkg/testutils"
 	"github.com/joincivil/civil-api-server/pkg/utils"
 	"github.com/joincivil/go-common/pkg/email"
+	"strings"
 	"testing"
 )
 
@@ -32,7 +34,7 @@ func TestNewsroomHandle(t *testing.T) {
 
 	sendGridKey := getSendGridKeyFromEnvVar()
 	emailer := email.NewEmailerWithSandbox(sendGridKey, useSandbox)
-	svc := channels.NewService(persister, MockGetNewsroomHelper{}, MockStripeConnector{}, generator, emailer, testSignupLoginProtoHost)
+	svc := channels.NewService(persister, MockGetNewsroomHelper{}, MockStripeConnector{}, generator, emailer, testSignupLoginProtoHost, images.NewPipeline(nil))
 
 	channel, err := svc.CreateUserChannel(user3ID)
 	if err != nil {
@@ -88,7 +90,7 @@ func TestGetChannelEmptyID(t *testing.T) {
 
 	sendGridKey := getSendGridKeyFromEnvVar()
 	emailer := email.NewEmailerWithSandbox(sendGridKey, useSandbox)
-	svc := channels.NewService(persister, MockGetNewsroomHelper{}, MockStripeConnector{}, generator, emailer, testSignupLoginProtoHost)
+	svc := channels.NewService(persister, MockGetNewsroomHelper{}, MockStripeConnector{}, generator, emailer, testSignupLoginProtoHost, images.NewPipeline(nil))
 
 	channel, err := svc.CreateUserChannel(user4ID)
 	if err != nil {
@@ -120,7 +122,7 @@ func TestSetIsAwaitingEmailConfirmation(t *testing.T) {
 
 	sendGridKey := getSendGridKeyFromEnvVar()
 	emailer := email.NewEmailerWithSandbox(sendGridKey, useSandbox)
-	svc := channels.NewService(persister, MockGetNewsroomHelper{}, MockStripeConnector{}, generator, emailer, testSignupLoginProtoHost)
+	svc := channels.NewService(persister, MockGetNewsroomHelper{}, MockStripeConnector{}, generator, emailer, testSignupLoginProtoHost, images.NewPipeline(nil))
 
 	channel, err := svc.CreateUserChannel(user5ID)
 	if err != nil {
@@ -151,3 +153,156 @@ func TestSetIsAwaitingEmailConfirmation(t *testing.T) {
 		t.Fatalf("IsAwaitingEmailConfirmation should not be true here")
 	}
 }
+
+func TestChannelFollows(t *testing.T) {
+	db, err := testutils.GetTestDBConnection()
+	if err != nil {
+		t.Fatalf("error getting DB: %v", err)
+	}
+	err = testruntime.RunMigrations(db)
+	if err != nil {
+		t.Fatalf("error cleaning DB: %v", err)
+	}
+
+	persister := channels.NewDBPersister(db)
+	generator := utils.NewJwtTokenGenerator([]byte("secret"))
+
+	sendGridKey := getSendGridKeyFromEnvVar()
+	emailer := email.NewEmailerWithSandbox(sendGridKey, useSandbox)
+	svc := channels.NewService(persister, MockGetNewsroomHelper{}, MockStripeConnector{}, generator, emailer, testSignupLoginProtoHost, images.NewPipeline(nil))
+
+	group, err := svc.CreateGroupChannel(randomUUID(), "follow"+randomUUID()[:8])
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+	userChannel, err := svc.CreateUserChannel(randomUUID())
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+
+	followerID := randomUUID()
+	_, err = svc.FollowChannel(followerID, userChannel.ID)
+	if err != channels.ErrorCannotFollowChannelType {
+		t.Fatalf("expected ErrorCannotFollowChannelType: %v", err)
+	}
+
+	_, err = svc.FollowChannel(followerID, group.ID)
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+	// following twice should not create a second follow
+	_, err = svc.FollowChannel(followerID, group.ID)
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+
+	count, err := svc.GetChannelFollowerCount(group.ID)
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 follower, got %v", count)
+	}
+	following, err := svc.IsFollowingChannel(followerID, group.ID)
+	if err != nil || !following {
+		t.Fatalf("expected user to be following channel: %v", err)
+	}
+	followed, err := svc.GetFollowedChannelIDs(followerID)
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+	if len(followed) != 1 || followed[0] != group.ID {
+		t.Fatalf("expected followed channels to be the group channel")
+	}
+
+	err = svc.UnfollowChannel(followerID, group.ID)
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+	following, err = svc.IsFollowingChannel(followerID, group.ID)
+	if err != nil || following {
+		t.Fatalf("expected user to no longer follow channel: %v", err)
+	}
+}
+
+func TestMigrateAvatars(t *testing.T) {
+	db, err := testutils.GetTestDBConnection()
+	if err != nil {
+		t.Fatalf("error getting DB: %v", err)
+	}
+	err = testruntime.RunMigrations(db)
+	if err != nil {
+		t.Fatalf("error cleaning DB: %v", err)
+	}
+
+	persister := channels.NewDBPersister(db)
+	generator := utils.NewJwtTokenGenerator([]byte("secret"))
+
+	sendGridKey := getSendGridKeyFromEnvVar()
+	emailer := email.NewEmailerWithSandbox(sendGridKey, useSandbox)
+	imagePipeline, cleanup := newTestImagePipeline(t)
+	defer cleanup()
+	svc := channels.NewService(persister, MockGetNewsroomHelper{}, MockStripeConnector{}, generator, emailer, testSignupLoginProtoHost, imagePipeline)
+
+	channel, err := svc.CreateUserChannel(randomUUID())
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+	badChannel, err := svc.CreateUserChannel(randomUUID())
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+
+	// avatars set before they were saved to blob storage
+	err = db.Model(channel).Update(channels.Channel{AvatarDataURL: validAvatarImageDataURL}).Error
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+	err = db.Model(badChannel).Update(channels.Channel{AvatarDataURL: invalidTypeAvatarImageDataURL}).Error
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+
+	url := svc.AvatarURL(channel, channels.AvatarVariantFull)
+	if url == nil || *url != validAvatarImageDataURL {
+		t.Fatalf("expected the data url to be used until the avatar is migrated")
+	}
+
+	migrator := channels.NewAvatarMigrator(persister, imagePipeline)
+	migrated, failed, err := migrator.Migrate(1)
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+	if migrated != 1 || failed != 1 {
+		t.Fatalf("expected 1 migrated and 1 failed, got %v and %v", migrated, failed)
+	}
+
+	retrieved, err := svc.GetChannel(channel.ID)
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+	if retrieved.Avatar == nil || retrieved.AvatarDataURL != "" {
+		t.Fatalf("expected the avatar to be moved to blob storage")
+	}
+	url = svc.AvatarURL(retrieved, channels.AvatarVariantFull)
+	if url == nil || !strings.HasPrefix(*url, testBlobBaseURL+"/avatars/") {
+		t.Fatalf("unexpected avatar url after migrating: %v", url)
+	}
+
+	retrieved, err = svc.GetChannel(badChannel.ID)
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+	if retrieved.Avatar != nil || retrieved.AvatarDataURL != invalidTypeAvatarImageDataURL {
+		t.Fatalf("expected the avatar that failed to migrate to be left as a data url")
+	}
+
+	// running again only retries the failures
+	migrated, failed, err = migrator.Migrate(10)
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+	if migrated != 0 || failed != 1 {
+		t.Fatalf("expected 0 migrated and 1 failed, got %v and %v", migrated, failed)
+	}
+}
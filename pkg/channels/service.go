@@ -1,24 +1,19 @@
 package channels
 
 import (
-	"bytes"
 	"encoding/base64"
 	"fmt"
-	"github.com/Jeffail/tunny"
 	"github.com/ethereum/go-ethereum/common"
 	log "github.com/golang/glog"
 	"github.com/gosimple/slug"
+	"github.com/joincivil/civil-api-server/pkg/images"
 	"github.com/joincivil/civil-api-server/pkg/utils"
 	"github.com/joincivil/go-common/pkg/email"
-	"github.com/nfnt/resize"
 	"github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
 	"github.com/vincent-petithory/dataurl"
 	"image"
-	"image/jpeg"
-	"image/png"
 	"regexp"
-	"runtime"
 	"strings"
 )
 
@@ -46,7 +41,7 @@ type Service struct {
 	tokenGenerator       *utils.JwtTokenGenerator
 	emailer              *email.Emailer
 	signupLoginProtoHost string
-	imageProcessingPool  *tunny.Pool
+	imagePipeline        *images.Pipeline
 }
 
 // NewsroomHelper describes methods needed to get the members of a newsroom multisig
@@ -65,28 +60,24 @@ type StripeConnector interface {
 
 // NewServiceFromConfig creates a new channels.Service using the main graphql config
 func NewServiceFromConfig(persister Persister, newsroomHelper NewsroomHelper, stripeConnector StripeConnector, tokenGenerator *utils.JwtTokenGenerator,
-	emailer *email.Emailer, config *utils.GraphQLConfig) *Service {
+	emailer *email.Emailer, imagePipeline *images.Pipeline, config *utils.GraphQLConfig) *Service {
 	signupLoginProtoHost := config.SignupLoginProtoHost
-	return NewService(persister, newsroomHelper, stripeConnector, tokenGenerator, emailer, signupLoginProtoHost)
+	return NewService(persister, newsroomHelper, stripeConnector, tokenGenerator, emailer, signupLoginProtoHost, imagePipeline)
 }
 
 // NewService builds a new Service instance
 func NewService(persister Persister, newsroomHelper NewsroomHelper, stripeConnector StripeConnector, tokenGenerator *utils.JwtTokenGenerator,
-	emailer *email.Emailer, signupLoginProtoHost string) *Service {
+	emailer *email.Emailer, signupLoginProtoHost string, imagePipeline *images.Pipeline) *Service {
 
-	s := &Service{
+	return &Service{
 		persister,
 		newsroomHelper,
 		stripeConnector,
 		tokenGenerator,
 		emailer,
 		signupLoginProtoHost,
-		nil,
+		imagePipeline,
 	}
-	multiplier := 1
-	numCPUs := runtime.NumCPU() * multiplier
-	s.imageProcessingPool = tunny.NewFunc(numCPUs, s.processAvatar)
-	return s
 }
 
 // GetUserChannels retrieves the Channels a user is a member of
@@ -222,57 +213,31 @@ func getImageAndDecodedDataURLFromDataURL(dataURL string) (*image.Image, *dataur
 	return &m, decodedDataURL, nil
 }
 
-// SetAvatarDataURL sets the avatar data url on a channel of any type
+// SetAvatarDataURL sets the avatar of a channel of any type from a data url, saving it to blob storage
 func (s *Service) SetAvatarDataURL(userID string, channelID string, avatarDataURL string) (*Channel, error) {
 	image, decodedDataURL, err := getImageAndDecodedDataURLFromDataURL(avatarDataURL)
 	if err != nil {
 		return nil, err
 	}
-	if (*image).Bounds().Size().X != 336 || (*image).Bounds().Size().Y != 336 {
+	if (*image).Bounds().Size().X != avatarSize || (*image).Bounds().Size().Y != avatarSize {
 		return nil, ErrorBadAvatarSize
 	}
 
-	channel, err := s.persister.SetAvatarDataURL(userID, channelID, avatarDataURL)
+	// check before saving anything to blob storage
+	isAdmin, err := s.persister.IsChannelAdmin(userID, channelID)
 	if err != nil {
 		return nil, err
 	}
-	go func(p *tunny.Pool) {
-		p.Process(processAvatarInputs{userID, channelID, image, decodedDataURL})
-	}(s.imageProcessingPool)
-
-	return channel, nil
-}
-
-type processAvatarInputs struct {
-	userID         string
-	channelID      string
-	image          *image.Image
-	decodedDataURL *dataurl.DataURL
-}
-
-func (s *Service) processAvatar(payload interface{}) interface{} {
-	inputs := payload.(processAvatarInputs)
-
-	mTiny := resize.Resize(72, 72, *(inputs.image), resize.Lanczos3)
+	if !isAdmin {
+		return nil, ErrorUnauthorized
+	}
 
-	var buff bytes.Buffer
-	if inputs.decodedDataURL.Subtype == "jpeg" {
-		err := jpeg.Encode(&buff, mTiny, nil)
-		if err != nil {
-			return err
-		}
-	} else if inputs.decodedDataURL.Subtype == "png" {
-		err := png.Encode(&buff, mTiny)
-		if err != nil {
-			return err
-		}
-	} else {
-		return ErrorBadAvatarDataURLSubType
+	avatar, err := storeAvatar(s.imagePipeline, *image, decodedDataURL)
+	if err != nil {
+		return nil, err
 	}
 
-	mTinyBase64Str := base64.StdEncoding.EncodeToString(buff.Bytes())
-	mTinyDataURL := "data:" + inputs.decodedDataURL.ContentType() + ";base64," + mTinyBase64Str
-	return s.persister.SetTiny72AvatarDataURL(inputs.userID, inputs.channelID, mTinyDataURL)
+	return s.persister.SetAvatar(userID, channelID, avatar)
 }
 
 // SetStripeCustomerID sets the stripe customer id on a channel of any type
@@ -569,6 +534,12 @@ func (s *Service) GetChannel(id string) (*Channel, error) {
 	return s.persister.GetChannel(id)
 }
 
+// SetChannelSuspended suspends a channel from posting, or lifts the suspension. Callers are responsible
+// for checking the requestor is a platform moderator
+func (s *Service) SetChannelSuspended(channelID string, suspended bool) (*Channel, error) {
+	return s.persister.SetSuspended(channelID, suspended)
+}
+
 // GetChannelMembers returns a list of channel members given a channel id
 func (s *Service) GetChannelMembers(channelID string) ([]*ChannelMember, error) {
 	return s.persister.GetChannelMembers(channelID)
@@ -589,6 +560,41 @@ func (s *Service) IsChannelAdmin(userID string, channelID string) (bool, error)
 	return s.persister.IsChannelAdmin(userID, channelID)
 }
 
+// FollowChannel makes the user a follower of a newsroom or group channel
+func (s *Service) FollowChannel(userID string, channelID string) (*ChannelFollow, error) {
+	channel, err := s.GetChannel(channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel == nil {
+		return nil, ErrorNotFound
+	}
+	if channel.ChannelType != TypeNewsroom && channel.ChannelType != TypeGroup {
+		return nil, ErrorCannotFollowChannelType
+	}
+	return s.persister.CreateChannelFollow(channelID, userID)
+}
+
+// UnfollowChannel removes the user as a follower of the channel
+func (s *Service) UnfollowChannel(userID string, channelID string) error {
+	return s.persister.DeleteChannelFollow(channelID, userID)
+}
+
+// IsFollowingChannel returns whether the user follows the channel
+func (s *Service) IsFollowingChannel(userID string, channelID string) (bool, error) {
+	return s.persister.IsFollowingChannel(channelID, userID)
+}
+
+// GetChannelFollowerCount returns the number of users following the channel
+func (s *Service) GetChannelFollowerCount(channelID string) (int, error) {
+	return s.persister.GetChannelFollowerCount(channelID)
+}
+
+// GetFollowedChannelIDs returns the IDs of the channels the user follows
+func (s *Service) GetFollowedChannelIDs(userID string) ([]string, error) {
+	return s.persister.GetFollowedChannelIDs(userID)
+}
+
 // ChannelEmailAddress returns the email address of the channel
 func (s *Service) ChannelEmailAddress(channelID string) (string, error) {
 	channel, err := s.persister.GetChannel(channelID)
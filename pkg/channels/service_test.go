@@ -4,12 +4,15 @@ import (
 	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/joincivil/civil-api-server/pkg/blobstore"
 	"github.com/joincivil/civil-api-server/pkg/channels"
+	"github.com/joincivil/civil-api-server/pkg/images"
 	"github.com/joincivil/civil-api-server/pkg/testruntime"
 	"github.com/joincivil/civil-api-server/pkg/testutils"
 	"github.com/joincivil/civil-api-server/pkg/utils"
 	"github.com/joincivil/go-common/pkg/email"
 	uuid "github.com/satori/go.uuid"
+	"io/ioutil"
 	"math/rand"
 	"os"
 	"strconv"
@@ -22,6 +25,7 @@ const (
 	testSignupLoginProtoHost = "http://localhost:8080"
 	sendGridKeyEnvVar        = "SENDGRID_TEST_KEY"
 	useSandbox               = true
+	testBlobBaseURL          = "http://localhost:8080/v1/blobs"
 )
 
 var r = rand.New(rand.NewSource(time.Now().UnixNano()))
@@ -104,6 +108,16 @@ func (s MockStripeConnector) IsApplePayEnabled(stripeAccountID string) (bool, er
 	return false, nil
 }
 
+func newTestImagePipeline(t *testing.T) (*images.Pipeline, func()) {
+	dir, err := ioutil.TempDir("", "channels")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	return images.NewPipeline(blobstore.NewLocalStore(dir, testBlobBaseURL)), func() {
+		os.RemoveAll(dir) // nolint: errcheck
+	}
+}
+
 func getSendGridKeyFromEnvVar() string {
 	return os.Getenv(sendGridKeyEnvVar)
 }
@@ -123,7 +137,9 @@ func TestCreateChannel(t *testing.T) {
 
 	sendGridKey := getSendGridKeyFromEnvVar()
 	emailer := email.NewEmailerWithSandbox(sendGridKey, useSandbox)
-	svc := channels.NewService(persister, MockGetNewsroomHelper{}, MockStripeConnector{}, generator, emailer, testSignupLoginProtoHost)
+	imagePipeline, cleanup := newTestImagePipeline(t)
+	defer cleanup()
+	svc := channels.NewService(persister, MockGetNewsroomHelper{}, MockStripeConnector{}, generator, emailer, testSignupLoginProtoHost, imagePipeline)
 
 	channel, err := svc.CreateUserChannel(user1ID)
 	if err != nil {
@@ -345,10 +361,36 @@ func TestCreateChannel(t *testing.T) {
 		if err != channels.ErrorBadAvatarSize {
 			t.Fatalf("was expecting ErrorBadAvatarSize: %v", err)
 		}
-		_, err = svc.SetAvatarDataURL(u1, channel.ID, validAvatarImageDataURL)
+		_, err = svc.SetAvatarDataURL(randomUUID(), channel.ID, validAvatarImageDataURL)
+		if err != channels.ErrorUnauthorized {
+			t.Fatalf("was expecting ErrorUnauthorized: %v", err)
+		}
+		channel, err = svc.SetAvatarDataURL(u1, channel.ID, validAvatarImageDataURL)
 		if err != nil {
 			t.Fatalf("not expecting error: %v", err)
 		}
+		if channel.Avatar == nil || len(channel.Avatar.Variants) != len(channels.AvatarVariants) {
+			t.Fatalf("expected the avatar to be saved to blob storage")
+		}
+		tiny := channel.Avatar.Variant(channels.AvatarVariantTiny72)
+		if tiny.Width != 72 || tiny.Height != 72 {
+			t.Fatalf("expected the tiny avatar to be 72x72 but is %vx%v", tiny.Width, tiny.Height)
+		}
+		if channel.AvatarDataURL != "" {
+			t.Fatalf("was not expecting the avatar data url to be saved")
+		}
+
+		retrieved, err := svc.GetChannel(channel.ID)
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		if retrieved.Avatar == nil || retrieved.Avatar.Variant(channels.AvatarVariantFull).Key != channel.Avatar.Variant(channels.AvatarVariantFull).Key {
+			t.Fatalf("expected the avatar to be persisted")
+		}
+		url := svc.AvatarURL(retrieved, channels.AvatarVariantTiny72)
+		if url == nil || *url != testBlobBaseURL+"/"+tiny.Key {
+			t.Fatalf("unexpected tiny avatar url: %v", url)
+		}
 	})
 
 	t.Run("SendEmailConfirmation", func(t *testing.T) {
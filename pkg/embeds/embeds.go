@@ -0,0 +1,221 @@
+package embeds
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/joincivil/civil-api-server/pkg/payments"
+	"github.com/joincivil/civil-api-server/pkg/posts"
+	"github.com/joincivil/civil-api-server/pkg/utils"
+)
+
+const (
+	// ThemeLight is the default widget theme, dark text on a light background
+	ThemeLight = "light"
+	// ThemeDark is light text on a dark background
+	ThemeDark = "dark"
+
+	defaultAccent   = "2b56ff"
+	defaultMaxAge   = 60
+	defaultProvider = "Civil"
+)
+
+var accentRegexp = regexp.MustCompile(`^[0-9a-fA-F]{6}$`)
+
+// PostSource defines the post functions needed to embed boosts
+type PostSource interface {
+	GetPost(id string) (posts.Post, error)
+}
+
+// PaymentSource defines the payment functions needed to show the progress of a boost
+type PaymentSource interface {
+	TotalPayments(postID string, currencyCode string) (float64, error)
+	GetGroupedSanitizedPayments(postID string) ([]*payments.SanitizedPayment, error)
+}
+
+// EmbedConfig configures the embeddable widget endpoints
+type EmbedConfig struct {
+	// SiteURL is the base URL of the Civil site, the widget's support button links to the boost there
+	SiteURL string
+	// APIURL is the base URL the embed endpoints are served from, used to link the widget and
+	// oEmbed responses to each other. It is configured rather than taken from the request, since responses are cached
+	APIURL string
+	// CacheMaxAge is the number of seconds widgets may be cached for
+	CacheMaxAge  int
+	ProviderName string
+}
+
+// Embedder renders boosts as widgets newsrooms can embed on their own sites
+type Embedder struct {
+	postSource    PostSource
+	paymentSource PaymentSource
+	siteURL       string
+	apiURL        string
+	cacheMaxAge   int
+	providerName  string
+}
+
+// NewEmbedder builds an instance of embeds.Embedder
+func NewEmbedder(postSource PostSource, paymentSource PaymentSource, config EmbedConfig) *Embedder {
+	cacheMaxAge := config.CacheMaxAge
+	if cacheMaxAge <= 0 {
+		cacheMaxAge = defaultMaxAge
+	}
+	providerName := config.ProviderName
+	if providerName == "" {
+		providerName = defaultProvider
+	}
+
+	return &Embedder{
+		postSource:    postSource,
+		paymentSource: paymentSource,
+		siteURL:       strings.TrimRight(config.SiteURL, "/"),
+		apiURL:        strings.TrimRight(config.APIURL, "/"),
+		cacheMaxAge:   cacheMaxAge,
+		providerName:  providerName,
+	}
+}
+
+// NewEmbedderFromConfig builds an instance of embeds.Embedder using the graphql config
+func NewEmbedderFromConfig(postSource PostSource, paymentSource PaymentSource, config *utils.GraphQLConfig) *Embedder {
+	return NewEmbedder(postSource, paymentSource, EmbedConfig{
+		SiteURL:      config.EmbedSiteURL,
+		APIURL:       config.EmbedAPIURL,
+		CacheMaxAge:  config.EmbedCacheMaxAge,
+		ProviderName: config.EmbedProviderName,
+	})
+}
+
+// BoostEmbed is the progress of a boost shown by the embeddable widget
+type BoostEmbed struct {
+	ID            string    `json:"id"`
+	Title         string    `json:"title"`
+	URL           string    `json:"url"`
+	CurrencyCode  string    `json:"currencyCode"`
+	GoalAmount    float64   `json:"goalAmount"`
+	PaymentsTotal float64   `json:"paymentsTotal"`
+	Supporters    int       `json:"supporters"`
+	DateEnd       time.Time `json:"dateEnd"`
+	Ended         bool      `json:"ended"`
+}
+
+// PercentRaised returns the percentage of the goal raised, capped at 100
+func (b *BoostEmbed) PercentRaised() int {
+	if b.GoalAmount <= 0 {
+		return 0
+	}
+	return int(math.Min(100, math.Floor(b.PaymentsTotal/b.GoalAmount*100)))
+}
+
+// Boost returns the progress of a published boost. Drafts, hidden posts and posts that
+// aren't boosts return posts.ErrorNotFound
+func (e *Embedder) Boost(postID string) (*BoostEmbed, error) {
+	post, err := e.postSource.GetPost(postID)
+	if err != nil {
+		return nil, err
+	}
+	boost, ok := post.(*posts.Boost)
+	if !ok || boost.Draft || boost.Hidden {
+		return nil, posts.ErrorNotFound
+	}
+
+	total, err := e.paymentSource.TotalPayments(boost.ID, boost.CurrencyCode)
+	if err != nil {
+		return nil, err
+	}
+	supporters, err := e.paymentSource.GetGroupedSanitizedPayments(boost.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BoostEmbed{
+		ID:            boost.ID,
+		Title:         boost.Title,
+		URL:           fmt.Sprintf("%v/boosts/%v", e.siteURL, boost.ID),
+		CurrencyCode:  boost.CurrencyCode,
+		GoalAmount:    boost.GoalAmount,
+		PaymentsTotal: total,
+		Supporters:    len(supporters),
+		DateEnd:       boost.DateEnd,
+		Ended:         !boost.DateEnd.IsZero() && time.Now().After(boost.DateEnd),
+	}, nil
+}
+
+// Theme is the look of a widget, set with the `theme` and `accent` query params
+type Theme struct {
+	Name string
+	// Accent is the hex color, without the #, of the progress bar and support button
+	Accent string
+}
+
+// ThemeFromQuery reads the theme from query params, falling back to the defaults for missing or invalid values
+func ThemeFromQuery(query url.Values) *Theme {
+	theme := &Theme{Name: ThemeLight, Accent: defaultAccent}
+	if query.Get("theme") == ThemeDark {
+		theme.Name = ThemeDark
+	}
+	if accent := query.Get("accent"); accentRegexp.MatchString(accent) {
+		theme.Accent = strings.ToLower(accent)
+	}
+	return theme
+}
+
+// Query returns the query params that select the theme, empty for the default theme
+func (t *Theme) Query() string {
+	query := url.Values{}
+	if t.Name != ThemeLight {
+		query.Set("theme", t.Name)
+	}
+	if t.Accent != defaultAccent {
+		query.Set("accent", t.Accent)
+	}
+	return query.Encode()
+}
+
+// the colors are validated or constants, so they are safe to use in the widget's stylesheet
+func (t *Theme) background() template.CSS {
+	if t.Name == ThemeDark {
+		return template.CSS("#1c1c1e")
+	}
+	return template.CSS("#ffffff")
+}
+
+func (t *Theme) text() template.CSS {
+	if t.Name == ThemeDark {
+		return template.CSS("#f2f2f2")
+	}
+	return template.CSS("#1c1c1e")
+}
+
+func (t *Theme) muted() template.CSS {
+	if t.Name == ThemeDark {
+		return template.CSS("#a1a1a6")
+	}
+	return template.CSS("#6e6e73")
+}
+
+func (t *Theme) accent() template.CSS {
+	return template.CSS("#" + t.Accent) // nolint: gosec
+}
+
+// formatAmount formats an amount without cents and with thousands separators, such as $1,250
+func formatAmount(amount float64, currencyCode string) string {
+	digits := fmt.Sprintf("%.0f", math.Floor(amount))
+	var grouped strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteRune(',')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	if currencyCode == "USD" || currencyCode == "" {
+		return "$" + grouped.String()
+	}
+	return grouped.String() + " " + currencyCode
+}
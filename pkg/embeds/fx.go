@@ -0,0 +1,10 @@
+package embeds
+
+import "go.uber.org/fx"
+
+// EmbedModule builds the embeddable widget services
+var EmbedModule = fx.Options(
+	fx.Provide(
+		NewEmbedderFromConfig,
+	),
+)
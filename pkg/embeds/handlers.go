@@ -0,0 +1,269 @@
+package embeds
+
+import (
+	"bytes"
+	"crypto/sha1" // nolint: gosec
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	log "github.com/golang/glog"
+
+	"github.com/joincivil/civil-api-server/pkg/posts"
+	"github.com/joincivil/civil-api-server/pkg/utils"
+)
+
+const (
+	// PostIDURLParam is the name of the URL param holding the boost's post ID
+	PostIDURLParam = "postID"
+
+	defaultWidgetWidth  = 400
+	defaultWidgetHeight = 200
+	minWidgetWidth      = 200
+	minWidgetHeight     = 120
+)
+
+// matches the path of a boost on the Civil site, /boosts/{id}, or of its widget, /embed/boost/{id}
+var boostPathRegexp = regexp.MustCompile(`/boosts?/([0-9a-fA-F-]{36})/?$`)
+
+var widgetTemplate = template.Must(template.New("boost").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Boost.Title}}</title>
+<link rel="alternate" type="application/json+oembed" href="{{.OEmbedURL}}" title="{{.Boost.Title}}">
+<style>
+body { margin: 0; padding: 16px; font-family: -apple-system, BlinkMacSystemFont, "Helvetica Neue", Arial, sans-serif; background: {{.Background}}; color: {{.Text}}; }
+h1 { margin: 0 0 12px; font-size: 18px; line-height: 1.3; }
+h1 a { color: inherit; text-decoration: none; }
+.progress { height: 8px; border-radius: 4px; background: {{.Muted}}; overflow: hidden; }
+.progress div { height: 100%; background: {{.Accent}}; }
+.stats { margin: 8px 0 16px; font-size: 14px; color: {{.Muted}}; }
+.stats strong { color: {{.Text}}; }
+.support { display: inline-block; padding: 8px 20px; border-radius: 4px; background: {{.Accent}}; color: #ffffff; font-size: 14px; font-weight: bold; text-decoration: none; }
+</style>
+</head>
+<body>
+<h1><a href="{{.Boost.URL}}" target="_blank" rel="noopener">{{.Boost.Title}}</a></h1>
+<div class="progress"><div style="width: {{.Percent}}%"></div></div>
+<p class="stats"><strong>{{.Raised}}</strong> raised of {{.Goal}} goal &middot; {{.Boost.Supporters}} {{if eq .Boost.Supporters 1}}supporter{{else}}supporters{{end}} &middot; {{.EndLabel}}</p>
+{{if not .Boost.Ended}}<a class="support" href="{{.Boost.URL}}" target="_blank" rel="noopener">Support</a>{{end}}
+</body>
+</html>
+`))
+
+type widgetView struct {
+	Boost      *BoostEmbed
+	Raised     string
+	Goal       string
+	Percent    int
+	EndLabel   string
+	OEmbedURL  string
+	Background template.CSS
+	Text       template.CSS
+	Muted      template.CSS
+	Accent     template.CSS
+}
+
+type oEmbedResponse struct {
+	Version      string `json:"version"`
+	Type         string `json:"type"`
+	ProviderName string `json:"provider_name"`
+	ProviderURL  string `json:"provider_url"`
+	Title        string `json:"title"`
+	HTML         string `json:"html"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	CacheAge     int    `json:"cache_age"`
+}
+
+// BoostHandler is a REST endpoint handler that renders a boost as an HTML widget, for use in an iframe
+func (e *Embedder) BoostHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		boost, ok := e.getBoost(w, r, chi.URLParam(r, PostIDURLParam))
+		if !ok {
+			return
+		}
+		theme := ThemeFromQuery(r.URL.Query())
+
+		oEmbedURL := fmt.Sprintf("%v/oembed?url=%v", e.apiURL, url.QueryEscape(e.widgetURL(boost.ID)))
+		if query := theme.Query(); query != "" {
+			oEmbedURL += "&" + query
+		}
+
+		endLabel := "Ends " + boost.DateEnd.Format("January 2, 2006")
+		if boost.Ended {
+			endLabel = "Ended " + boost.DateEnd.Format("January 2, 2006")
+		}
+
+		var body bytes.Buffer
+		err := widgetTemplate.Execute(&body, &widgetView{
+			Boost:      boost,
+			Raised:     formatAmount(boost.PaymentsTotal, boost.CurrencyCode),
+			Goal:       formatAmount(boost.GoalAmount, boost.CurrencyCode),
+			Percent:    boost.PercentRaised(),
+			EndLabel:   endLabel,
+			OEmbedURL:  oEmbedURL,
+			Background: theme.background(),
+			Text:       theme.text(),
+			Muted:      theme.muted(),
+			Accent:     theme.accent(),
+		})
+		if err != nil {
+			log.Errorf("error rendering boost widget: %v", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		e.write(w, r, "text/html; charset=utf-8", body.Bytes())
+	}
+}
+
+// BoostJSONHandler is a REST endpoint handler that returns the progress of a boost as JSON,
+// for newsrooms that render their own widget
+func (e *Embedder) BoostJSONHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		boost, ok := e.getBoost(w, r, chi.URLParam(r, PostIDURLParam))
+		if !ok {
+			return
+		}
+		body, err := json.Marshal(boost)
+		if err != nil {
+			log.Errorf("error marshalling boost embed: %v", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		// requested by scripts on newsroom sites
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		e.write(w, r, "application/json", body)
+	}
+}
+
+// OEmbedHandler is a REST endpoint handler implementing oEmbed for boosts, `url` may be the
+// boost's page on the Civil site or its widget
+func (e *Embedder) OEmbedHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if format := query.Get("format"); format != "" && format != "json" {
+			http.Error(w, http.StatusText(http.StatusNotImplemented), http.StatusNotImplemented)
+			return
+		}
+
+		postID, ok := e.boostIDFromURL(query.Get("url"))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		boost, ok := e.getBoost(w, r, postID)
+		if !ok {
+			return
+		}
+
+		width := widgetDimension(query.Get("maxwidth"), defaultWidgetWidth, minWidgetWidth)
+		height := widgetDimension(query.Get("maxheight"), defaultWidgetHeight, minWidgetHeight)
+		src := e.widgetURL(boost.ID)
+		if themeQuery := ThemeFromQuery(query).Query(); themeQuery != "" {
+			src += "?" + themeQuery
+		}
+
+		body, err := json.Marshal(&oEmbedResponse{
+			Version:      "1.0",
+			Type:         "rich",
+			ProviderName: e.providerName,
+			ProviderURL:  e.siteURL,
+			Title:        boost.Title,
+			HTML: fmt.Sprintf(`<iframe src="%v" width="%v" height="%v" frameborder="0" scrolling="no" title="%v"></iframe>`,
+				template.HTMLEscapeString(src), width, height, template.HTMLEscapeString(boost.Title)),
+			Width:    width,
+			Height:   height,
+			CacheAge: e.cacheMaxAge,
+		})
+		if err != nil {
+			log.Errorf("error marshalling oembed response: %v", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		e.write(w, r, "application/json", body)
+	}
+}
+
+// getBoost writes a not found or error response if the boost can't be embedded
+func (e *Embedder) getBoost(w http.ResponseWriter, r *http.Request, postID string) (*BoostEmbed, bool) {
+	boost, err := e.Boost(postID)
+	if err == posts.ErrorNotFound {
+		http.NotFound(w, r)
+		return nil, false
+	}
+	if err != nil {
+		log.Errorf("error building boost embed: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil, false
+	}
+	return boost, true
+}
+
+// widgetURL returns the URL of a boost's widget, {apiURL}/boost/{id}
+func (e *Embedder) widgetURL(postID string) string {
+	return e.apiURL + "/boost/" + postID
+}
+
+// boostIDFromURL returns the boost ID from a URL on the Civil site or this API
+func (e *Embedder) boostIDFromURL(rawURL string) (string, bool) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return "", false
+	}
+	if !sameHost(parsed, e.siteURL) && !sameHost(parsed, e.apiURL) {
+		return "", false
+	}
+
+	match := boostPathRegexp.FindStringSubmatch(parsed.Path)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+// write sets the cache headers and writes the body, or not modified if the client's copy is current.
+// Payments don't change the boost's update time, so the ETag is a hash of the body
+func (e *Embedder) write(w http.ResponseWriter, r *http.Request, contentType string, body []byte) {
+	hash := sha1.Sum(body) // nolint: gosec
+	etag := fmt.Sprintf("\"%v\"", hex.EncodeToString(hash[:]))
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%v", e.cacheMaxAge))
+
+	if utils.NotModified(r, etag, time.Time{}) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(body) // nolint: errcheck
+}
+
+func sameHost(parsed *url.URL, baseURL string) bool {
+	base, err := url.Parse(baseURL)
+	return err == nil && base.Host != "" && parsed.Host == base.Host
+}
+
+func widgetDimension(max string, defaultValue int, minValue int) int {
+	value, err := strconv.Atoi(max)
+	if err != nil || value <= 0 || value >= defaultValue {
+		return defaultValue
+	}
+	if value < minValue {
+		return minValue
+	}
+	return value
+}
@@ -0,0 +1,227 @@
+package embeds_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/joincivil/civil-api-server/pkg/embeds"
+	"github.com/joincivil/civil-api-server/pkg/payments"
+	"github.com/joincivil/civil-api-server/pkg/posts"
+)
+
+const (
+	testBoostID = "11111111-1111-1111-1111-111111111111"
+	testDraftID = "22222222-2222-2222-2222-222222222222"
+	testLinkID  = "33333333-3333-3333-3333-333333333333"
+)
+
+type mockPostSource struct{}
+
+func (m *mockPostSource) GetPost(id string) (posts.Post, error) {
+	switch id {
+	case testBoostID:
+		return &posts.Boost{
+			PostModel:    posts.PostModel{ID: testBoostID},
+			Title:        "Fund our <investigation>",
+			CurrencyCode: "USD",
+			GoalAmount:   5000,
+			DateEnd:      time.Now().Add(24 * time.Hour),
+		}, nil
+	case testDraftID:
+		return &posts.Boost{PostModel: posts.PostModel{ID: testDraftID, Draft: true}, Title: "Draft"}, nil
+	case testLinkID:
+		return &posts.ExternalLink{PostModel: posts.PostModel{ID: testLinkID}, URL: "https://example.com"}, nil
+	}
+	return nil, posts.ErrorNotFound
+}
+
+type mockPaymentSource struct{}
+
+func (m *mockPaymentSource) TotalPayments(postID string, currencyCode string) (float64, error) {
+	return 1250.5, nil
+}
+
+func (m *mockPaymentSource) GetGroupedSanitizedPayments(postID string) ([]*payments.SanitizedPayment, error) {
+	return []*payments.SanitizedPayment{{PayerChannelID: "a"}, {PayerChannelID: "b"}, {}}, nil
+}
+
+func newTestEmbedRouter() chi.Router {
+	embedder := embeds.NewEmbedder(&mockPostSource{}, &mockPaymentSource{}, embeds.EmbedConfig{
+		SiteURL:     "https://civil.test",
+		APIURL:      "https://api.civil.test/v1/embed/",
+		CacheMaxAge: 120,
+	})
+	router := chi.NewRouter()
+	router.Route("/v1/embed", func(r chi.Router) {
+		r.Get("/boost/{postID}", embedder.BoostHandler())
+		r.Get("/boost/{postID}/json", embedder.BoostJSONHandler())
+		r.Get("/oembed", embedder.OEmbedHandler())
+	})
+	return router
+}
+
+func get(router chi.Router, target string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", target, nil)
+	for name := range header {
+		req.Header.Set(name, header.Get(name))
+	}
+	if host := header.Get("Host"); host != "" {
+		req.Host = host
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestBoostWidget(t *testing.T) {
+	router := newTestEmbedRouter()
+
+	w := get(router, "/v1/embed/boost/"+testBoostID, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v", w.Code)
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("expected html, got %v", w.Header().Get("Content-Type"))
+	}
+	if w.Header().Get("Cache-Control") != "public, max-age=120" {
+		t.Fatalf("unexpected cache control: %v", w.Header().Get("Cache-Control"))
+	}
+	body := w.Body.String()
+	for _, expected := range []string{
+		"Fund our &lt;investigation&gt;",
+		"$1,250</strong> raised of $5,000 goal",
+		"3 supporters",
+		"width: 25%",
+		`href="https://civil.test/boosts/` + testBoostID + `"`,
+		">Support</a>",
+		`type="application/json+oembed" href="https://api.civil.test/v1/embed/oembed?url=` +
+			url.QueryEscape("https://api.civil.test/v1/embed/boost/"+testBoostID) + `"`,
+		"background: #ffffff",
+		"background: #2b56ff",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Fatalf("expected the widget to contain %q, got: %v", expected, body)
+		}
+	}
+
+	// cached widgets must not depend on headers the client controls
+	forged := get(router, "/v1/embed/boost/"+testBoostID, http.Header{"X-Forwarded-Proto": {"javascript"}, "Host": {"evil.test"}})
+	if forged.Body.String() != body {
+		t.Fatalf("expected the widget to ignore the request host and forwarded proto, got: %v", forged.Body.String())
+	}
+
+	notModified := get(router, "/v1/embed/boost/"+testBoostID, http.Header{"If-None-Match": {w.Header().Get("ETag")}})
+	if notModified.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 for a matching etag, got %v", notModified.Code)
+	}
+}
+
+func TestBoostWidgetTheme(t *testing.T) {
+	router := newTestEmbedRouter()
+
+	w := get(router, "/v1/embed/boost/"+testBoostID+"?theme=dark&accent=FF0000", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v", w.Code)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "background: #1c1c1e") || !strings.Contains(body, "background: #ff0000") {
+		t.Fatalf("expected the dark theme with a red accent, got: %v", body)
+	}
+	if !strings.Contains(body, "accent=ff0000&amp;theme=dark") {
+		t.Fatalf("expected the theme to be passed to the oembed endpoint, got: %v", body)
+	}
+
+	w = get(router, "/v1/embed/boost/"+testBoostID+"?theme=neon&accent="+url.QueryEscape("red;}body{"), nil)
+	body = w.Body.String()
+	if !strings.Contains(body, "background: #ffffff") || strings.Contains(body, "red;") {
+		t.Fatalf("expected invalid theme params to be ignored, got: %v", body)
+	}
+}
+
+func TestBoostWidgetNotFound(t *testing.T) {
+	router := newTestEmbedRouter()
+
+	for _, id := range []string{testDraftID, testLinkID, "44444444-4444-4444-4444-444444444444"} {
+		if w := get(router, "/v1/embed/boost/"+id, nil); w.Code != http.StatusNotFound {
+			t.Fatalf("expected 404 for %v, got %v", id, w.Code)
+		}
+		if w := get(router, "/v1/embed/boost/"+id+"/json", nil); w.Code != http.StatusNotFound {
+			t.Fatalf("expected 404 for %v json, got %v", id, w.Code)
+		}
+	}
+}
+
+func TestBoostJSON(t *testing.T) {
+	router := newTestEmbedRouter()
+
+	w := get(router, "/v1/embed/boost/"+testBoostID+"/json", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v", w.Code)
+	}
+	if w.Header().Get("Access-Control-Allow-Origin") != "*" || w.Header().Get("ETag") == "" {
+		t.Fatalf("expected cors and cache headers")
+	}
+
+	boost := &embeds.BoostEmbed{}
+	if err := json.Unmarshal(w.Body.Bytes(), boost); err != nil {
+		t.Fatalf("error decoding json: %v", err)
+	}
+	if boost.ID != testBoostID || boost.PaymentsTotal != 1250.5 || boost.GoalAmount != 5000 ||
+		boost.Supporters != 3 || boost.Ended {
+		t.Fatalf("unexpected boost embed: %+v", boost)
+	}
+}
+
+func TestOEmbed(t *testing.T) {
+	router := newTestEmbedRouter()
+
+	w := get(router, "/v1/embed/oembed?maxwidth=300&theme=dark&url="+url.QueryEscape("https://civil.test/boosts/"+testBoostID), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v", w.Code)
+	}
+	response := map[string]interface{}{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("error decoding json: %v", err)
+	}
+	if response["type"] != "rich" || response["version"] != "1.0" || response["provider_url"] != "https://civil.test" {
+		t.Fatalf("unexpected oembed response: %v", response)
+	}
+	if response["width"] != float64(300) || response["height"] != float64(200) {
+		t.Fatalf("expected the width to be limited to maxwidth, got %v x %v", response["width"], response["height"])
+	}
+	html, _ := response["html"].(string)
+	if !strings.Contains(html, `src="https://api.civil.test/v1/embed/boost/`+testBoostID+`?theme=dark"`) {
+		t.Fatalf("unexpected oembed html: %v", html)
+	}
+
+	// the widget's own url can be embedded too
+	w = get(router, "/v1/embed/oembed?url="+url.QueryEscape("https://api.civil.test/v1/embed/boost/"+testBoostID), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for the widget url, got %v", w.Code)
+	}
+	// but not a url on whatever host the request was sent to
+	w = get(router, "/v1/embed/oembed?url="+url.QueryEscape("http://example.com/v1/embed/boost/"+testBoostID), nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for the request host, got %v", w.Code)
+	}
+
+	w = get(router, "/v1/embed/oembed?format=xml&url="+url.QueryEscape("https://civil.test/boosts/"+testBoostID), nil)
+	if w.Code != http.StatusNotImplemented {
+		t.Fatalf("expected 501 for xml, got %v", w.Code)
+	}
+	w = get(router, "/v1/embed/oembed?url="+url.QueryEscape("https://elsewhere.test/boosts/"+testBoostID), nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for another site, got %v", w.Code)
+	}
+	w = get(router, "/v1/embed/oembed?url="+url.QueryEscape("https://civil.test/boosts/"+testDraftID), nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a draft, got %v", w.Code)
+	}
+}
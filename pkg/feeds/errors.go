@@ -0,0 +1,16 @@
+package feeds
+
+import "errors"
+
+var (
+	// ErrorNotFound is returned when a channel has no feed registered
+	ErrorNotFound = errors.New("feed not found")
+	// ErrorUnauthorized is returned when a user who is not a channel admin tries to manage its feed
+	ErrorUnauthorized = errors.New("unauthorized")
+	// ErrorInvalidFeedURL is returned when a feed url is not an absolute http or https url
+	ErrorInvalidFeedURL = errors.New("invalid feed url")
+	// ErrorChannelNotNewsroom is returned when registering a feed for a channel that is not a newsroom
+	ErrorChannelNotNewsroom = errors.New("feeds can only be registered for newsroom channels")
+	// ErrorUnsupportedFeedFormat is returned when a fetched document is neither RSS 2.0 nor Atom
+	ErrorUnsupportedFeedFormat = errors.New("unsupported feed format")
+)
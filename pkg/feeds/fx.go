@@ -0,0 +1,12 @@
+package feeds
+
+import "go.uber.org/fx"
+
+// FeedModule builds feed services
+var FeedModule = fx.Options(
+	fx.Provide(
+		NewDBPersister,
+		NewServiceFromConfig,
+		NewSyndicatorFromConfig,
+	),
+)
@@ -0,0 +1,223 @@
+package feeds
+
+import (
+	"crypto/sha1" // nolint: gosec
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	log "github.com/golang/glog"
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/joincivil/civil-api-server/pkg/channels"
+	"github.com/joincivil/civil-api-server/pkg/posts"
+	"github.com/joincivil/civil-api-server/pkg/utils"
+	"github.com/joincivil/go-common/pkg/newsroom"
+)
+
+const (
+	// ChannelIDURLParam is the name of the URL param holding the channel ID
+	ChannelIDURLParam = "channelID"
+	// FormatURLParam is the name of the URL param holding the feed format
+	FormatURLParam = "format"
+
+	defaultSyndicationItemLimit = 50
+	globalFeedTitle             = "Civil Storyfeed"
+	globalFeedDescription       = "The latest stories and Boosts from newsrooms on Civil"
+
+	// syndicated storyfeeds include boosts unless another algorithm is requested
+	defaultSyndicationAlg = posts.StoryfeedAlgFairWithInterleavedBoosts
+)
+
+// StoryfeedSource defines the post functions needed to syndicate storyfeeds
+type StoryfeedSource interface {
+	SearchPostsRanked(limit int, after *posts.StoryfeedCursor, filter *posts.StoryfeedFilter) (*posts.PostSearchResult, error)
+}
+
+// NewsroomHelper defines the newsroom functions needed to describe a channel's feed
+type NewsroomHelper interface {
+	GetNewsroomByAddress(newsroomAddress string) (*newsroom.Newsroom, error)
+}
+
+// SyndicationConfig configures the storyfeed syndication endpoints
+type SyndicationConfig struct {
+	// SiteURL is the base URL of the Civil site, used to link to Boosts
+	SiteURL   string
+	ItemLimit int
+}
+
+// Syndicator renders storyfeeds as RSS, Atom and JSON feeds
+type Syndicator struct {
+	source         StoryfeedSource
+	channelHelper  ChannelHelper
+	newsroomHelper NewsroomHelper
+	siteURL        string
+	itemLimit      int
+}
+
+// NewSyndicator builds an instance of feeds.Syndicator
+func NewSyndicator(source StoryfeedSource, channelHelper ChannelHelper, newsroomHelper NewsroomHelper, config SyndicationConfig) *Syndicator {
+	itemLimit := config.ItemLimit
+	if itemLimit <= 0 {
+		itemLimit = defaultSyndicationItemLimit
+	}
+
+	return &Syndicator{
+		source:         source,
+		channelHelper:  channelHelper,
+		newsroomHelper: newsroomHelper,
+		siteURL:        config.SiteURL,
+		itemLimit:      itemLimit,
+	}
+}
+
+// NewSyndicatorFromConfig builds an instance of feeds.Syndicator using the graphql config
+func NewSyndicatorFromConfig(source StoryfeedSource, channelHelper ChannelHelper, newsroomHelper NewsroomHelper, config *utils.GraphQLConfig) *Syndicator {
+	return NewSyndicator(source, channelHelper, newsroomHelper, SyndicationConfig{
+		SiteURL:   config.SyndicationSiteURL,
+		ItemLimit: config.SyndicationItemLimit,
+	})
+}
+
+// GlobalStoryfeed returns the global storyfeed prepared for syndication
+func (s *Syndicator) GlobalStoryfeed(alg string) (*Syndication, error) {
+	syndication := &Syndication{
+		Title:       globalFeedTitle,
+		Description: globalFeedDescription,
+		HomeURL:     s.siteURL,
+	}
+
+	return syndication, s.addItems(syndication, &posts.StoryfeedFilter{Alg: syndicationAlg(alg)})
+}
+
+// ChannelStoryfeed returns a channel's storyfeed prepared for syndication
+func (s *Syndicator) ChannelStoryfeed(channelID string, alg string) (*Syndication, error) {
+	if _, err := uuid.FromString(channelID); err != nil {
+		return nil, channels.ErrorNotFound
+	}
+	channel, err := s.channelHelper.GetChannel(channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel == nil {
+		return nil, channels.ErrorNotFound
+	}
+
+	syndication := &Syndication{
+		HomeURL: s.siteURL,
+	}
+	if channel.Handle != nil {
+		syndication.Title = *channel.Handle
+	}
+	if channel.ChannelType == channels.TypeNewsroom {
+		nr, err := s.newsroomHelper.GetNewsroomByAddress(channel.Reference)
+		if err != nil {
+			return nil, err
+		}
+		syndication.Title = nr.Name
+		if nr.Charter != nil {
+			syndication.Description = nr.Charter.Tagline
+			if nr.Charter.NewsroomURL != "" {
+				syndication.HomeURL = nr.Charter.NewsroomURL
+			}
+		}
+	}
+	if syndication.Title == "" {
+		syndication.Title = globalFeedTitle
+	}
+
+	return syndication, s.addItems(syndication, &posts.StoryfeedFilter{Alg: syndicationAlg(alg), ChannelID: &channel.ID})
+}
+
+func syndicationAlg(alg string) string {
+	if alg == "" {
+		return defaultSyndicationAlg
+	}
+	return alg
+}
+
+func (s *Syndicator) addItems(syndication *Syndication, filter *posts.StoryfeedFilter) error {
+	result, err := s.source.SearchPostsRanked(s.itemLimit, nil, filter)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range result.Posts {
+		if item, ok := SyndicationItemFromPost(post, s.siteURL); ok {
+			syndication.Items = append(syndication.Items, item)
+		}
+	}
+	return nil
+}
+
+// GlobalStoryfeedHandler is a REST endpoint handler that renders the global storyfeed
+func (s *Syndicator) GlobalStoryfeedHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		syndication, err := s.GlobalStoryfeed(r.URL.Query().Get("alg"))
+		s.writeSyndication(w, r, syndication, err)
+	}
+}
+
+// ChannelStoryfeedHandler is a REST endpoint handler that renders a channel's storyfeed
+func (s *Syndicator) ChannelStoryfeedHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		syndication, err := s.ChannelStoryfeed(chi.URLParam(r, ChannelIDURLParam), r.URL.Query().Get("alg"))
+		if err == channels.ErrorNotFound {
+			http.NotFound(w, r)
+			return
+		}
+		s.writeSyndication(w, r, syndication, err)
+	}
+}
+
+func (s *Syndicator) writeSyndication(w http.ResponseWriter, r *http.Request, syndication *Syndication, err error) {
+	format := chi.URLParam(r, FormatURLParam)
+	if format != FormatRSS && format != FormatAtom && format != FormatJSON {
+		http.NotFound(w, r)
+		return
+	}
+	if err == posts.ErrorBadFilterProvided {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Errorf("error building storyfeed syndication: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	syndication.FeedURL = utils.RequestURL(r)
+
+	etag := syndicationETag(format, syndication)
+	lastModified := syndication.LastModified().UTC().Truncate(time.Second)
+	w.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+
+	if utils.NotModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	body, contentType, err := syndication.Render(format)
+	if err != nil {
+		log.Errorf("error rendering storyfeed syndication: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(body) // nolint: errcheck
+}
+
+// syndicationETag hashes the feed format and the id and update time of each item
+func syndicationETag(format string, syndication *Syndication) string {
+	hash := sha1.New() // nolint: gosec
+
+	fmt.Fprintf(hash, "%v\n%v\n", format, syndication.Title) // nolint: errcheck
+	for _, item := range syndication.Items {
+		fmt.Fprintf(hash, "%v:%v\n", item.ID, item.Updated.UnixNano()) // nolint: errcheck
+	}
+	return fmt.Sprintf("\"%v\"", hex.EncodeToString(hash.Sum(nil)))
+}
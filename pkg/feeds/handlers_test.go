@@ -0,0 +1,236 @@
+package feeds_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/joincivil/civil-api-server/pkg/feeds"
+	"github.com/joincivil/civil-api-server/pkg/posts"
+	"github.com/joincivil/go-common/pkg/newsroom"
+)
+
+type mockStoryfeedSource struct {
+	posts  []posts.Post
+	filter *posts.StoryfeedFilter
+}
+
+func (m *mockStoryfeedSource) SearchPostsRanked(limit int, after *posts.StoryfeedCursor, filter *posts.StoryfeedFilter) (*posts.PostSearchResult, error) {
+	m.filter = filter
+	return &posts.PostSearchResult{Posts: m.posts}, nil
+}
+
+type mockNewsroomHelper struct{}
+
+func (m *mockNewsroomHelper) GetNewsroomByAddress(newsroomAddress string) (*newsroom.Newsroom, error) {
+	return &newsroom.Newsroom{
+		Name:    "Test Newsroom",
+		Charter: &newsroom.Charter{NewsroomURL: "https://example.com", Tagline: "News you can trust"},
+	}, nil
+}
+
+func newTestSyndicationRouter(source *mockStoryfeedSource) chi.Router {
+	syndicator := feeds.NewSyndicator(source, &mockChannelHelper{}, &mockNewsroomHelper{}, feeds.SyndicationConfig{
+		SiteURL: "https://civil.test",
+	})
+	router := chi.NewRouter()
+	router.Get("/storyfeed/{format}", syndicator.GlobalStoryfeedHandler())
+	router.Get("/channels/{channelID}/storyfeed/{format}", syndicator.ChannelStoryfeedHandler())
+	return router
+}
+
+func testStoryfeedPosts() []posts.Post {
+	published := time.Date(2019, 9, 1, 10, 0, 0, 0, time.UTC)
+	updated := time.Date(2019, 9, 3, 12, 30, 0, 0, time.UTC)
+	og, _ := json.Marshal(map[string]interface{}{
+		"title":       "A Great Story",
+		"description": "Everything you need to know",
+		"images":      []map[string]string{{"url": "https://example.com/image.jpg"}},
+	})
+
+	return []posts.Post{
+		&posts.ExternalLink{
+			PostModel:     posts.PostModel{ID: "11111111-1111-1111-1111-111111111111", ChannelID: testChannelID, UpdatedAt: updated},
+			URL:           "https://example.com/story",
+			OpenGraphData: og,
+			PublishedTime: &published,
+		},
+		&posts.Boost{
+			PostModel:    posts.PostModel{ID: "22222222-2222-2222-2222-222222222222", ChannelID: testChannelID, CreatedAt: published, UpdatedAt: published},
+			Title:        "Fund our reporting",
+			CurrencyCode: "USD",
+			GoalAmount:   500,
+			DateEnd:      time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC),
+			What:         "An investigation",
+		},
+		&posts.Comment{
+			PostModel: posts.PostModel{ID: "33333333-3333-3333-3333-333333333333"},
+		},
+	}
+}
+
+func TestSyndicationFormats(t *testing.T) {
+	source := &mockStoryfeedSource{posts: testStoryfeedPosts()}
+	router := newTestSyndicationRouter(source)
+
+	req := httptest.NewRequest("GET", "/channels/"+testChannelID+"/storyfeed/rss", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 for rss feed, got %v", rec.Code)
+	}
+	if source.filter.ChannelID == nil || *source.filter.ChannelID != testChannelID {
+		t.Fatalf("expected storyfeed to be filtered by channel")
+	}
+	if source.filter.Alg != posts.StoryfeedAlgFairWithInterleavedBoosts {
+		t.Fatalf("expected storyfeed to include boosts by default, got %v", source.filter.Alg)
+	}
+	var rss struct {
+		Channel struct {
+			Title string `xml:"title"`
+			Items []struct {
+				Title   string `xml:"title"`
+				Link    string `xml:"link"`
+				PubDate string `xml:"pubDate"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(rec.Body.Bytes(), &rss); err != nil {
+		t.Fatalf("was not expecting error parsing rss output: %v", err)
+	}
+	if rss.Channel.Title != "Test Newsroom" {
+		t.Fatalf("expected newsroom name as title, got %v", rss.Channel.Title)
+	}
+	if len(rss.Channel.Items) != 2 {
+		t.Fatalf("expected comments to be left out of the feed, got %v items", len(rss.Channel.Items))
+	}
+	if rss.Channel.Items[0].Title != "A Great Story" || rss.Channel.Items[0].PubDate != "Sun, 01 Sep 2019 10:00:00 +0000" {
+		t.Fatalf("expected item to use open graph title and published time, got %+v", rss.Channel.Items[0])
+	}
+	if rss.Channel.Items[1].Link != "https://civil.test/boosts/22222222-2222-2222-2222-222222222222" {
+		t.Fatalf("expected boost to link to the site, got %v", rss.Channel.Items[1].Link)
+	}
+	if rec.Header().Get("Last-Modified") != "Tue, 03 Sep 2019 12:30:00 GMT" {
+		t.Fatalf("expected last modified from latest post update, got %v", rec.Header().Get("Last-Modified"))
+	}
+
+	req = httptest.NewRequest("GET", "/storyfeed/atom", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/atom+xml") {
+		t.Fatalf("expected atom feed, got %v %v", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var atom struct {
+		Entries []struct {
+			ID      string `xml:"id"`
+			Summary string `xml:"summary"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(rec.Body.Bytes(), &atom); err != nil {
+		t.Fatalf("was not expecting error parsing atom output: %v", err)
+	}
+	if len(atom.Entries) != 2 || !strings.Contains(atom.Entries[1].Summary, "Goal: 500.00 USD") {
+		t.Fatalf("expected boost details in atom summary")
+	}
+
+	req = httptest.NewRequest("GET", "/storyfeed/json", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	var jsonFeed struct {
+		Version string `json:"version"`
+		FeedURL string `json:"feed_url"`
+		Items   []struct {
+			Image string `json:"image"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &jsonFeed); err != nil {
+		t.Fatalf("was not expecting error parsing json feed output: %v", err)
+	}
+	if jsonFeed.Version != "https://jsonfeed.org/version/1" || jsonFeed.FeedURL != "http://example.com/storyfeed/json" {
+		t.Fatalf("unexpected json feed header: %+v", jsonFeed)
+	}
+	if len(jsonFeed.Items) != 2 || jsonFeed.Items[0].Image != "https://example.com/image.jpg" {
+		t.Fatalf("expected open graph image on json feed item")
+	}
+
+	req = httptest.NewRequest("GET", "/storyfeed/xml", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown format, got %v", rec.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/storyfeed/rss?alg=vw_post_externallink_chronological", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || source.filter.Alg != "vw_post_externallink_chronological" {
+		t.Fatalf("expected the requested algorithm to be used, got %v", source.filter.Alg)
+	}
+
+	req = httptest.NewRequest("GET", "/channels/not-a-channel/storyfeed/rss", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a malformed channel id, got %v", rec.Code)
+	}
+}
+
+func TestSyndicationConditionalGet(t *testing.T) {
+	source := &mockStoryfeedSource{posts: testStoryfeedPosts()}
+	router := newTestSyndicationRouter(source)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/storyfeed/rss", nil))
+	etag := rec.Header().Get("ETag")
+	lastModified := rec.Header().Get("Last-Modified")
+	if etag == "" || lastModified == "" {
+		t.Fatalf("expected ETag and Last-Modified headers")
+	}
+
+	req := httptest.NewRequest("GET", "/storyfeed/rss", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Fatalf("expected 304 for matching etag, got %v", rec.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/storyfeed/atom", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected etag to differ between formats, got %v", rec.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/storyfeed/rss", nil)
+	req.Header.Set("If-Modified-Since", lastModified)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 for unmodified feed, got %v", rec.Code)
+	}
+
+	source.posts[1].(*posts.Boost).UpdatedAt = time.Date(2019, 9, 4, 0, 0, 0, 0, time.UTC)
+	req = httptest.NewRequest("GET", "/storyfeed/rss", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 once a post is updated, got %v", rec.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/storyfeed/rss", nil)
+	req.Header.Set("If-Modified-Since", lastModified)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 when modified since, got %v", rec.Code)
+	}
+}
@@ -0,0 +1,69 @@
+package feeds
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// FETCH STATUSES
+const (
+	// FetchStatusPending is the status of a feed that has not been fetched yet
+	FetchStatusPending = "pending"
+	// FetchStatusOK is the status of a feed whose items were all imported or already existed
+	FetchStatusOK = "ok"
+	// FetchStatusPartial is the status of a feed that was fetched but some items could not be imported
+	FetchStatusPartial = "partial"
+	// FetchStatusError is the status of a feed that could not be fetched or parsed
+	FetchStatusError = "error"
+)
+
+// ChannelFeed is an RSS or Atom feed registered to a newsroom channel. Items in the feed
+// are imported as ExternalLink posts on the channel
+type ChannelFeed struct {
+	ID              string `gorm:"type:uuid;primary_key"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeletedAt       *time.Time
+	ChannelID       string `gorm:"type:uuid;not null;unique_index:idx_channelfeed_channel_id"`
+	URL             string `gorm:"not null"`
+	CreatedByUserID string `gorm:"type:uuid;not null"` // posts are created on behalf of the admin that registered the feed
+	LastFetchedAt   *time.Time
+	LastSuccessAt   *time.Time
+	LastStatus      string `gorm:"not null"`
+	LastError       string
+	PostsCreated    int `gorm:"not null;default:0"`
+}
+
+// TableName returns the gorm table name for ChannelFeed
+func (ChannelFeed) TableName() string {
+	return "channel_feeds"
+}
+
+// BeforeCreate is a GORM hook that sets the ID before it its persisted
+func (f *ChannelFeed) BeforeCreate() (err error) {
+	id := uuid.NewV4()
+	f.ID = id.String()
+	return
+}
+
+// ChannelFeedItem records a feed item link that was imported to a channel. The post created for an item is
+// referenced by the canonical url of the page, which can differ from the item link, so later polls check the
+// link here to skip the item without fetching the page again
+type ChannelFeedItem struct {
+	ChannelID string `gorm:"type:uuid;primary_key"`
+	Link      string `gorm:"primary_key"`
+	CreatedAt time.Time
+	PostID    *string `gorm:"type:uuid"` // nil when the page had already been posted to the channel
+}
+
+// TableName returns the gorm table name for ChannelFeedItem
+func (ChannelFeedItem) TableName() string {
+	return "channel_feed_items"
+}
+
+// FeedItem is an entry in an RSS or Atom feed
+type FeedItem struct {
+	Link          string
+	PublishedTime *time.Time
+}
@@ -0,0 +1,139 @@
+package feeds
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+)
+
+type rssDocument struct {
+	Channel struct {
+		Items []struct {
+			Link    string `xml:"link"`
+			GUID    string `xml:"guid"`
+			PubDate string `xml:"pubDate"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+type atomDocument struct {
+	Entries []struct {
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		ID        string `xml:"id"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+var rssDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+}
+
+// ParseFeed parses an RSS 2.0 or Atom document and returns its items in document order
+func ParseFeed(data []byte) ([]*FeedItem, error) {
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch root {
+	case "rss":
+		return parseRSS(data)
+	case "feed":
+		return parseAtom(data)
+	}
+	return nil, ErrorUnsupportedFeedFormat
+}
+
+func rootElement(data []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return "", ErrorUnsupportedFeedFormat
+		}
+		if err != nil {
+			return "", err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func parseRSS(data []byte) ([]*FeedItem, error) {
+	doc := &rssDocument{}
+	if err := xml.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+
+	var items []*FeedItem
+	for _, item := range doc.Channel.Items {
+		link := strings.TrimSpace(item.Link)
+		if link == "" && strings.HasPrefix(strings.TrimSpace(item.GUID), "http") {
+			link = strings.TrimSpace(item.GUID)
+		}
+		if link == "" {
+			continue
+		}
+		items = append(items, &FeedItem{
+			Link:          link,
+			PublishedTime: parseTime(item.PubDate, rssDateLayouts),
+		})
+	}
+
+	return items, nil
+}
+
+func parseAtom(data []byte) ([]*FeedItem, error) {
+	doc := &atomDocument{}
+	if err := xml.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+
+	var items []*FeedItem
+	for _, entry := range doc.Entries {
+		var link string
+		for _, l := range entry.Links {
+			if l.Rel == "" || l.Rel == "alternate" {
+				link = strings.TrimSpace(l.Href)
+				break
+			}
+		}
+		if link == "" {
+			continue
+		}
+		published := entry.Published
+		if published == "" {
+			published = entry.Updated
+		}
+		items = append(items, &FeedItem{
+			Link:          link,
+			PublishedTime: parseTime(published, []string{time.RFC3339}),
+		})
+	}
+
+	return items, nil
+}
+
+func parseTime(value string, layouts []string) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	for _, layout := range layouts {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return &t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package feeds
+
+// Persister defines the methods needed to persist ChannelFeeds
+type Persister interface {
+	SaveFeed(channelID string, url string, userID string) (*ChannelFeed, error)
+	GetFeedByChannelID(channelID string) (*ChannelFeed, error)
+	GetFeeds() ([]*ChannelFeed, error)
+	DeleteFeed(channelID string) error
+	UpdateFetchStatus(feedID string, status string, fetchError string, postsCreated int) error
+	IsItemImported(channelID string, link string) (bool, error)
+	SaveImportedItem(channelID string, link string, postID *string) error
+}
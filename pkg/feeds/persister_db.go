@@ -0,0 +1,110 @@
+package feeds
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// DBPersister implements the Persister interface using GORM
+type DBPersister struct {
+	db *gorm.DB
+}
+
+// NewDBPersister instantiates a new DBPersister
+func NewDBPersister(db *gorm.DB) *DBPersister {
+	return &DBPersister{
+		db,
+	}
+}
+
+// SaveFeed registers the feed url for the channel, replacing any existing feed
+func (p *DBPersister) SaveFeed(channelID string, url string, userID string) (*ChannelFeed, error) {
+	feed := &ChannelFeed{}
+	err := p.db.Where(&ChannelFeed{ChannelID: channelID}).
+		Assign(ChannelFeed{URL: url, CreatedByUserID: userID, LastStatus: FetchStatusPending}).
+		FirstOrCreate(feed).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "error saving feed")
+	}
+
+	return feed, nil
+}
+
+// GetFeedByChannelID retrieves the feed registered for the channel
+func (p *DBPersister) GetFeedByChannelID(channelID string) (*ChannelFeed, error) {
+	feed := &ChannelFeed{}
+	if p.db.Where(&ChannelFeed{ChannelID: channelID}).First(feed).RecordNotFound() {
+		return nil, ErrorNotFound
+	}
+
+	return feed, nil
+}
+
+// GetFeeds retrieves all registered feeds
+func (p *DBPersister) GetFeeds() ([]*ChannelFeed, error) {
+	var feeds []*ChannelFeed
+	if err := p.db.Find(&feeds).Error; err != nil {
+		return nil, errors.Wrap(err, "error getting feeds")
+	}
+
+	return feeds, nil
+}
+
+// DeleteFeed removes the feed registered for the channel
+// this uses the `Unscoped` Delete function to remove entry from DB, rather than just
+// setting deleted_at
+func (p *DBPersister) DeleteFeed(channelID string) error {
+	err := p.db.Unscoped().Where(&ChannelFeed{ChannelID: channelID}).Delete(&ChannelFeed{}).Error
+	if err != nil {
+		return errors.Wrap(err, "error deleting feed")
+	}
+
+	return nil
+}
+
+// UpdateFetchStatus records the outcome of fetching a feed
+func (p *DBPersister) UpdateFetchStatus(feedID string, status string, fetchError string, postsCreated int) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"last_fetched_at": now,
+		"last_status":     status,
+		"last_error":      fetchError,
+		"posts_created":   gorm.Expr("posts_created + ?", postsCreated),
+	}
+	if status != FetchStatusError {
+		updates["last_success_at"] = now
+	}
+
+	err := p.db.Model(&ChannelFeed{ID: feedID}).Updates(updates).Error
+	if err != nil {
+		return errors.Wrap(err, "error updating feed fetch status")
+	}
+
+	return nil
+}
+
+// IsItemImported returns whether the feed item link has been imported to the channel
+func (p *DBPersister) IsItemImported(channelID string, link string) (bool, error) {
+	var count int
+	err := p.db.Model(&ChannelFeedItem{}).Where(&ChannelFeedItem{ChannelID: channelID, Link: link}).Count(&count).Error
+	if err != nil {
+		return false, errors.Wrap(err, "error getting feed item")
+	}
+
+	return count > 0, nil
+}
+
+// SaveImportedItem records that the feed item link has been imported to the channel as the given post
+func (p *DBPersister) SaveImportedItem(channelID string, link string, postID *string) error {
+	item := &ChannelFeedItem{}
+	err := p.db.Where(&ChannelFeedItem{ChannelID: channelID, Link: link}).
+		Assign(ChannelFeedItem{PostID: postID}).
+		FirstOrCreate(item).Error
+	if err != nil {
+		return errors.Wrap(err, "error saving feed item")
+	}
+
+	return nil
+}
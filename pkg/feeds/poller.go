@@ -0,0 +1,26 @@
+package feeds
+
+import (
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/joincivil/civil-api-server/pkg/utils"
+)
+
+// FeedPollerCron polls registered channel feeds on a regular interval
+func FeedPollerCron(service *Service, config *utils.GraphQLConfig) {
+	interval := time.Duration(config.FeedPollIntervalSecs) * time.Second
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			err := service.PollFeeds()
+			if err != nil {
+				log.Errorf("error polling feeds: %v", err)
+			}
+		}
+	}()
+}
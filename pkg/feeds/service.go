@@ -0,0 +1,228 @@
+package feeds
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	log "github.com/golang/glog"
+	"github.com/lib/pq"
+
+	"github.com/joincivil/civil-api-server/pkg/channels"
+	"github.com/joincivil/civil-api-server/pkg/posts"
+	"github.com/joincivil/civil-api-server/pkg/utils"
+)
+
+const (
+	defaultMaxItemsPerPoll = 20
+	maxRecordedErrors      = 5
+	uniqueViolationCode    = "23505"
+)
+
+// PostCreator defines the post functions needed to import feed items
+type PostCreator interface {
+	CreatePost(authorID string, post posts.Post) (posts.Post, error)
+	GetPostByReferenceSafe(reference string) (posts.Post, error)
+}
+
+// ChannelHelper defines the channel functions needed to manage feeds
+type ChannelHelper interface {
+	IsChannelAdmin(userID string, channelID string) (bool, error)
+	GetChannel(id string) (*channels.Channel, error)
+}
+
+// ServiceConfig configures how feeds are polled
+type ServiceConfig struct {
+	MaxItemsPerPoll int
+}
+
+// Service provides methods to register and poll channel feeds
+type Service struct {
+	persister     Persister
+	postCreator   PostCreator
+	channelHelper ChannelHelper
+	fetcher       *utils.Fetcher
+	maxItems      int
+}
+
+// NewService builds an instance of feeds.Service
+func NewService(persister Persister, postCreator PostCreator, channelHelper ChannelHelper, fetcher *utils.Fetcher,
+	config ServiceConfig) *Service {
+	maxItems := config.MaxItemsPerPoll
+	if maxItems <= 0 {
+		maxItems = defaultMaxItemsPerPoll
+	}
+
+	return &Service{
+		persister:     persister,
+		postCreator:   postCreator,
+		channelHelper: channelHelper,
+		fetcher:       fetcher,
+		maxItems:      maxItems,
+	}
+}
+
+// NewServiceFromConfig builds an instance of feeds.Service using the graphql config
+func NewServiceFromConfig(persister Persister, postCreator PostCreator, channelHelper ChannelHelper, fetcher *utils.Fetcher,
+	config *utils.GraphQLConfig) *Service {
+	return NewService(persister, postCreator, channelHelper, fetcher, ServiceConfig{
+		MaxItemsPerPoll: config.FeedMaxItemsPerPoll,
+	})
+}
+
+// RegisterFeed sets the feed url for a newsroom channel, the user must be an admin of the channel
+func (s *Service) RegisterFeed(userID string, channelID string, feedURL string) (*ChannelFeed, error) {
+	if err := s.requireAdmin(userID, channelID); err != nil {
+		return nil, err
+	}
+
+	channel, err := s.channelHelper.GetChannel(channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel.ChannelType != channels.TypeNewsroom {
+		return nil, ErrorChannelNotNewsroom
+	}
+
+	parsed, err := url.Parse(strings.TrimSpace(feedURL))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, ErrorInvalidFeedURL
+	}
+
+	return s.persister.SaveFeed(channelID, parsed.String(), userID)
+}
+
+// RemoveFeed removes the feed registered for a channel, the user must be an admin of the channel
+func (s *Service) RemoveFeed(userID string, channelID string) error {
+	if err := s.requireAdmin(userID, channelID); err != nil {
+		return err
+	}
+
+	return s.persister.DeleteFeed(channelID)
+}
+
+// GetFeed returns the feed registered for a channel
+func (s *Service) GetFeed(channelID string) (*ChannelFeed, error) {
+	return s.persister.GetFeedByChannelID(channelID)
+}
+
+// PollFeeds fetches every registered feed and imports new items
+func (s *Service) PollFeeds() error {
+	feeds, err := s.persister.GetFeeds()
+	if err != nil {
+		return err
+	}
+
+	for _, feed := range feeds {
+		if _, err := s.PollFeed(feed); err != nil {
+			log.Errorf("error polling feed %v for channel %v: %v", feed.URL, feed.ChannelID, err)
+		}
+	}
+
+	return nil
+}
+
+// PollFeed fetches a single feed, creates ExternalLink posts for items that have not been imported yet
+// and records the outcome on the feed. Returns the number of posts created
+func (s *Service) PollFeed(feed *ChannelFeed) (int, error) {
+	items, err := s.fetchFeed(feed.URL)
+	if err != nil {
+		if updateErr := s.persister.UpdateFetchStatus(feed.ID, FetchStatusError, err.Error(), 0); updateErr != nil {
+			log.Errorf("error updating feed status: %v", updateErr)
+		}
+		return 0, err
+	}
+
+	created := 0
+	var itemErrors []string
+	// feeds list the newest items first, import the oldest first so posts are created in publish order
+	for i := len(items) - 1; i >= 0 && created < s.maxItems; i-- {
+		item := items[i]
+		if s.isImported(feed.ChannelID, item.Link) {
+			continue
+		}
+
+		externalLink := posts.ExternalLink{
+			PostModel:     posts.PostModel{ChannelID: feed.ChannelID},
+			URL:           item.Link,
+			PublishedTime: item.PublishedTime,
+		}
+		post, err := s.postCreator.CreatePost(feed.CreatedByUserID, externalLink)
+		if err != nil {
+			// the page was already posted, under a different link than the one in the feed
+			if isUniqueViolation(err) {
+				s.saveImported(feed.ChannelID, item.Link, nil)
+				continue
+			}
+			itemErrors = append(itemErrors, fmt.Sprintf("%v: %v", item.Link, err))
+			continue
+		}
+		postID := post.GetID()
+		s.saveImported(feed.ChannelID, item.Link, &postID)
+		created++
+	}
+
+	status := FetchStatusOK
+	if len(itemErrors) > 0 {
+		status = FetchStatusPartial
+		if len(itemErrors) > maxRecordedErrors {
+			itemErrors = append(itemErrors[:maxRecordedErrors], fmt.Sprintf("and %v more", len(itemErrors)-maxRecordedErrors))
+		}
+	}
+	err = s.persister.UpdateFetchStatus(feed.ID, status, strings.Join(itemErrors, "; "), created)
+	if err != nil {
+		return created, err
+	}
+
+	return created, nil
+}
+
+func (s *Service) fetchFeed(feedURL string) ([]*FeedItem, error) {
+	result, err := s.fetcher.Fetch(feedURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.StatusCode < 200 || result.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected status fetching feed: %v", result.StatusCode)
+	}
+
+	return ParseFeed(result.Body)
+}
+
+// isImported returns whether the item link was imported by an earlier poll, or was posted to the channel
+// with a link that is the same as the canonical url of the page
+func (s *Service) isImported(channelID string, link string) bool {
+	imported, err := s.persister.IsItemImported(channelID, link)
+	if err != nil {
+		log.Errorf("error checking feed item %v: %v", link, err)
+	}
+	if imported {
+		return true
+	}
+
+	post, err := s.postCreator.GetPostByReferenceSafe(posts.TypeExternalLink + "+" + link)
+	return err == nil && post != nil
+}
+
+func (s *Service) saveImported(channelID string, link string, postID *string) {
+	if err := s.persister.SaveImportedItem(channelID, link, postID); err != nil {
+		log.Errorf("error saving feed item %v: %v", link, err)
+	}
+}
+
+func (s *Service) requireAdmin(userID string, channelID string) error {
+	isAdmin, err := s.channelHelper.IsChannelAdmin(userID, channelID)
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return ErrorUnauthorized
+	}
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == uniqueViolationCode
+}
@@ -0,0 +1,386 @@
+package feeds_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joincivil/civil-api-server/pkg/channels"
+	"github.com/joincivil/civil-api-server/pkg/feeds"
+	"github.com/joincivil/civil-api-server/pkg/posts"
+	"github.com/joincivil/civil-api-server/pkg/utils"
+	"github.com/lib/pq"
+)
+
+const (
+	testChannelID = "0a7c4bd1-5dd2-4b0d-9b34-a7b1fdd3d2f1"
+	testAdminID   = "8ef93e08-4ab8-4c39-a1f4-8f7a2a0c9d11"
+
+	rssFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Test Newsroom</title>
+    <item>
+      <title>Newest</title>
+      <link>https://example.com/newest?utm_source=rss</link>
+      <pubDate>Tue, 03 Sep 2019 10:00:00 +0000</pubDate>
+    </item>
+    <item>
+      <title>Existing</title>
+      <link>https://example.com/existing</link>
+      <pubDate>Mon, 02 Sep 2019 10:00:00 +0000</pubDate>
+    </item>
+    <item>
+      <title>Oldest</title>
+      <guid>https://example.com/oldest</guid>
+      <pubDate>Sun, 01 Sep 2019 10:00:00 GMT</pubDate>
+    </item>
+  </channel>
+</rss>`
+
+	atomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Test Newsroom</title>
+  <entry>
+    <title>Second</title>
+    <link rel="alternate" href="https://example.com/second"/>
+    <id>urn:uuid:2</id>
+    <updated>2019-09-02T10:00:00Z</updated>
+  </entry>
+  <entry>
+    <title>First</title>
+    <link rel="self" href="https://example.com/first.atom"/>
+    <link href="https://example.com/first"/>
+    <id>urn:uuid:1</id>
+    <published>2019-09-01T10:00:00Z</published>
+  </entry>
+</feed>`
+)
+
+type mockPersister struct {
+	feeds map[string]*feeds.ChannelFeed
+	items map[string]*string
+}
+
+func (m *mockPersister) SaveFeed(channelID string, url string, userID string) (*feeds.ChannelFeed, error) {
+	feed := &feeds.ChannelFeed{ID: channelID, ChannelID: channelID, URL: url, CreatedByUserID: userID, LastStatus: feeds.FetchStatusPending}
+	m.feeds[channelID] = feed
+	return feed, nil
+}
+
+func (m *mockPersister) GetFeedByChannelID(channelID string) (*feeds.ChannelFeed, error) {
+	feed, ok := m.feeds[channelID]
+	if !ok {
+		return nil, feeds.ErrorNotFound
+	}
+	return feed, nil
+}
+
+func (m *mockPersister) GetFeeds() ([]*feeds.ChannelFeed, error) {
+	var all []*feeds.ChannelFeed
+	for _, feed := range m.feeds {
+		all = append(all, feed)
+	}
+	return all, nil
+}
+
+func (m *mockPersister) DeleteFeed(channelID string) error {
+	delete(m.feeds, channelID)
+	return nil
+}
+
+func (m *mockPersister) UpdateFetchStatus(feedID string, status string, fetchError string, postsCreated int) error {
+	for _, feed := range m.feeds {
+		if feed.ID == feedID {
+			now := time.Now()
+			feed.LastFetchedAt = &now
+			feed.LastStatus = status
+			feed.LastError = fetchError
+			feed.PostsCreated += postsCreated
+		}
+	}
+	return nil
+}
+
+func (m *mockPersister) IsItemImported(channelID string, link string) (bool, error) {
+	_, ok := m.items[channelID+link]
+	return ok, nil
+}
+
+func (m *mockPersister) SaveImportedItem(channelID string, link string, postID *string) error {
+	m.items[channelID+link] = postID
+	return nil
+}
+
+// stripScheme removes the scheme and www from a url, as is done for post references
+func stripScheme(url string) string {
+	for _, prefix := range []string{"https://www.", "http://www.", "https://", "http://"} {
+		if strings.HasPrefix(url, prefix) {
+			return strings.TrimPrefix(url, prefix)
+		}
+	}
+	return url
+}
+
+type mockPostCreator struct {
+	created    []posts.ExternalLink
+	references map[string]bool
+	failing    map[string]bool
+	// canonical is the canonical url of the page at a link, if it is not the link itself
+	canonical map[string]string
+	attempts  int
+}
+
+// CreatePost references the post by the canonical url of the page, as posts.Service does
+func (m *mockPostCreator) CreatePost(authorID string, post posts.Post) (posts.Post, error) {
+	m.attempts++
+	link := post.(posts.ExternalLink)
+	if m.failing[link.URL] {
+		return nil, errors.New("could not fetch link")
+	}
+	canonicalURL, ok := m.canonical[link.URL]
+	if !ok {
+		canonicalURL = link.URL
+	}
+	reference := posts.TypeExternalLink + "+" + stripScheme(canonicalURL)
+	if m.references[reference] {
+		return nil, &pq.Error{Code: "23505"}
+	}
+	link.ID = fmt.Sprintf("post-%v", len(m.created))
+	link.AuthorID = authorID
+	link.Reference = &reference
+	m.created = append(m.created, link)
+	m.references[reference] = true
+	return link, nil
+}
+
+func (m *mockPostCreator) GetPostByReferenceSafe(reference string) (posts.Post, error) {
+	if m.references[posts.TypeExternalLink+"+"+stripScheme(strings.TrimPrefix(reference, posts.TypeExternalLink+"+"))] {
+		return posts.ExternalLink{}, nil
+	}
+	return nil, posts.ErrorNotFound
+}
+
+type mockChannelHelper struct{}
+
+func (m *mockChannelHelper) IsChannelAdmin(userID string, channelID string) (bool, error) {
+	return userID == testAdminID, nil
+}
+
+func (m *mockChannelHelper) GetChannel(id string) (*channels.Channel, error) {
+	if id == testChannelID {
+		return &channels.Channel{ID: id, ChannelType: channels.TypeNewsroom}, nil
+	}
+	return &channels.Channel{ID: id, ChannelType: channels.TypeUser}, nil
+}
+
+func newTestService(maxItems int) (*feeds.Service, *mockPersister, *mockPostCreator) {
+	persister := &mockPersister{feeds: map[string]*feeds.ChannelFeed{}, items: map[string]*string{}}
+	creator := &mockPostCreator{references: map[string]bool{}, failing: map[string]bool{}, canonical: map[string]string{}}
+	fetcher := utils.NewFetcher(utils.FetcherConfig{Timeout: 5 * time.Second, AllowPrivateNetworks: true})
+	service := feeds.NewService(persister, creator, &mockChannelHelper{}, fetcher, feeds.ServiceConfig{
+		MaxItemsPerPoll: maxItems,
+	})
+	return service, persister, creator
+}
+
+func newFeedServer(body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rss":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			_, _ = w.Write([]byte(body)) // nolint: errcheck
+		case "/html":
+			_, _ = w.Write([]byte("<html><body>not a feed</body></html>")) // nolint: errcheck
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestRegisterFeed(t *testing.T) {
+	service, _, _ := newTestService(10)
+
+	_, err := service.RegisterFeed("someone-else", testChannelID, "https://example.com/feed")
+	if err != feeds.ErrorUnauthorized {
+		t.Fatalf("expected unauthorized error for non admin, got %v", err)
+	}
+	_, err = service.RegisterFeed(testAdminID, "user-channel", "https://example.com/feed")
+	if err != feeds.ErrorChannelNotNewsroom {
+		t.Fatalf("expected error for non newsroom channel, got %v", err)
+	}
+	_, err = service.RegisterFeed(testAdminID, testChannelID, "ftp://example.com/feed")
+	if err != feeds.ErrorInvalidFeedURL {
+		t.Fatalf("expected invalid feed url error, got %v", err)
+	}
+
+	feed, err := service.RegisterFeed(testAdminID, testChannelID, "https://example.com/feed")
+	if err != nil {
+		t.Fatalf("was not expecting error registering feed: %v", err)
+	}
+	if feed.LastStatus != feeds.FetchStatusPending {
+		t.Fatalf("expected new feed to be pending, got %v", feed.LastStatus)
+	}
+
+	err = service.RemoveFeed(testAdminID, testChannelID)
+	if err != nil {
+		t.Fatalf("was not expecting error removing feed: %v", err)
+	}
+	_, err = service.GetFeed(testChannelID)
+	if err != feeds.ErrorNotFound {
+		t.Fatalf("expected feed to be removed, got %v", err)
+	}
+}
+
+func TestPollFeedRSS(t *testing.T) {
+	server := newFeedServer(rssFeed)
+	defer server.Close()
+
+	service, persister, creator := newTestService(10)
+	creator.references[posts.TypeExternalLink+"+example.com/existing"] = true
+	creator.canonical["https://example.com/newest?utm_source=rss"] = "https://www.example.com/newest"
+
+	feed, err := service.RegisterFeed(testAdminID, testChannelID, server.URL+"/rss")
+	if err != nil {
+		t.Fatalf("was not expecting error registering feed: %v", err)
+	}
+
+	created, err := service.PollFeed(feed)
+	if err != nil {
+		t.Fatalf("was not expecting error polling feed: %v", err)
+	}
+	if created != 2 || len(creator.created) != 2 {
+		t.Fatalf("expected 2 posts to be created, got %v", created)
+	}
+	if creator.created[0].URL != "https://example.com/oldest" || creator.created[1].URL != "https://example.com/newest?utm_source=rss" {
+		t.Fatalf("expected posts to be created oldest first, got %v then %v", creator.created[0].URL, creator.created[1].URL)
+	}
+	if creator.created[0].ChannelID != testChannelID || creator.created[0].AuthorID != testAdminID {
+		t.Fatalf("expected post to be created on the channel by the admin that registered the feed")
+	}
+	if creator.created[0].PublishedTime == nil || creator.created[0].PublishedTime.Day() != 1 {
+		t.Fatalf("expected published time to be taken from the feed")
+	}
+	if feed.LastStatus != feeds.FetchStatusOK || feed.PostsCreated != 2 || feed.LastFetchedAt == nil {
+		t.Fatalf("expected fetch status to be recorded, got %v with %v posts", feed.LastStatus, feed.PostsCreated)
+	}
+
+	if *creator.created[1].Reference != posts.TypeExternalLink+"+example.com/newest" {
+		t.Fatalf("expected post to be referenced by the canonical url, got %v", *creator.created[1].Reference)
+	}
+	postID := persister.items[testChannelID+"https://example.com/newest?utm_source=rss"]
+	if postID == nil || *postID != creator.created[1].ID {
+		t.Fatalf("expected the feed item link to be recorded with its post")
+	}
+
+	attempts := creator.attempts
+	created, err = service.PollFeed(feed)
+	if err != nil {
+		t.Fatalf("was not expecting error polling feed again: %v", err)
+	}
+	if created != 0 || creator.attempts != attempts {
+		t.Fatalf("expected already imported items to be skipped without creating posts, got %v new posts", created)
+	}
+}
+
+func TestPollFeedAlreadyPosted(t *testing.T) {
+	server := newFeedServer(rssFeed)
+	defer server.Close()
+
+	service, persister, creator := newTestService(10)
+	creator.canonical["https://example.com/oldest"] = "https://example.com/existing"
+
+	feed, err := service.RegisterFeed(testAdminID, testChannelID, server.URL+"/rss")
+	if err != nil {
+		t.Fatalf("was not expecting error registering feed: %v", err)
+	}
+
+	// the oldest item is the same page as the existing item, so creating it conflicts once existing is posted
+	creator.references[posts.TypeExternalLink+"+example.com/existing"] = true
+	created, err := service.PollFeed(feed)
+	if err != nil {
+		t.Fatalf("was not expecting error polling feed: %v", err)
+	}
+	if created != 1 || feed.LastStatus != feeds.FetchStatusOK {
+		t.Fatalf("expected conflicting item to be skipped without error, got %v posts: %v", created, feed.LastError)
+	}
+	if postID, ok := persister.items[testChannelID+"https://example.com/oldest"]; !ok || postID != nil {
+		t.Fatalf("expected conflicting item link to be recorded without a post")
+	}
+
+	attempts := creator.attempts
+	_, err = service.PollFeed(feed)
+	if err != nil {
+		t.Fatalf("was not expecting error polling feed again: %v", err)
+	}
+	if creator.attempts != attempts {
+		t.Fatalf("expected conflicting item to be skipped on the next poll, got %v attempts", creator.attempts-attempts)
+	}
+}
+
+func TestPollFeedPartialAndCapped(t *testing.T) {
+	server := newFeedServer(rssFeed)
+	defer server.Close()
+
+	service, _, creator := newTestService(1)
+	creator.failing["https://example.com/oldest"] = true
+
+	feed, err := service.RegisterFeed(testAdminID, testChannelID, server.URL+"/rss")
+	if err != nil {
+		t.Fatalf("was not expecting error registering feed: %v", err)
+	}
+
+	created, err := service.PollFeed(feed)
+	if err != nil {
+		t.Fatalf("was not expecting error polling feed: %v", err)
+	}
+	if created != 1 || creator.created[0].URL != "https://example.com/existing" {
+		t.Fatalf("expected a single post to be created after the failing item")
+	}
+	if feed.LastStatus != feeds.FetchStatusPartial || !strings.Contains(feed.LastError, "https://example.com/oldest") {
+		t.Fatalf("expected partial status with item error, got %v: %v", feed.LastStatus, feed.LastError)
+	}
+}
+
+func TestPollFeedErrors(t *testing.T) {
+	server := newFeedServer(rssFeed)
+	defer server.Close()
+
+	service, _, _ := newTestService(10)
+	for _, path := range []string{"/missing", "/html"} {
+		feed, err := service.RegisterFeed(testAdminID, testChannelID, server.URL+path)
+		if err != nil {
+			t.Fatalf("was not expecting error registering feed: %v", err)
+		}
+		_, err = service.PollFeed(feed)
+		if err == nil {
+			t.Fatalf("expected error polling %v", path)
+		}
+		if feed.LastStatus != feeds.FetchStatusError || feed.LastError == "" {
+			t.Fatalf("expected error status to be recorded for %v", path)
+		}
+	}
+}
+
+func TestParseFeedAtom(t *testing.T) {
+	items, err := feeds.ParseFeed([]byte(atomFeed))
+	if err != nil {
+		t.Fatalf("was not expecting error parsing atom feed: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %v", len(items))
+	}
+	if items[1].Link != "https://example.com/first" {
+		t.Fatalf("expected alternate link to be used, got %v", items[1].Link)
+	}
+	if items[0].PublishedTime == nil || items[0].PublishedTime.Day() != 2 {
+		t.Fatalf("expected updated time to be used when published is missing")
+	}
+	if items[1].PublishedTime == nil || items[1].PublishedTime.Day() != 1 {
+		t.Fatalf("expected published time to be parsed")
+	}
+}
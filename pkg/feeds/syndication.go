@@ -0,0 +1,289 @@
+package feeds
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dyatlov/go-opengraph/opengraph"
+
+	"github.com/joincivil/civil-api-server/pkg/posts"
+)
+
+// SYNDICATION FORMATS
+const (
+	// FormatRSS renders a storyfeed as RSS 2.0
+	FormatRSS = "rss"
+	// FormatAtom renders a storyfeed as Atom 1.0
+	FormatAtom = "atom"
+	// FormatJSON renders a storyfeed as JSON Feed 1.0
+	FormatJSON = "json"
+)
+
+const (
+	atomNamespace   = "http://www.w3.org/2005/Atom"
+	jsonFeedVersion = "https://jsonfeed.org/version/1"
+)
+
+// Syndication is a storyfeed prepared for rendering as an RSS, Atom or JSON feed
+type Syndication struct {
+	Title       string
+	Description string
+	HomeURL     string
+	FeedURL     string
+	Items       []*SyndicationItem
+}
+
+// SyndicationItem is a single post within a Syndication
+type SyndicationItem struct {
+	ID        string
+	Title     string
+	URL       string
+	Summary   string
+	ImageURL  string
+	Published time.Time
+	Updated   time.Time
+}
+
+// LastModified returns the most recent update time of the items in the feed
+func (s *Syndication) LastModified() time.Time {
+	var lastModified time.Time
+	for _, item := range s.Items {
+		if item.Updated.After(lastModified) {
+			lastModified = item.Updated
+		}
+	}
+	return lastModified
+}
+
+// SyndicationItemFromPost converts a boost or external link into a SyndicationItem, other post types are not syndicated
+func SyndicationItemFromPost(post posts.Post, siteURL string) (*SyndicationItem, bool) {
+	switch p := post.(type) {
+	case *posts.ExternalLink:
+		return externalLinkItem(p), true
+	case *posts.Boost:
+		return boostItem(p, siteURL), true
+	}
+	return nil, false
+}
+
+func externalLinkItem(link *posts.ExternalLink) *SyndicationItem {
+	item := &SyndicationItem{
+		ID:        link.ID,
+		Title:     link.URL,
+		URL:       link.URL,
+		Published: link.CreatedAt,
+		Updated:   link.UpdatedAt,
+	}
+	if link.PublishedTime != nil {
+		item.Published = *link.PublishedTime
+	}
+
+	var og opengraph.OpenGraph
+	if err := json.Unmarshal(link.OpenGraphData, &og); err == nil {
+		if og.Title != "" {
+			item.Title = og.Title
+		}
+		item.Summary = og.Description
+		if len(og.Images) > 0 && og.Images[0] != nil {
+			item.ImageURL = og.Images[0].URL
+		}
+	}
+
+	return item
+}
+
+func boostItem(boost *posts.Boost, siteURL string) *SyndicationItem {
+	summary := []string{}
+	if boost.What != "" {
+		summary = append(summary, boost.What)
+	}
+	if boost.Why != "" {
+		summary = append(summary, boost.Why)
+	}
+	summary = append(summary, fmt.Sprintf("Goal: %.2f %v by %v", boost.GoalAmount, boost.CurrencyCode, boost.DateEnd.Format("January 2, 2006")))
+
+	return &SyndicationItem{
+		ID:        boost.ID,
+		Title:     boost.Title,
+		URL:       fmt.Sprintf("%v/boosts/%v", strings.TrimRight(siteURL, "/"), boost.ID),
+		Summary:   strings.Join(summary, "\n\n"),
+		Published: boost.CreatedAt,
+		Updated:   boost.UpdatedAt,
+	}
+}
+
+type rssOutput struct {
+	XMLName xml.Name         `xml:"rss"`
+	Version string           `xml:"version,attr"`
+	AtomNS  string           `xml:"xmlns:atom,attr"`
+	Channel rssOutputChannel `xml:"channel"`
+}
+
+type rssOutputChannel struct {
+	Title         string          `xml:"title"`
+	Link          string          `xml:"link"`
+	Description   string          `xml:"description"`
+	AtomLink      atomOutputLink  `xml:"atom:link"`
+	LastBuildDate string          `xml:"lastBuildDate,omitempty"`
+	Items         []rssOutputItem `xml:"item"`
+}
+
+type rssOutputItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	Description string        `xml:"description,omitempty"`
+	GUID        rssOutputGUID `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+}
+
+type rssOutputGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type atomOutput struct {
+	XMLName xml.Name          `xml:"feed"`
+	XMLNS   string            `xml:"xmlns,attr"`
+	ID      string            `xml:"id"`
+	Title   string            `xml:"title"`
+	Updated string            `xml:"updated"`
+	Links   []atomOutputLink  `xml:"link"`
+	Entries []atomOutputEntry `xml:"entry"`
+}
+
+type atomOutputLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomOutputEntry struct {
+	ID        string         `xml:"id"`
+	Title     string         `xml:"title"`
+	Link      atomOutputLink `xml:"link"`
+	Published string         `xml:"published"`
+	Updated   string         `xml:"updated"`
+	Summary   string         `xml:"summary,omitempty"`
+}
+
+type jsonFeedOutput struct {
+	Version     string               `json:"version"`
+	Title       string               `json:"title"`
+	HomePageURL string               `json:"home_page_url,omitempty"`
+	FeedURL     string               `json:"feed_url,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Items       []jsonFeedOutputItem `json:"items"`
+}
+
+type jsonFeedOutputItem struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	Title         string `json:"title"`
+	ContentText   string `json:"content_text"`
+	Image         string `json:"image,omitempty"`
+	DatePublished string `json:"date_published"`
+	DateModified  string `json:"date_modified"`
+}
+
+// Render encodes the feed in the given format and returns it with its content type
+func (s *Syndication) Render(format string) ([]byte, string, error) {
+	switch format {
+	case FormatRSS:
+		body, err := s.renderRSS()
+		return body, "application/rss+xml; charset=utf-8", err
+	case FormatAtom:
+		body, err := s.renderAtom()
+		return body, "application/atom+xml; charset=utf-8", err
+	case FormatJSON:
+		body, err := s.renderJSON()
+		return body, "application/feed+json; charset=utf-8", err
+	}
+	return nil, "", ErrorUnsupportedFeedFormat
+}
+
+func (s *Syndication) renderRSS() ([]byte, error) {
+	output := rssOutput{
+		Version: "2.0",
+		AtomNS:  atomNamespace,
+		Channel: rssOutputChannel{
+			Title:       s.Title,
+			Link:        s.HomeURL,
+			Description: s.Description,
+			AtomLink:    atomOutputLink{Href: s.FeedURL, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	if lastModified := s.LastModified(); !lastModified.IsZero() {
+		output.Channel.LastBuildDate = lastModified.UTC().Format(time.RFC1123Z)
+	}
+	for _, item := range s.Items {
+		output.Channel.Items = append(output.Channel.Items, rssOutputItem{
+			Title:       item.Title,
+			Link:        item.URL,
+			Description: item.Summary,
+			GUID:        rssOutputGUID{Value: item.ID},
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+		})
+	}
+
+	return marshalXML(output)
+}
+
+func (s *Syndication) renderAtom() ([]byte, error) {
+	output := atomOutput{
+		XMLNS:   atomNamespace,
+		ID:      s.FeedURL,
+		Title:   s.Title,
+		Updated: s.LastModified().UTC().Format(time.RFC3339),
+		Links: []atomOutputLink{
+			{Href: s.FeedURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: s.HomeURL, Rel: "alternate", Type: "text/html"},
+		},
+	}
+	for _, item := range s.Items {
+		output.Entries = append(output.Entries, atomOutputEntry{
+			ID:        "urn:uuid:" + item.ID,
+			Title:     item.Title,
+			Link:      atomOutputLink{Href: item.URL, Rel: "alternate"},
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   item.Updated.UTC().Format(time.RFC3339),
+			Summary:   item.Summary,
+		})
+	}
+
+	return marshalXML(output)
+}
+
+func (s *Syndication) renderJSON() ([]byte, error) {
+	output := jsonFeedOutput{
+		Version:     jsonFeedVersion,
+		Title:       s.Title,
+		HomePageURL: s.HomeURL,
+		FeedURL:     s.FeedURL,
+		Description: s.Description,
+		Items:       []jsonFeedOutputItem{},
+	}
+	for _, item := range s.Items {
+		output.Items = append(output.Items, jsonFeedOutputItem{
+			ID:            item.ID,
+			URL:           item.URL,
+			Title:         item.Title,
+			ContentText:   item.Summary,
+			Image:         item.ImageURL,
+			DatePublished: item.Published.UTC().Format(time.RFC3339),
+			DateModified:  item.Updated.UTC().Format(time.RFC3339),
+		})
+	}
+
+	return json.Marshal(output)
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
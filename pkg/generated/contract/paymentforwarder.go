@@ -0,0 +1,365 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contract
+
+import (
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = abi.U256
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// PaymentForwarderContractABI is the input ABI used to generate the binding from.
+const PaymentForwarderContractABI = "[{\"constant\":false,\"inputs\":[{\"name\":\"channel\",\"type\":\"address\"},{\"name\":\"ref\",\"type\":\"bytes32\"}],\"name\":\"pay\",\"outputs\":[],\"payable\":true,\"stateMutability\":\"payable\",\"type\":\"function\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"name\":\"channel\",\"type\":\"address\"},{\"indexed\":true,\"name\":\"payer\",\"type\":\"address\"},{\"indexed\":false,\"name\":\"amount\",\"type\":\"uint256\"},{\"indexed\":false,\"name\":\"ref\",\"type\":\"bytes32\"}],\"name\":\"Payment\",\"type\":\"event\"}]"
+
+// PaymentForwarderContractBin is the compiled bytecode used for deploying new contracts.
+var PaymentForwarderContractBin = "0x61008f80600c6000396000f360003560e01c6346f8f30414630000001657600080fd5b60008080803460043573ffffffffffffffffffffffffffffffffffffffff165af115630000008a57346000526024356020523360043573ffffffffffffffffffffffffffffffffffffffff167f77e1d0d8689317ec14af806725490695c9224469afdfa7503596456f2e5c0e8c60406000a3005b600080fd"
+
+// DeployPaymentForwarderContract deploys a new Ethereum contract, binding an instance of PaymentForwarderContract to it.
+func DeployPaymentForwarderContract(auth *bind.TransactOpts, backend bind.ContractBackend) (common.Address, *types.Transaction, *PaymentForwarderContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(PaymentForwarderContractABI))
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, parsed, common.FromHex(PaymentForwarderContractBin), backend)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &PaymentForwarderContract{PaymentForwarderContractCaller: PaymentForwarderContractCaller{contract: contract}, PaymentForwarderContractTransactor: PaymentForwarderContractTransactor{contract: contract}, PaymentForwarderContractFilterer: PaymentForwarderContractFilterer{contract: contract}}, nil
+}
+
+// PaymentForwarderContract is an auto generated Go binding around an Ethereum contract.
+type PaymentForwarderContract struct {
+	PaymentForwarderContractCaller     // Read-only binding to the contract
+	PaymentForwarderContractTransactor // Write-only binding to the contract
+	PaymentForwarderContractFilterer   // Log filterer for contract events
+}
+
+// PaymentForwarderContractCaller is an auto generated read-only Go binding around an Ethereum contract.
+type PaymentForwarderContractCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// PaymentForwarderContractTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type PaymentForwarderContractTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// PaymentForwarderContractFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type PaymentForwarderContractFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// PaymentForwarderContractSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type PaymentForwarderContractSession struct {
+	Contract     *PaymentForwarderContract // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts             // Call options to use throughout this session
+	TransactOpts bind.TransactOpts         // Transaction auth options to use throughout this session
+}
+
+// PaymentForwarderContractCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type PaymentForwarderContractCallerSession struct {
+	Contract *PaymentForwarderContractCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts                   // Call options to use throughout this session
+}
+
+// PaymentForwarderContractTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type PaymentForwarderContractTransactorSession struct {
+	Contract     *PaymentForwarderContractTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts                   // Transaction auth options to use throughout this session
+}
+
+// PaymentForwarderContractRaw is an auto generated low-level Go binding around an Ethereum contract.
+type PaymentForwarderContractRaw struct {
+	Contract *PaymentForwarderContract // Generic contract binding to access the raw methods on
+}
+
+// PaymentForwarderContractCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type PaymentForwarderContractCallerRaw struct {
+	Contract *PaymentForwarderContractCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// PaymentForwarderContractTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type PaymentForwarderContractTransactorRaw struct {
+	Contract *PaymentForwarderContractTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewPaymentForwarderContract creates a new instance of PaymentForwarderContract, bound to a specific deployed contract.
+func NewPaymentForwarderContract(address common.Address, backend bind.ContractBackend) (*PaymentForwarderContract, error) {
+	contract, err := bindPaymentForwarderContract(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &PaymentForwarderContract{PaymentForwarderContractCaller: PaymentForwarderContractCaller{contract: contract}, PaymentForwarderContractTransactor: PaymentForwarderContractTransactor{contract: contract}, PaymentForwarderContractFilterer: PaymentForwarderContractFilterer{contract: contract}}, nil
+}
+
+// NewPaymentForwarderContractCaller creates a new read-only instance of PaymentForwarderContract, bound to a specific deployed contract.
+func NewPaymentForwarderContractCaller(address common.Address, caller bind.ContractCaller) (*PaymentForwarderContractCaller, error) {
+	contract, err := bindPaymentForwarderContract(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &PaymentForwarderContractCaller{contract: contract}, nil
+}
+
+// NewPaymentForwarderContractTransactor creates a new write-only instance of PaymentForwarderContract, bound to a specific deployed contract.
+func NewPaymentForwarderContractTransactor(address common.Address, transactor bind.ContractTransactor) (*PaymentForwarderContractTransactor, error) {
+	contract, err := bindPaymentForwarderContract(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &PaymentForwarderContractTransactor{contract: contract}, nil
+}
+
+// NewPaymentForwarderContractFilterer creates a new log filterer instance of PaymentForwarderContract, bound to a specific deployed contract.
+func NewPaymentForwarderContractFilterer(address common.Address, filterer bind.ContractFilterer) (*PaymentForwarderContractFilterer, error) {
+	contract, err := bindPaymentForwarderContract(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &PaymentForwarderContractFilterer{contract: contract}, nil
+}
+
+// bindPaymentForwarderContract binds a generic wrapper to an already deployed contract.
+func bindPaymentForwarderContract(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(PaymentForwarderContractABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_PaymentForwarderContract *PaymentForwarderContractRaw) Call(opts *bind.CallOpts, result interface{}, method string, params ...interface{}) error {
+	return _PaymentForwarderContract.Contract.PaymentForwarderContractCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_PaymentForwarderContract *PaymentForwarderContractRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _PaymentForwarderContract.Contract.PaymentForwarderContractTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_PaymentForwarderContract *PaymentForwarderContractRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _PaymentForwarderContract.Contract.PaymentForwarderContractTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_PaymentForwarderContract *PaymentForwarderContractCallerRaw) Call(opts *bind.CallOpts, result interface{}, method string, params ...interface{}) error {
+	return _PaymentForwarderContract.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_PaymentForwarderContract *PaymentForwarderContractTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _PaymentForwarderContract.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_PaymentForwarderContract *PaymentForwarderContractTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _PaymentForwarderContract.Contract.contract.Transact(opts, method, params...)
+}
+
+// Pay is a paid mutator transaction binding the contract method 0x46f8f304.
+//
+// Solidity: function pay(address channel, bytes32 ref) returns()
+func (_PaymentForwarderContract *PaymentForwarderContractTransactor) Pay(opts *bind.TransactOpts, channel common.Address, ref [32]byte) (*types.Transaction, error) {
+	return _PaymentForwarderContract.contract.Transact(opts, "pay", channel, ref)
+}
+
+// Pay is a paid mutator transaction binding the contract method 0x46f8f304.
+//
+// Solidity: function pay(address channel, bytes32 ref) returns()
+func (_PaymentForwarderContract *PaymentForwarderContractSession) Pay(channel common.Address, ref [32]byte) (*types.Transaction, error) {
+	return _PaymentForwarderContract.Contract.Pay(&_PaymentForwarderContract.TransactOpts, channel, ref)
+}
+
+// Pay is a paid mutator transaction binding the contract method 0x46f8f304.
+//
+// Solidity: function pay(address channel, bytes32 ref) returns()
+func (_PaymentForwarderContract *PaymentForwarderContractTransactorSession) Pay(channel common.Address, ref [32]byte) (*types.Transaction, error) {
+	return _PaymentForwarderContract.Contract.Pay(&_PaymentForwarderContract.TransactOpts, channel, ref)
+}
+
+// PaymentForwarderContractPaymentIterator is returned from FilterPayment and is used to iterate over the raw logs and unpacked data for Payment events raised by the PaymentForwarderContract contract.
+type PaymentForwarderContractPaymentIterator struct {
+	Event *PaymentForwarderContractPayment // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *PaymentForwarderContractPaymentIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(PaymentForwarderContractPayment)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(PaymentForwarderContractPayment)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *PaymentForwarderContractPaymentIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *PaymentForwarderContractPaymentIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// PaymentForwarderContractPayment represents a Payment event raised by the PaymentForwarderContract contract.
+type PaymentForwarderContractPayment struct {
+	Channel common.Address
+	Payer   common.Address
+	Amount  *big.Int
+	Ref     [32]byte
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterPayment is a free log retrieval operation binding the contract event 0x77e1d0d8689317ec14af806725490695c9224469afdfa7503596456f2e5c0e8c.
+//
+// Solidity: event Payment(address indexed channel, address indexed payer, uint256 amount, bytes32 ref)
+func (_PaymentForwarderContract *PaymentForwarderContractFilterer) FilterPayment(opts *bind.FilterOpts, channel []common.Address, payer []common.Address) (*PaymentForwarderContractPaymentIterator, error) {
+
+	var channelRule []interface{}
+	for _, channelItem := range channel {
+		channelRule = append(channelRule, channelItem)
+	}
+	var payerRule []interface{}
+	for _, payerItem := range payer {
+		payerRule = append(payerRule, payerItem)
+	}
+
+	logs, sub, err := _PaymentForwarderContract.contract.FilterLogs(opts, "Payment", channelRule, payerRule)
+	if err != nil {
+		return nil, err
+	}
+	return &PaymentForwarderContractPaymentIterator{contract: _PaymentForwarderContract.contract, event: "Payment", logs: logs, sub: sub}, nil
+}
+
+// WatchPayment is a free log subscription operation binding the contract event 0x77e1d0d8689317ec14af806725490695c9224469afdfa7503596456f2e5c0e8c.
+//
+// Solidity: event Payment(address indexed channel, address indexed payer, uint256 amount, bytes32 ref)
+func (_PaymentForwarderContract *PaymentForwarderContractFilterer) WatchPayment(opts *bind.WatchOpts, sink chan<- *PaymentForwarderContractPayment, channel []common.Address, payer []common.Address) (event.Subscription, error) {
+
+	var channelRule []interface{}
+	for _, channelItem := range channel {
+		channelRule = append(channelRule, channelItem)
+	}
+	var payerRule []interface{}
+	for _, payerItem := range payer {
+		payerRule = append(payerRule, payerItem)
+	}
+
+	logs, sub, err := _PaymentForwarderContract.contract.WatchLogs(opts, "Payment", channelRule, payerRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(PaymentForwarderContractPayment)
+				if err := _PaymentForwarderContract.contract.UnpackLog(event, "Payment", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParsePayment is a log parse operation binding the contract event 0x77e1d0d8689317ec14af806725490695c9224469afdfa7503596456f2e5c0e8c.
+//
+// Solidity: event Payment(address indexed channel, address indexed payer, uint256 amount, bytes32 ref)
+func (_PaymentForwarderContract *PaymentForwarderContractFilterer) ParsePayment(log types.Log) (*PaymentForwarderContractPayment, error) {
+	event := new(PaymentForwarderContractPayment)
+	if err := _PaymentForwarderContract.contract.UnpackLog(event, "Payment", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/joincivil/civil-api-server/pkg/posts"
+	"github.com/joincivil/civil-api-server/pkg/reports"
 	"github.com/joincivil/civil-events-processor/pkg/model"
 )
 
@@ -22,6 +23,11 @@ type BlockData struct {
 	Index       int    `json:"index"`
 }
 
+type ChannelsSetFeedURLInput struct {
+	ChannelID string `json:"channelID"`
+	FeedURL   string `json:"feedURL"`
+}
+
 type DateRange struct {
 	Gt *int `json:"gt"`
 	Lt *int `json:"lt"`
@@ -57,6 +63,16 @@ type Metadata struct {
 	Value string `json:"value"`
 }
 
+type ModerationActionEdge struct {
+	Cursor string                    `json:"cursor"`
+	Action *reports.ModerationAction `json:"action"`
+}
+
+type ModerationActionResultCursor struct {
+	Edges    []*ModerationActionEdge `json:"edges"`
+	PageInfo *PageInfo               `json:"pageInfo"`
+}
+
 type NrsignupStepsInput struct {
 	Step         *int `json:"step"`
 	FurthestStep *int `json:"furthestStep"`
@@ -142,3 +158,18 @@ type PostResultCursor struct {
 	Edges    []*PostEdge `json:"edges"`
 	PageInfo *PageInfo   `json:"pageInfo"`
 }
+
+type ReportEdge struct {
+	Cursor string          `json:"cursor"`
+	Report *reports.Report `json:"report"`
+}
+
+type ReportResultCursor struct {
+	Edges    []*ReportEdge `json:"edges"`
+	PageInfo *PageInfo     `json:"pageInfo"`
+}
+
+type StoryfeedAlgorithm struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
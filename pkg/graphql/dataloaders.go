@@ -7,7 +7,9 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/joincivil/civil-api-server/pkg/auth"
 	"github.com/joincivil/civil-api-server/pkg/discourse"
+	"github.com/joincivil/civil-api-server/pkg/posts"
 
 	"github.com/ethereum/go-ethereum/common"
 
@@ -27,6 +29,8 @@ type loaders struct {
 	challengeAddressLoader    *ChallengeSliceByAddressLoader
 	appealLoader              *AppealLoader
 	discourseListingMapLoader *ListingMapLoader
+	postReactionsLoader       *PostReactionsLoader
+	postTagsLoader            *PostTagsLoader
 }
 
 // DataloaderMiddleware defines the listingLoader
@@ -101,6 +105,30 @@ func DataloaderMiddleware(g *Resolver, next http.Handler) http.Handler {
 			},
 		}
 
+		// reaction counts include whether the user making the request reacted
+		var viewerUserID string
+		if token := auth.ForContext(r.Context()); token != nil {
+			viewerUserID = token.Sub
+		}
+		ldrs.postReactionsLoader = &PostReactionsLoader{
+			maxBatch: 100,
+			wait:     100 * time.Millisecond,
+			fetch: func(keys []string) ([][]*posts.ReactionCount, []error) {
+				counts, err := g.postService.GetReactionCounts(keys, viewerUserID)
+				errors := []error{err}
+				return counts, errors
+			},
+		}
+		ldrs.postTagsLoader = &PostTagsLoader{
+			maxBatch: 100,
+			wait:     100 * time.Millisecond,
+			fetch: func(keys []string) ([][]*posts.Tag, []error) {
+				tags, err := g.postService.GetTagsForPosts(keys)
+				errors := []error{err}
+				return tags, errors
+			},
+		}
+
 		ctx := context.WithValue(r.Context(), ctxKey, ldrs) // nolint: golint
 		r = r.WithContext(ctx)
 		next.ServeHTTP(w, r)
@@ -15,11 +15,14 @@ import (
 	"github.com/joincivil/civil-api-server/pkg/auth"
 	"github.com/joincivil/civil-api-server/pkg/channels"
 	"github.com/joincivil/civil-api-server/pkg/discourse"
+	"github.com/joincivil/civil-api-server/pkg/feeds"
 	"github.com/joincivil/civil-api-server/pkg/generated/graphql"
+	"github.com/joincivil/civil-api-server/pkg/images"
 	"github.com/joincivil/civil-api-server/pkg/jsonstore"
 	"github.com/joincivil/civil-api-server/pkg/nrsignup"
 	"github.com/joincivil/civil-api-server/pkg/payments"
 	"github.com/joincivil/civil-api-server/pkg/posts"
+	"github.com/joincivil/civil-api-server/pkg/reports"
 	"github.com/joincivil/civil-api-server/pkg/storefront"
 	"github.com/joincivil/civil-api-server/pkg/users"
 
@@ -67,6 +70,11 @@ type ResolverConfig struct {
 	NewsroomTools                *newsrooms.Tools
 	PaymentService               *payments.Service
 	PostService                  *posts.Service
+	ExternalLinkRefresher        *posts.ExternalLinkRefresher
+	BoostLifecycleService        *posts.BoostLifecycleService
+	FeedService                  *feeds.Service
+	ReportService                *reports.Service
+	ImagePipeline                *images.Pipeline
 	StorefrontService            *storefront.Service
 	DiscourseService             *discourse.Service
 	EmailListMembers             cemail.ListMemberManager
@@ -97,6 +105,11 @@ func NewResolver(config ResolverConfig) *Resolver {
 		nrsignupService:              config.NrsignupService,
 		paymentService:               config.PaymentService,
 		postService:                  config.PostService,
+		externalLinkRefresher:        config.ExternalLinkRefresher,
+		boostLifecycleService:        config.BoostLifecycleService,
+		feedService:                  config.FeedService,
+		reportService:                config.ReportService,
+		imagePipeline:                config.ImagePipeline,
 		storefrontService:            config.StorefrontService,
 		discourseService:             config.DiscourseService,
 		emailListMembers:             config.EmailListMembers,
@@ -127,6 +140,11 @@ type Resolver struct {
 	newsroomTools                *newsrooms.Tools
 	paymentService               *payments.Service
 	postService                  *posts.Service
+	externalLinkRefresher        *posts.ExternalLinkRefresher
+	boostLifecycleService        *posts.BoostLifecycleService
+	feedService                  *feeds.Service
+	reportService                *reports.Service
+	imagePipeline                *images.Pipeline
 	storefrontService            *storefront.Service
 	discourseService             *discourse.Service
 	emailListMembers             cemail.ListMemberManager
@@ -161,6 +161,34 @@ func (r *mutationResolver) ChannelsEnableApplePay(ctx context.Context, channelID
 	return r.channelService.EnableStripeApplePay(channelID)
 }
 
+func (r *mutationResolver) ChannelsFollow(ctx context.Context, channelID string) (*channels.Channel, error) {
+	token := auth.ForContext(ctx)
+	if token == nil {
+		return nil, ErrAccessDenied
+	}
+
+	_, err := r.channelService.FollowChannel(token.Sub, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.channelService.GetChannel(channelID)
+}
+
+func (r *mutationResolver) ChannelsUnfollow(ctx context.Context, channelID string) (*channels.Channel, error) {
+	token := auth.ForContext(ctx)
+	if token == nil {
+		return nil, ErrAccessDenied
+	}
+
+	err := r.channelService.UnfollowChannel(token.Sub, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.channelService.GetChannel(channelID)
+}
+
 // Channel is the resolver for the Channel type
 func (r *Resolver) Channel() graphql.ChannelResolver {
 	return &channelResolver{Resolver: r}
@@ -218,6 +246,37 @@ func (r *channelResolver) CurrentUserIsAdmin(ctx context.Context, channel *chann
 	return r.channelService.IsChannelAdmin(token.Sub, channel.ID)
 }
 
+// AvatarURL returns the URL of the channel's avatar
+func (r *channelResolver) AvatarURL(ctx context.Context, channel *channels.Channel) (*string, error) {
+	return r.channelService.AvatarURL(channel, channels.AvatarVariantFull), nil
+}
+
+// Tiny100AvatarURL returns the URL of the channel's avatar scaled down to 100x100
+func (r *channelResolver) Tiny100AvatarURL(ctx context.Context, channel *channels.Channel) (*string, error) {
+	return r.channelService.AvatarURL(channel, channels.AvatarVariantTiny100), nil
+}
+
+// Tiny72AvatarURL returns the URL of the channel's avatar scaled down to 72x72
+func (r *channelResolver) Tiny72AvatarURL(ctx context.Context, channel *channels.Channel) (*string, error) {
+	return r.channelService.AvatarURL(channel, channels.AvatarVariantTiny72), nil
+}
+
+// AvatarDataURL is deprecated, it returns the same URL as AvatarURL, which is a data url for channels whose avatar
+// hasn't been migrated to blob storage
+func (r *channelResolver) AvatarDataURL(ctx context.Context, channel *channels.Channel) (*string, error) {
+	return r.AvatarURL(ctx, channel)
+}
+
+// Tiny100AvatarDataURL is deprecated, it returns the same URL as Tiny100AvatarURL
+func (r *channelResolver) Tiny100AvatarDataURL(ctx context.Context, channel *channels.Channel) (*string, error) {
+	return r.Tiny100AvatarURL(ctx, channel)
+}
+
+// Tiny72AvatarDataURL is deprecated, it returns the same URL as Tiny72AvatarURL
+func (r *channelResolver) Tiny72AvatarDataURL(ctx context.Context, channel *channels.Channel) (*string, error) {
+	return r.Tiny72AvatarURL(ctx, channel)
+}
+
 func (r *channelResolver) EmailAddressRestricted(ctx context.Context, channel *channels.Channel) (*string, error) {
 	token := auth.ForContext(ctx)
 	if token == nil {
@@ -286,3 +345,16 @@ func (r *channelResolver) StripeCustomerInfo(ctx context.Context, channel *chann
 
 	return &stripeCustomerInfo, nil
 }
+
+func (r *channelResolver) FollowerCount(ctx context.Context, channel *channels.Channel) (int, error) {
+	return r.channelService.GetChannelFollowerCount(channel.ID)
+}
+
+func (r *channelResolver) CurrentUserIsFollowing(ctx context.Context, channel *channels.Channel) (bool, error) {
+	token := auth.ForContext(ctx)
+	if token == nil {
+		return false, nil
+	}
+
+	return r.channelService.IsFollowingChannel(token.Sub, channel.ID)
+}
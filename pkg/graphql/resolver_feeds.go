@@ -0,0 +1,54 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/joincivil/civil-api-server/pkg/auth"
+	"github.com/joincivil/civil-api-server/pkg/channels"
+	"github.com/joincivil/civil-api-server/pkg/feeds"
+	"github.com/joincivil/civil-api-server/pkg/generated/graphql"
+)
+
+// mutations
+
+func (r *mutationResolver) ChannelsSetFeedURL(ctx context.Context, input graphql.ChannelsSetFeedURLInput) (*feeds.ChannelFeed, error) {
+	token := auth.ForContext(ctx)
+	if token == nil {
+		return nil, ErrAccessDenied
+	}
+
+	return r.feedService.RegisterFeed(token.Sub, input.ChannelID, input.FeedURL)
+}
+
+func (r *mutationResolver) ChannelsRemoveFeed(ctx context.Context, channelID string) (*channels.Channel, error) {
+	token := auth.ForContext(ctx)
+	if token == nil {
+		return nil, ErrAccessDenied
+	}
+
+	err := r.feedService.RemoveFeed(token.Sub, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.channelService.GetChannel(channelID)
+}
+
+// Feed returns the feed registered to this channel, only visible to channel admins
+func (r *channelResolver) Feed(ctx context.Context, channel *channels.Channel) (*feeds.ChannelFeed, error) {
+	token := auth.ForContext(ctx)
+	if token == nil {
+		return nil, nil
+	}
+	isAdmin, err := r.channelService.IsChannelAdmin(token.Sub, channel.ID)
+	if err != nil || !isAdmin {
+		return nil, nil
+	}
+
+	feed, err := r.feedService.GetFeed(channel.ID)
+	if err == feeds.ErrorNotFound {
+		return nil, nil
+	}
+
+	return feed, err
+}
@@ -170,13 +170,31 @@ func (r *mutationResolver) PaymentsCreateStripePaymentMethod(ctx context.Context
 	return paymentMethod, nil
 }
 
+// nolint: dupl
 func (r *mutationResolver) PaymentsCreateTokenPayment(ctx context.Context, postID string, payment payments.TokenPayment) (*payments.TokenPayment, error) {
-	token := auth.ForContext(ctx)
-	if token == nil {
-		return &payments.TokenPayment{}, ErrAccessDenied
+
+	post, err := r.postService.GetPost(postID)
+	if err != nil {
+		return &payments.TokenPayment{}, errors.New("could not find post")
+	}
+
+	if payment.PayerChannelID != "" {
+		err = r.validateUserIsChannelAdmin(ctx, payment.PayerChannelID)
+		if err != nil {
+			return &payments.TokenPayment{}, err
+		}
+	}
+	if payment.PayerChannelID == "" {
+		payment.ShouldPublicize = false
 	}
 
-	return &payments.TokenPayment{}, ErrNotImplemented
+	channelID := post.GetChannelID()
+	postTitle, err := r.GetPostTitle(post)
+	if err != nil {
+		return &payments.TokenPayment{}, errors.New("error getting post title")
+	}
+	p, err := r.paymentService.CreateTokenPayment(channelID, "posts", post.GetType(), postID, postTitle, payment)
+	return &p, err
 }
 
 func (r *mutationResolver) GetEthPaymentEmailTemplateData(post posts.Post, payment payments.EtherPayment) (email.TemplateData, error) {
@@ -286,6 +304,83 @@ func (r *mutationResolver) PaymentsRemoveSavedPaymentMethod(ctx context.Context,
 	return true, nil
 }
 
+func (r *mutationResolver) PaymentsRefund(ctx context.Context, paymentID string, amount *float64) (*payments.StripePayment, error) {
+	token := auth.ForContext(ctx)
+	if token == nil {
+		return nil, ErrAccessDenied
+	}
+
+	payment, err := r.paymentService.GetPayment(paymentID)
+	if err != nil {
+		return nil, errors.New("could not find payment")
+	}
+	stripePayment, ok := payment.(*payments.StripePayment)
+	if !ok {
+		return nil, payments.ErrPaymentNotRefundable
+	}
+
+	// refunds can be made by admins of the channel that received the payment, or platform admins
+	if !token.IsAdmin {
+		err = r.validateUserIsChannelAdmin(ctx, stripePayment.OwnerChannelID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return r.paymentService.RefundStripePayment(paymentID, amount)
+}
+
+func (r *mutationResolver) PaymentsCreateSubscription(ctx context.Context, channelID string, input payments.StripeSubscription) (*payments.StripeSubscription, error) {
+	err := r.validateUserIsChannelAdmin(ctx, input.PayerChannelID)
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := r.channelService.GetChannel(channelID)
+	if err != nil {
+		return nil, errors.New("could not find channel")
+	}
+	newsroom, err := r.newsroomService.GetNewsroomByAddress(channel.Reference)
+	if err != nil {
+		return nil, errors.New("could not find newsroom")
+	}
+
+	return r.paymentService.CreateStripeSubscription(channelID, newsroom.Name, input)
+}
+
+func (r *mutationResolver) PaymentsPauseSubscription(ctx context.Context, subscriptionID string) (*payments.StripeSubscription, error) {
+	err := r.validateUserIsSubscriber(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	return r.paymentService.PauseStripeSubscription(subscriptionID)
+}
+
+func (r *mutationResolver) PaymentsResumeSubscription(ctx context.Context, subscriptionID string) (*payments.StripeSubscription, error) {
+	err := r.validateUserIsSubscriber(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	return r.paymentService.ResumeStripeSubscription(subscriptionID)
+}
+
+func (r *mutationResolver) PaymentsCancelSubscription(ctx context.Context, subscriptionID string) (*payments.StripeSubscription, error) {
+	err := r.validateUserIsSubscriber(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	return r.paymentService.CancelStripeSubscription(subscriptionID)
+}
+
+// validateUserIsSubscriber checks the user is an admin of the channel paying for the subscription
+func (r *mutationResolver) validateUserIsSubscriber(ctx context.Context, subscriptionID string) error {
+	subscription, err := r.paymentService.GetStripeSubscription(subscriptionID)
+	if err != nil {
+		return errors.New("could not find subscription")
+	}
+	return r.validateUserIsChannelAdmin(ctx, subscription.PayerChannelID)
+}
+
 func (r *queryResolver) GetChannelTotalProceeds(ctx context.Context, channelID string) (*payments.ProceedsQueryResult, error) {
 	err := r.validateUserIsChannelAdmin(ctx, channelID)
 	if err != nil {
@@ -306,6 +401,15 @@ func (r *queryResolver) GetChannelTotalProceedsByBoostType(ctx context.Context,
 	return result, nil
 }
 
+func (r *queryResolver) PaymentsSubscriptions(ctx context.Context, channelID string) ([]*payments.StripeSubscription, error) {
+	err := r.validateUserIsChannelAdmin(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.paymentService.GetStripeSubscriptionsByPayerChannel(channelID)
+}
+
 // PaymentEther is the resolver for the PaymentEther type
 func (r *Resolver) PaymentEther() graphql.PaymentEtherResolver {
 	return &etherPaymentResolver{Resolver: r, paymentResolver: &paymentResolver{r}}
@@ -321,6 +425,11 @@ func (r *Resolver) PaymentToken() graphql.PaymentTokenResolver {
 	return &tokenPaymentResolver{Resolver: r, paymentResolver: &paymentResolver{r}}
 }
 
+// PaymentsSubscription is the resolver for the PaymentsSubscription type
+func (r *Resolver) PaymentsSubscription() graphql.PaymentsSubscriptionResolver {
+	return &paymentsSubscriptionResolver{r}
+}
+
 // TYPE RESOLVERS
 type paymentResolver struct{ *Resolver }
 
@@ -390,3 +499,9 @@ func (r *tokenPaymentResolver) Post(ctx context.Context, payment *payments.Token
 	}
 	return post, nil
 }
+
+type paymentsSubscriptionResolver struct{ *Resolver }
+
+func (r *paymentsSubscriptionResolver) Channel(ctx context.Context, subscription *payments.StripeSubscription) (*channels.Channel, error) {
+	return r.channelService.GetChannel(subscription.OwnerChannelID)
+}
@@ -0,0 +1,87 @@
+package graphql
+
+import (
+	context "context"
+
+	"github.com/joincivil/civil-api-server/pkg/auth"
+	"github.com/joincivil/civil-api-server/pkg/generated/graphql"
+	"github.com/joincivil/civil-api-server/pkg/posts"
+)
+
+// QUERIES
+
+func (r *queryResolver) PostsGetComments(ctx context.Context, postID string, first *int, after *string,
+	sort *posts.CommentSort) (*graphql.PostResultCursor, error) {
+	return comments(ctx, r.postService, postID, first, after, sort)
+}
+
+func comments(ctx context.Context, postService *posts.Service, postID string, first *int, after *string,
+	sort *posts.CommentSort) (*graphql.PostResultCursor, error) {
+	var viewerUserID string
+	if token := auth.ForContext(ctx); token != nil {
+		viewerUserID = token.Sub
+	}
+	commentSort := posts.CommentSortNewest
+	if sort != nil {
+		commentSort = *sort
+	}
+
+	return paginatedPosts(first, after, func(count int, offset int) (*posts.PostSearchResult, error) {
+		return postService.GetComments(viewerUserID, postID, commentSort, count, offset)
+	})
+}
+
+// MUTATIONS
+
+func (r *mutationResolver) PostsHideComment(ctx context.Context, postID string, hidden bool) (*posts.Comment, error) {
+	return r.moderateComment(ctx, postID, func(userID string) (*posts.CommentModeration, error) {
+		return r.postService.HideComment(userID, postID, hidden)
+	})
+}
+
+func (r *mutationResolver) PostsPinComment(ctx context.Context, postID string, pinned bool) (*posts.Comment, error) {
+	return r.moderateComment(ctx, postID, func(userID string) (*posts.CommentModeration, error) {
+		return r.postService.PinComment(userID, postID, pinned)
+	})
+}
+
+func (r *mutationResolver) PostsLockCommentThread(ctx context.Context, postID string, locked bool) (*posts.Comment, error) {
+	return r.moderateComment(ctx, postID, func(userID string) (*posts.CommentModeration, error) {
+		return r.postService.LockCommentThread(userID, postID, locked)
+	})
+}
+
+func (r *mutationResolver) moderateComment(ctx context.Context, postID string,
+	moderate func(userID string) (*posts.CommentModeration, error)) (*posts.Comment, error) {
+	token := auth.ForContext(ctx)
+	if token == nil {
+		return nil, ErrAccessDenied
+	}
+
+	// the post service checks the user is an admin of the channel the comment thread belongs to
+	_, err := moderate(token.Sub)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := r.postService.GetPost(postID)
+	if err != nil {
+		return nil, err
+	}
+	return post.(*posts.Comment), nil
+}
+
+// Moderation returns the moderation applied to a Comment by admins of its thread's channel, if any
+func (r *postCommentResolver) Moderation(ctx context.Context, post *posts.Comment) (*posts.CommentModeration, error) {
+	moderation, err := r.postService.GetCommentModeration(post.ID)
+	if err == posts.ErrorNotFound {
+		return nil, nil
+	}
+	return moderation, err
+}
+
+// Replies returns the comments replying to a Comment, pinned replies first
+func (r *postCommentResolver) Replies(ctx context.Context, post *posts.Comment, first *int, after *string,
+	sort *posts.CommentSort) (*graphql.PostResultCursor, error) {
+	return comments(ctx, r.postService, post.ID, first, after, sort)
+}
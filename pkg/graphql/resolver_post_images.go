@@ -0,0 +1,34 @@
+package graphql
+
+import (
+	context "context"
+
+	"github.com/joincivil/civil-api-server/pkg/auth"
+	"github.com/joincivil/civil-api-server/pkg/generated/graphql"
+	"github.com/joincivil/civil-api-server/pkg/images"
+	"github.com/joincivil/civil-api-server/pkg/posts"
+)
+
+// PostImageVariant is the resolver for the PostImageVariant type
+func (r *Resolver) PostImageVariant() graphql.PostImageVariantResolver {
+	return &postImageVariantResolver{r}
+}
+
+// MUTATIONS
+
+func (r *mutationResolver) PostsSetBoostCoverImage(ctx context.Context, postID string, dataURL *string) (*posts.Boost, error) {
+	token := auth.ForContext(ctx)
+	if token == nil {
+		return nil, ErrAccessDenied
+	}
+
+	// the post service checks the user is an admin of the boost's channel
+	return r.postService.SetBoostCoverImage(token.Sub, postID, dataURL)
+}
+
+type postImageVariantResolver struct{ *Resolver }
+
+// URL returns the URL the variant is served from by the blob store
+func (r *postImageVariantResolver) URL(ctx context.Context, variant *images.StoredVariant) (string, error) {
+	return r.imagePipeline.URL(variant), nil
+}
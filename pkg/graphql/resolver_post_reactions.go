@@ -0,0 +1,59 @@
+package graphql
+
+import (
+	context "context"
+
+	"github.com/joincivil/civil-api-server/pkg/auth"
+	"github.com/joincivil/civil-api-server/pkg/posts"
+)
+
+// QUERIES
+
+func (r *queryResolver) PostsReactionEmojis(ctx context.Context) ([]string, error) {
+	return posts.ReactionEmojis, nil
+}
+
+// MUTATIONS
+
+func (r *mutationResolver) PostsReact(ctx context.Context, postID string, emoji string) (posts.Post, error) {
+	token := auth.ForContext(ctx)
+	if token == nil {
+		return nil, ErrAccessDenied
+	}
+
+	return r.postService.React(token.Sub, postID, emoji)
+}
+
+func (r *mutationResolver) PostsUnreact(ctx context.Context, postID string, emoji string) (posts.Post, error) {
+	token := auth.ForContext(ctx)
+	if token == nil {
+		return nil, ErrAccessDenied
+	}
+
+	return r.postService.Unreact(token.Sub, postID, emoji)
+}
+
+func (r *postResolver) getReactions(ctx context.Context, postID string) ([]*posts.ReactionCount, error) {
+	loaders := ctxLoaders(ctx)
+	return loaders.postReactionsLoader.Load(postID)
+}
+
+// Reactions returns the number of reactions to a Boost post with each emoji
+func (r *postBoostResolver) Reactions(ctx context.Context, post *posts.Boost) ([]*posts.ReactionCount, error) {
+	return r.getReactions(ctx, post.ID)
+}
+
+// Reactions returns the number of reactions to an ExternalLink post with each emoji
+func (r *postExternalLinkResolver) Reactions(ctx context.Context, post *posts.ExternalLink) ([]*posts.ReactionCount, error) {
+	return r.getReactions(ctx, post.ID)
+}
+
+// Reactions returns the number of reactions to a Comment post with each emoji
+func (r *postCommentResolver) Reactions(ctx context.Context, post *posts.Comment) ([]*posts.ReactionCount, error) {
+	return r.getReactions(ctx, post.ID)
+}
+
+// Reactions returns the number of reactions to a BoostUpdate post with each emoji
+func (r *postBoostUpdateResolver) Reactions(ctx context.Context, post *posts.BoostUpdate) ([]*posts.ReactionCount, error) {
+	return r.getReactions(ctx, post.ID)
+}
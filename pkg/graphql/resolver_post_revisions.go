@@ -0,0 +1,110 @@
+package graphql
+
+import (
+	context "context"
+	"encoding/json"
+
+	"github.com/joincivil/civil-api-server/pkg/generated/graphql"
+	"github.com/joincivil/civil-api-server/pkg/posts"
+	cpostgres "github.com/joincivil/go-common/pkg/persistence/postgres"
+)
+
+// PostRevision is the resolver for the PostRevision type
+func (r *Resolver) PostRevision() graphql.PostRevisionResolver {
+	return &postRevisionResolver{r}
+}
+
+// PostRevisionChange is the resolver for the PostRevisionChange type
+func (r *Resolver) PostRevisionChange() graphql.PostRevisionChangeResolver {
+	return &postRevisionChangeResolver{r}
+}
+
+func (r *postResolver) getRevision(ctx context.Context, postID string, n int) (*posts.PostRevision, error) {
+	revision, err := r.postService.GetPostRevision(postID, n)
+	if err == posts.ErrorNotFound {
+		return nil, nil
+	}
+	return revision, err
+}
+
+// Revisions returns the edit history of a Boost post
+func (r *postBoostResolver) Revisions(ctx context.Context, post *posts.Boost) ([]*posts.PostRevision, error) {
+	return r.postService.GetPostRevisions(post.ID)
+}
+
+// Revision returns revision `n` of a Boost post
+func (r *postBoostResolver) Revision(ctx context.Context, post *posts.Boost, n int) (*posts.PostRevision, error) {
+	return r.getRevision(ctx, post.ID, n)
+}
+
+// EditedAfterPaymentsReceived returns whether the Boost was edited after its first completed payment
+func (r *postBoostResolver) EditedAfterPaymentsReceived(ctx context.Context, post *posts.Boost) (bool, error) {
+	return r.postService.IsPostEditedAfterPayments(post.ID)
+}
+
+// Revisions returns the edit history of an ExternalLink post
+func (r *postExternalLinkResolver) Revisions(ctx context.Context, post *posts.ExternalLink) ([]*posts.PostRevision, error) {
+	return r.postService.GetPostRevisions(post.ID)
+}
+
+// Revision returns revision `n` of an ExternalLink post
+func (r *postExternalLinkResolver) Revision(ctx context.Context, post *posts.ExternalLink, n int) (*posts.PostRevision, error) {
+	return r.getRevision(ctx, post.ID, n)
+}
+
+// Revisions returns the edit history of a Comment post
+func (r *postCommentResolver) Revisions(ctx context.Context, post *posts.Comment) ([]*posts.PostRevision, error) {
+	return r.postService.GetPostRevisions(post.ID)
+}
+
+// Revision returns revision `n` of a Comment post
+func (r *postCommentResolver) Revision(ctx context.Context, post *posts.Comment, n int) (*posts.PostRevision, error) {
+	return r.getRevision(ctx, post.ID, n)
+}
+
+// Revisions returns the edit history of a BoostUpdate post
+func (r *postBoostUpdateResolver) Revisions(ctx context.Context, post *posts.BoostUpdate) ([]*posts.PostRevision, error) {
+	return r.postService.GetPostRevisions(post.ID)
+}
+
+// Revision returns revision `n` of a BoostUpdate post
+func (r *postBoostUpdateResolver) Revision(ctx context.Context, post *posts.BoostUpdate, n int) (*posts.PostRevision, error) {
+	return r.getRevision(ctx, post.ID, n)
+}
+
+type postRevisionResolver struct{ *Resolver }
+
+// Data returns the snapshot of the post data after the edit
+func (r *postRevisionResolver) Data(ctx context.Context, revision *posts.PostRevision) (*cpostgres.JsonbPayload, error) {
+	payload := cpostgres.JsonbPayload{}
+	err := json.Unmarshal(revision.Data.RawMessage, &payload)
+	if err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
+
+// Changes returns the fields changed by the edit
+func (r *postRevisionResolver) Changes(ctx context.Context, revision *posts.PostRevision) ([]*posts.PostRevisionChange, error) {
+	return revision.GetChanges()
+}
+
+type postRevisionChangeResolver struct{ *Resolver }
+
+// OldValue returns the JSON encoded value of the field before the edit
+func (r *postRevisionChangeResolver) OldValue(ctx context.Context, change *posts.PostRevisionChange) (*string, error) {
+	return rawJSONString(change.OldValue), nil
+}
+
+// NewValue returns the JSON encoded value of the field after the edit
+func (r *postRevisionChangeResolver) NewValue(ctx context.Context, change *posts.PostRevisionChange) (*string, error) {
+	return rawJSONString(change.NewValue), nil
+}
+
+func rawJSONString(value json.RawMessage) *string {
+	if len(value) == 0 {
+		return nil
+	}
+	s := string(value)
+	return &s
+}
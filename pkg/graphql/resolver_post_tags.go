@@ -0,0 +1,51 @@
+package graphql
+
+import (
+	context "context"
+
+	"github.com/joincivil/civil-api-server/pkg/posts"
+)
+
+const (
+	defaultPopularTagsCount = 20
+	maxPopularTagsCount     = 100
+)
+
+// QUERIES
+
+func (r *queryResolver) PostsPopularTags(ctx context.Context, first *int) ([]*posts.TagCount, error) {
+	limit := defaultPopularTagsCount
+	if first != nil && *first > 0 {
+		limit = *first
+	}
+	if limit > maxPopularTagsCount {
+		limit = maxPopularTagsCount
+	}
+
+	return r.postService.GetPopularTags(limit)
+}
+
+func (r *postResolver) getTags(ctx context.Context, postID string) ([]*posts.Tag, error) {
+	loaders := ctxLoaders(ctx)
+	return loaders.postTagsLoader.Load(postID)
+}
+
+// Tags returns the tags of a Boost post
+func (r *postBoostResolver) Tags(ctx context.Context, post *posts.Boost) ([]*posts.Tag, error) {
+	return r.getTags(ctx, post.ID)
+}
+
+// Tags returns the tags of an ExternalLink post
+func (r *postExternalLinkResolver) Tags(ctx context.Context, post *posts.ExternalLink) ([]*posts.Tag, error) {
+	return r.getTags(ctx, post.ID)
+}
+
+// Tags returns the tags of a Comment post
+func (r *postCommentResolver) Tags(ctx context.Context, post *posts.Comment) ([]*posts.Tag, error) {
+	return r.getTags(ctx, post.ID)
+}
+
+// Tags returns the tags of a BoostUpdate post
+func (r *postBoostUpdateResolver) Tags(ctx context.Context, post *posts.BoostUpdate) ([]*posts.Tag, error) {
+	return r.getTags(ctx, post.ID)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/joincivil/civil-api-server/pkg/payments"
 	"github.com/joincivil/civil-api-server/pkg/posts"
 	"github.com/joincivil/civil-events-processor/pkg/utils"
+	"time"
 )
 
 // PostBoost is the resolver for the PostBoost type
@@ -23,6 +24,11 @@ func (r *Resolver) PostExternalLink() graphql.PostExternalLinkResolver {
 	return &postExternalLinkResolver{Resolver: r, postResolver: &postResolver{r}}
 }
 
+// PostBoostUpdate is the resolver for the PostBoostUpdate type
+func (r *Resolver) PostBoostUpdate() graphql.PostBoostUpdateResolver {
+	return &postBoostUpdateResolver{Resolver: r, postResolver: &postResolver{r}}
+}
+
 // PostComment is the resolver for the PostComment type
 func (r *Resolver) PostComment() graphql.PostCommentResolver {
 	return &postCommentResolver{Resolver: r, postResolver: &postResolver{r}}
@@ -31,17 +37,34 @@ func (r *Resolver) PostComment() graphql.PostCommentResolver {
 // QUERIES
 
 func (r *queryResolver) PostsGet(ctx context.Context, id string) (posts.Post, error) {
-	return r.postService.GetPost(id)
+	post, err := r.postService.GetPost(id)
+	if err != nil {
+		return nil, err
+	}
+	return r.visiblePost(ctx, post)
 }
 func (r *queryResolver) PostsGetChildren(ctx context.Context, id string, first *int, after *string) (*graphql.PostResultCursor, error) {
 	return children(ctx, r.postService, id, first, after)
 }
 
 func (r *queryResolver) PostsGetByReference(ctx context.Context, reference string) (posts.Post, error) {
-	return r.postService.GetPostByReferenceSafe(reference)
+	post, err := r.postService.GetPostByReferenceSafe(reference)
+	if err != nil {
+		return nil, err
+	}
+	return r.visiblePost(ctx, post)
 }
 
 func (r *queryResolver) PostsSearch(ctx context.Context, input posts.SearchInput) (*posts.PostSearchResult, error) {
+	// drafts are only searchable within a channel, by its admins
+	if input.IncludeDrafts {
+		if input.ChannelID == "" {
+			return nil, ErrIncludeDraftsWithoutChannel
+		}
+		if !r.isPostChannelAdmin(ctx, input.ChannelID) {
+			return nil, ErrUserNotAuthorized
+		}
+	}
 
 	results, err := r.postService.SearchPosts(&input)
 
@@ -56,27 +79,32 @@ func (r *queryResolver) PostsSearchGroupedByChannel(ctx context.Context, input p
 }
 
 func (r *queryResolver) PostsStoryfeed(ctx context.Context, first *int, after *string, filter *posts.StoryfeedFilter) (*graphql.PostResultCursor, error) {
-
-	cursor := defaultPaginationCursor
-	var offset int
+	var afterCursor *posts.StoryfeedCursor
 	var err error
 	count := criteriaCount(first)
-	// Figure out the pagination index start point if given
+	if filter != nil && filter.FollowedOnly != nil && *filter.FollowedOnly {
+		token := auth.ForContext(ctx)
+		if token == nil {
+			return nil, ErrAccessDenied
+		}
+		filter.FollowerUserID = token.Sub
+	}
+	// Figure out the ranking key to continue from if given
 	if after != nil && *after != "" {
-		offset, cursor, err = paginationOffsetFromCursor(cursor, after)
+		afterCursor, err = storyfeedCursorFromCursor(after)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	results, err := r.postService.SearchPostsRanked(count, offset, filter)
+	results, err := r.postService.SearchPostsRanked(count, afterCursor, filter)
 	if err != nil {
 		return nil, err
 	}
 
 	posts, hasNextPage := postsReturnPosts(results.Posts, count)
 
-	edges := postsBuildEdges(posts, cursor)
+	edges := storyfeedBuildEdges(posts, results.Cursors)
 	endCursor := postsEndCursor(edges)
 
 	return &graphql.PostResultCursor{
@@ -88,6 +116,48 @@ func (r *queryResolver) PostsStoryfeed(ctx context.Context, first *int, after *s
 	}, err
 }
 
+func (r *queryResolver) PostsStoryfeedAlgorithms(ctx context.Context) ([]*graphql.StoryfeedAlgorithm, error) {
+	algs := r.postService.StoryfeedAlgorithms()
+	results := make([]*graphql.StoryfeedAlgorithm, len(algs))
+	for i, alg := range algs {
+		results[i] = &graphql.StoryfeedAlgorithm{
+			Name:        alg.Name(),
+			Description: alg.Description(),
+		}
+	}
+	return results, nil
+}
+
+func storyfeedCursorFromCursor(after *string) (*posts.StoryfeedCursor, error) {
+	afterCursor, err := decodeToPaginationCursor(*after)
+	if err != nil {
+		return nil, err
+	}
+	if afterCursor.typeName != cursorTypeKeyset {
+		return nil, fmt.Errorf("Invalid cursor type: %v", afterCursor.typeName)
+	}
+	return posts.ParseStoryfeedCursor(afterCursor.value)
+}
+
+func storyfeedBuildEdges(allPosts []posts.Post,
+	cursors []*posts.StoryfeedCursor) []*graphql.PostEdge {
+
+	edges := make([]*graphql.PostEdge, len(allPosts))
+
+	// Each edge cursor encodes the ranking key of its post
+	for index, post := range allPosts {
+		newCursor := &paginationCursor{
+			typeName: cursorTypeKeyset,
+			value:    cursors[index].String(),
+		}
+		edges[index] = &graphql.PostEdge{
+			Cursor: newCursor.Encode(),
+			Post:   post,
+		}
+	}
+	return edges
+}
+
 func postsReturnPosts(allPosts []posts.Post,
 	count int) ([]posts.Post, bool) {
 	allPostsLen := len(allPosts)
@@ -191,6 +261,30 @@ func (r *mutationResolver) PostsUpdateBoost(ctx context.Context, postID string,
 	return post.(*posts.Boost), nil
 }
 
+func (r *mutationResolver) PostsCreateBoostUpdate(ctx context.Context, input posts.BoostUpdate) (*posts.BoostUpdate, error) {
+	token := auth.ForContext(ctx)
+	if token == nil {
+		return nil, ErrAccessDenied
+	}
+
+	// the post service checks the user is an admin of the boost's channel
+	post, err := r.postService.CreatePost(token.Sub, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.(*posts.BoostUpdate), nil
+}
+
+func (r *mutationResolver) PostsPublish(ctx context.Context, postID string, publishAt *time.Time) (posts.Post, error) {
+	token := auth.ForContext(ctx)
+	if token == nil {
+		return nil, ErrAccessDenied
+	}
+
+	return r.postService.PublishPost(token.Sub, postID, publishAt)
+}
+
 func (r *mutationResolver) PostsCreateComment(ctx context.Context, input posts.Comment) (*posts.Comment, error) {
 	post, err := r.postCreate(ctx, input)
 	if err != nil {
@@ -252,22 +346,66 @@ func (r *mutationResolver) PostsUpdateExternalLink(ctx context.Context, postID s
 	return post.(*posts.ExternalLink), nil
 }
 
+func (r *mutationResolver) PostsRefreshExternalLink(ctx context.Context, postID string) (*posts.ExternalLink, error) {
+	token := auth.ForContext(ctx)
+	if token == nil {
+		return nil, ErrAccessDenied
+	}
+
+	post, err := r.postService.GetPost(postID)
+	if err != nil {
+		return nil, err
+	}
+	isAdmin, err := r.channelService.IsChannelAdmin(token.Sub, post.GetChannelID())
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, ErrUserNotAuthorized
+	}
+
+	return r.externalLinkRefresher.RefreshPost(postID)
+}
+
 // errors
 var (
-	ErrNotImplemented            = errors.New("field not yet implemented")
-	ErrEmptyURLSubmitted         = errors.New("empty url submitted")
-	ErrNoListingFoundForURL      = errors.New("no listing found associated with submitted url")
-	ErrNoChannelFoundForNewsroom = errors.New("no channel found for listing")
+	ErrNotImplemented              = errors.New("field not yet implemented")
+	ErrEmptyURLSubmitted           = errors.New("empty url submitted")
+	ErrNoListingFoundForURL        = errors.New("no listing found associated with submitted url")
+	ErrNoChannelFoundForNewsroom   = errors.New("no channel found for listing")
+	ErrIncludeDraftsWithoutChannel = errors.New("a channelID is required to include drafts")
 )
 
 // TYPE RESOLVERS
 type postResolver struct{ *Resolver }
 
+// visiblePost hides draft and scheduled posts from everyone but the admins of the post's channel,
+// and posts hidden by moderators from everyone but platform admins
+func (r *Resolver) visiblePost(ctx context.Context, post posts.Post) (posts.Post, error) {
+	if post.GetPostModel().Draft && !r.isPostChannelAdmin(ctx, post.GetChannelID()) {
+		return nil, posts.ErrorNotFound
+	}
+	if post.GetPostModel().Hidden {
+		token := auth.ForContext(ctx)
+		if token == nil || !token.IsAdmin {
+			return nil, posts.ErrorNotFound
+		}
+	}
+	return post, nil
+}
+
 func (r *postResolver) getChannel(ctx context.Context, post posts.Post) (*channels.Channel, error) {
 	return r.channelService.GetChannel(post.GetChannelID())
 }
 
 func children(ctx context.Context, postService *posts.Service, postID string, first *int, after *string) (*graphql.PostResultCursor, error) {
+	return paginatedPosts(first, after, func(count int, offset int) (*posts.PostSearchResult, error) {
+		return postService.SearchChildren(postID, count, offset)
+	})
+}
+
+// paginatedPosts builds a cursor over the posts returned by `search`, continuing from the offset in `after`
+func paginatedPosts(first *int, after *string, search func(count int, offset int) (*posts.PostSearchResult, error)) (*graphql.PostResultCursor, error) {
 	cursor := defaultPaginationCursor
 	var offset int
 	var err error
@@ -280,7 +418,7 @@ func children(ctx context.Context, postService *posts.Service, postID string, fi
 		}
 	}
 
-	results, err := postService.SearchChildren(postID, count, offset)
+	results, err := search(count, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -342,6 +480,16 @@ func (r *postBoostResolver) PaymentsTotal(ctx context.Context, boost *posts.Boos
 	return r.paymentService.TotalPayments(boost.ID, currencyCode)
 }
 
+// Status is the lifecycle status of the boost
+func (r *postBoostResolver) Status(ctx context.Context, boost *posts.Boost) (string, error) {
+	return r.boostLifecycleService.Status(boost)
+}
+
+// PercentFunded is the USD equivalent of payments as a percentage of the boost goal
+func (r *postBoostResolver) PercentFunded(ctx context.Context, boost *posts.Boost) (float64, error) {
+	return r.boostLifecycleService.PercentFunded(boost)
+}
+
 type postExternalLinkResolver struct {
 	*Resolver
 	*postResolver
@@ -395,6 +543,24 @@ func (r *postExternalLinkResolver) OpenGraphData(ctx context.Context, link *post
 	return &ogdata, nil
 }
 
+// PreviousOpenGraphData returns the open graph data this post had before it last changed on refresh
+func (r *postExternalLinkResolver) PreviousOpenGraphData(ctx context.Context, link *posts.ExternalLink) (*graphql.OpenGraphData, error) {
+	refresh, err := r.externalLinkRefresher.GetRefresh(link.ID)
+	if err == posts.ErrorNotFound || (err == nil && len(refresh.PreviousOpenGraphData) == 0) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ogdata graphql.OpenGraphData
+	err = json.Unmarshal(refresh.PreviousOpenGraphData, &ogdata)
+	if err != nil {
+		return nil, err
+	}
+	return &ogdata, nil
+}
+
 type postCommentResolver struct {
 	*Resolver
 	*postResolver
@@ -438,6 +604,49 @@ func (r *postCommentResolver) PaymentsTotal(ctx context.Context, comment *posts.
 	return r.paymentService.TotalPayments(comment.ID, currencyCode)
 }
 
+type postBoostUpdateResolver struct {
+	*Resolver
+	*postResolver
+}
+
+// Channel returns the channel of a BoostUpdate post
+func (r *postBoostUpdateResolver) Channel(ctx context.Context, post *posts.BoostUpdate) (*channels.Channel, error) {
+	return r.getChannel(ctx, post)
+}
+
+// NumChildren returns the number of children post of a BoostUpdate post
+func (r *postBoostUpdateResolver) NumChildren(ctx context.Context, post *posts.BoostUpdate) (int, error) {
+	return r.postService.GetNumChildrenOfPost(post.ID)
+}
+
+// Children returns children post of a BoostUpdate post
+func (r *postBoostUpdateResolver) Children(ctx context.Context, post *posts.BoostUpdate, first *int, after *string) (*graphql.PostResultCursor, error) {
+	if first == nil {
+		three := 3
+		first = &three
+	}
+	return children(ctx, r.postService, post.ID, first, after)
+}
+
+// Payments returns payments associated with this Post
+func (r *postBoostUpdateResolver) Payments(ctx context.Context, post *posts.BoostUpdate) ([]payments.Payment, error) {
+	isAdmin := r.isPostChannelAdmin(ctx, post.ChannelID)
+	if !isAdmin {
+		return nil, ErrUserNotAuthorized
+	}
+	return r.paymentService.GetPayments(post.ID)
+}
+
+// GroupedSanitizedPayments returns "sanitized payments" associated with this Post, grouped by channel
+func (r *postBoostUpdateResolver) GroupedSanitizedPayments(ctx context.Context, post *posts.BoostUpdate) ([]*payments.SanitizedPayment, error) {
+	return nil, nil
+}
+
+// PaymentsTotal is the sum if payments for this Post
+func (r *postBoostUpdateResolver) PaymentsTotal(ctx context.Context, post *posts.BoostUpdate, currencyCode string) (float64, error) {
+	return r.paymentService.TotalPayments(post.ID, currencyCode)
+}
+
 // SanitizedPayment is a custom resolver for SanitizedPayments (so can get payer channel data)
 func (r *Resolver) SanitizedPayment() graphql.SanitizedPaymentResolver {
 	return &sanitizedPaymentResolver{Resolver: r}
@@ -0,0 +1,184 @@
+package graphql
+
+import (
+	context "context"
+	"fmt"
+
+	"github.com/joincivil/civil-api-server/pkg/auth"
+	"github.com/joincivil/civil-api-server/pkg/generated/graphql"
+	"github.com/joincivil/civil-api-server/pkg/posts"
+	"github.com/joincivil/civil-api-server/pkg/reports"
+)
+
+const (
+	maxReportActions = 100
+)
+
+// Report is the resolver for the Report type
+func (r *Resolver) Report() graphql.ReportResolver {
+	return &reportResolver{r}
+}
+
+// requirePlatformAdmin returns the token of the current user if they are a platform admin
+func requirePlatformAdmin(ctx context.Context) (*auth.Token, error) {
+	token := auth.ForContext(ctx)
+	if token == nil {
+		return nil, ErrAccessDenied
+	}
+	if !token.IsAdmin {
+		return nil, ErrUserNotAuthorized
+	}
+	return token, nil
+}
+
+// QUERIES
+
+func (r *queryResolver) ReportsQueue(ctx context.Context, status *string, first *int, after *string) (*graphql.ReportResultCursor, error) {
+	if _, err := requirePlatformAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	var reportStatus string
+	if status != nil {
+		reportStatus = *status
+	}
+	count, offset, cursor, err := offsetPagination(first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := r.reportService.GetQueue(reportStatus, count, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	hasNextPage := len(results) == count
+	if hasNextPage {
+		results = results[:len(results)-1]
+	}
+	edges := make([]*graphql.ReportEdge, len(results))
+	for index, report := range results {
+		edges[index] = &graphql.ReportEdge{
+			Cursor: offsetEdgeCursor(cursor, index),
+			Report: report,
+		}
+	}
+
+	var endCursor *string
+	if len(edges) > 0 {
+		endCursor = &edges[len(edges)-1].Cursor
+	}
+	return &graphql.ReportResultCursor{
+		Edges: edges,
+		PageInfo: &graphql.PageInfo{
+			EndCursor:   endCursor,
+			HasNextPage: hasNextPage,
+		},
+	}, nil
+}
+
+func (r *queryResolver) ReportsAuditTrail(ctx context.Context, postID *string, first *int, after *string) (*graphql.ModerationActionResultCursor, error) {
+	if _, err := requirePlatformAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	var actionPostID string
+	if postID != nil {
+		actionPostID = *postID
+	}
+	count, offset, cursor, err := offsetPagination(first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := r.reportService.GetAuditTrail(actionPostID, count, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	hasNextPage := len(results) == count
+	if hasNextPage {
+		results = results[:len(results)-1]
+	}
+	edges := make([]*graphql.ModerationActionEdge, len(results))
+	for index, action := range results {
+		edges[index] = &graphql.ModerationActionEdge{
+			Cursor: offsetEdgeCursor(cursor, index),
+			Action: action,
+		}
+	}
+
+	var endCursor *string
+	if len(edges) > 0 {
+		endCursor = &edges[len(edges)-1].Cursor
+	}
+	return &graphql.ModerationActionResultCursor{
+		Edges: edges,
+		PageInfo: &graphql.PageInfo{
+			EndCursor:   endCursor,
+			HasNextPage: hasNextPage,
+		},
+	}, nil
+}
+
+// offsetPagination returns the number of items to fetch, the offset to start from and the cursor to build edges from
+func offsetPagination(first *int, after *string) (int, int, *paginationCursor, error) {
+	cursor := defaultPaginationCursor
+	offset := 0
+	var err error
+	if after != nil && *after != "" {
+		offset, cursor, err = paginationOffsetFromCursor(cursor, after)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+	}
+	return criteriaCount(first), offset, cursor, nil
+}
+
+func offsetEdgeCursor(cursor *paginationCursor, index int) string {
+	newCursor := &paginationCursor{
+		typeName: cursor.typeName,
+		value:    fmt.Sprintf("%v", cursor.ValueInt()+index),
+	}
+	return newCursor.Encode()
+}
+
+// MUTATIONS
+
+func (r *mutationResolver) PostsReport(ctx context.Context, input reports.Report) (*reports.Report, error) {
+	token := auth.ForContext(ctx)
+	if token == nil {
+		return nil, ErrAccessDenied
+	}
+
+	return r.reportService.ReportPost(token.Sub, input.PostID, input.Reason, input.Details)
+}
+
+func (r *mutationResolver) ReportsResolve(ctx context.Context, reportID string, action reports.ActionType, note *string) (*reports.Report, error) {
+	token, err := requirePlatformAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var actionNote string
+	if note != nil {
+		actionNote = *note
+	}
+	return r.reportService.Resolve(token.Sub, reportID, action, actionNote)
+}
+
+type reportResolver struct{ *Resolver }
+
+// Post returns the reported post, or nil if it has been deleted
+func (r *reportResolver) Post(ctx context.Context, report *reports.Report) (posts.Post, error) {
+	post, err := r.postService.GetPost(report.PostID)
+	if err == posts.ErrorNotFound {
+		return nil, nil
+	}
+	return post, err
+}
+
+// Actions returns the moderation actions taken on the reported post
+func (r *reportResolver) Actions(ctx context.Context, report *reports.Report) ([]*reports.ModerationAction, error) {
+	return r.reportService.GetAuditTrail(report.PostID, maxReportActions, 0)
+}
@@ -14,6 +14,9 @@ const (
 	// Represents a cursor that uses row offset as the position
 	cursorTypeOffset = "offset"
 
+	// Represents a cursor that uses the ranking key of a row as the position
+	cursorTypeKeyset = "keyset"
+
 	defaultCursorType  = cursorTypeOffset
 	defaultCursorValue = "0"
 )
@@ -9,7 +9,9 @@ import (
 	"github.com/joincivil/civil-api-server/pkg/runtime"
 
 	log "github.com/golang/glog"
+	"github.com/joincivil/civil-api-server/pkg/feeds"
 	"github.com/joincivil/civil-api-server/pkg/payments"
+	"github.com/joincivil/civil-api-server/pkg/posts"
 	"github.com/joincivil/civil-api-server/pkg/utils"
 	"github.com/joincivil/civil-events-processor/pkg/helpers"
 	pconfig "github.com/joincivil/go-common/pkg/config"
@@ -27,6 +29,10 @@ var MainModule = fx.Options(
 	fx.Invoke(RunPostPersisterMigrations),
 	fx.Invoke(RunServer),
 	fx.Invoke(payments.PaymentUpdaterCron),
+	fx.Invoke(feeds.FeedPollerCron),
+	fx.Invoke(posts.ExternalLinkRefreshCron),
+	fx.Invoke(posts.BoostLifecycleCron),
+	fx.Invoke(posts.PostPublisherCron),
 )
 
 // EventProcessorModule defines the dependencies for the Event Processor
@@ -10,8 +10,10 @@ import (
 
 	"github.com/jinzhu/gorm"
 	"github.com/joincivil/civil-api-server/pkg/channels"
+	"github.com/joincivil/civil-api-server/pkg/feeds"
 	"github.com/joincivil/civil-api-server/pkg/payments"
 	"github.com/joincivil/civil-api-server/pkg/posts"
+	"github.com/joincivil/civil-api-server/pkg/reports"
 	"github.com/joincivil/civil-api-server/pkg/utils"
 )
 
@@ -57,9 +59,21 @@ func NewGorm(config *utils.GraphQLConfig) (*gorm.DB, error) {
 
 	amErr := db.AutoMigrate(
 		&posts.PostModel{},
+		&posts.ExternalLinkRefresh{},
+		&posts.PostRevision{},
+		&posts.BoostLifecycle{},
+		&posts.CommentModeration{},
+		&posts.PostReaction{},
+		&posts.Tag{},
 		&payments.PaymentModel{},
+		&payments.StripeSubscription{},
 		&channels.Channel{},
 		&channels.ChannelMember{},
+		&channels.ChannelFollow{},
+		&feeds.ChannelFeed{},
+		&feeds.ChannelFeedItem{},
+		&reports.Report{},
+		&reports.ModerationAction{},
 	).Error
 	if amErr != nil {
 		log.Errorf("automigration error: %v", amErr)
@@ -5,6 +5,9 @@ import (
 	"github.com/didip/tollbooth"
 	"github.com/didip/tollbooth_chi"
 	"github.com/go-chi/chi"
+	"github.com/joincivil/civil-api-server/pkg/blobstore"
+	"github.com/joincivil/civil-api-server/pkg/embeds"
+	"github.com/joincivil/civil-api-server/pkg/feeds"
 	"github.com/joincivil/civil-api-server/pkg/nrsignup"
 	"net/http"
 )
@@ -37,3 +40,52 @@ func nrsignupRouting(deps ServerDeps) error {
 
 	return nil
 }
+
+func feedsRouting(deps ServerDeps) error {
+
+	// Feed readers poll on a schedule, conditional GETs keep most requests cheap
+	limiter := tollbooth.NewLimiter(5, nil) // 5 req/sec max
+	limiter.SetIPLookups([]string{"X-Forwarded-For", "RemoteAddr", "X-Real-IP"})
+	limiter.SetMethods([]string{"GET"})
+
+	deps.Router.Route(fmt.Sprintf("/%v/feeds", invoicingVersion), func(r chi.Router) {
+		r.Use(tollbooth_chi.LimitHandler(limiter))
+		r.Get(fmt.Sprintf("/storyfeed/{%v}", feeds.FormatURLParam), deps.Syndicator.GlobalStoryfeedHandler())
+		r.Get(
+			fmt.Sprintf("/channels/{%v}/storyfeed/{%v}", feeds.ChannelIDURLParam, feeds.FormatURLParam),
+			deps.Syndicator.ChannelStoryfeedHandler(),
+		)
+	})
+
+	return nil
+}
+
+func embedsRouting(deps ServerDeps) error {
+
+	// Widgets are cached, so most requests come from browsers loading a newsroom page for the first time
+	limiter := tollbooth.NewLimiter(10, nil) // 10 req/sec max
+	limiter.SetIPLookups([]string{"X-Forwarded-For", "RemoteAddr", "X-Real-IP"})
+	limiter.SetMethods([]string{"GET"})
+
+	deps.Router.Route(fmt.Sprintf("/%v/embed", invoicingVersion), func(r chi.Router) {
+		r.Use(tollbooth_chi.LimitHandler(limiter))
+		r.Get(fmt.Sprintf("/boost/{%v}", embeds.PostIDURLParam), deps.Embedder.BoostHandler())
+		r.Get(fmt.Sprintf("/boost/{%v}/json", embeds.PostIDURLParam), deps.Embedder.BoostJSONHandler())
+		r.Get("/oembed", deps.Embedder.OEmbedHandler())
+	})
+
+	return nil
+}
+
+func blobsRouting(deps ServerDeps) error {
+	// blobs in other backends are served by the backend itself
+	localStore, ok := deps.BlobStore.(*blobstore.LocalStore)
+	if !ok {
+		return nil
+	}
+
+	prefix := fmt.Sprintf("/%v/blobs", invoicingVersion)
+	deps.Router.Get(prefix+"/*", http.StripPrefix(prefix, localStore.Handler()).ServeHTTP)
+
+	return nil
+}
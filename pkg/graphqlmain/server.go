@@ -10,7 +10,10 @@ import (
 	log "github.com/golang/glog"
 	"github.com/joincivil/civil-api-server/pkg/airswap"
 	"github.com/joincivil/civil-api-server/pkg/auth"
+	"github.com/joincivil/civil-api-server/pkg/blobstore"
 	"github.com/joincivil/civil-api-server/pkg/channels"
+	"github.com/joincivil/civil-api-server/pkg/embeds"
+	"github.com/joincivil/civil-api-server/pkg/feeds"
 	"github.com/joincivil/civil-api-server/pkg/graphql"
 	"github.com/joincivil/civil-api-server/pkg/newsrooms"
 	"github.com/joincivil/civil-api-server/pkg/nrsignup"
@@ -47,6 +50,9 @@ type ServerDeps struct {
 	PostService           *posts.Service
 	ChannelService        *channels.Service
 	NewsroomService       newsrooms.Service
+	Syndicator            *feeds.Syndicator
+	Embedder              *embeds.Embedder
+	BlobStore             blobstore.Store
 	Router                chi.Router
 }
 
@@ -118,7 +124,7 @@ func RunServer(deps ServerDeps) error {
 func enableAPIServices(router chi.Router, port string, deps ServerDeps) error {
 
 	// Enable authentication/authorization handling
-	router.Use(auth.Middleware(deps.JwtGenerator))
+	router.Use(auth.Middleware(deps.JwtGenerator, deps.Config.AdminUserIDs))
 
 	err := graphQLRouting(router, deps.ErrorReporter, deps.Resolver)
 	if err != nil {
@@ -146,6 +152,34 @@ func enableAPIServices(router chi.Router, port string, deps ServerDeps) error {
 		invoicingVersion,
 	)
 
+	// Storyfeed syndication REST endpoints
+	err = feedsRouting(deps)
+	if err != nil {
+		log.Fatalf("Error setting up feeds routing: err: %v", err)
+	}
+	log.Infof(
+		"Connect to http://localhost:%v/%v/feeds/storyfeed/rss for the global storyfeed\n",
+		port,
+		invoicingVersion,
+	)
+
+	// Embeddable boost widget REST endpoints
+	err = embedsRouting(deps)
+	if err != nil {
+		log.Fatalf("Error setting up embeds routing: err: %v", err)
+	}
+	log.Infof(
+		"Connect to http://localhost:%v/%v/embed/boost/{id} for a boost widget\n",
+		port,
+		invoicingVersion,
+	)
+
+	// Blob REST endpoints, for the local blob store
+	err = blobsRouting(deps)
+	if err != nil {
+		log.Fatalf("Error setting up blobs routing: err: %v", err)
+	}
+
 	err = deps.PaymentService.WebhookRouting(router, deps.Config.StripeWebhookSigningSecret)
 	if err != nil {
 		log.Fatalf("Error setting up webhook routing: err: %v", err)
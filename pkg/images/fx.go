@@ -0,0 +1,10 @@
+package images
+
+import "go.uber.org/fx"
+
+// ImageModule builds the image pipeline
+var ImageModule = fx.Options(
+	fx.Provide(
+		NewPipeline,
+	),
+)
@@ -0,0 +1,257 @@
+package images
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"runtime"
+	"sync"
+
+	"github.com/Jeffail/tunny"
+	"github.com/nfnt/resize"
+	"github.com/vincent-petithory/dataurl"
+
+	"github.com/joincivil/civil-api-server/pkg/blobstore"
+)
+
+const (
+	// FormatPNG is the format of png images
+	FormatPNG = "png"
+	// FormatJPEG is the format of jpeg images
+	FormatJPEG = "jpeg"
+
+	// maxSourcePixels limits the size of a decoded image, so a small upload can't exhaust memory
+	maxSourcePixels = 40000000
+	jpegQuality     = 85
+)
+
+var (
+	// ErrorBadDataURL is returned when an image data URL can't be decoded
+	ErrorBadDataURL = errors.New("bad image data URL")
+	// ErrorUnsupportedFormat is returned when an image is not a png or jpeg
+	ErrorUnsupportedFormat = errors.New("image must be a png or jpeg")
+	// ErrorImageTooLarge is returned when an image has too many pixels to process
+	ErrorImageTooLarge = errors.New("image is too large")
+)
+
+// Variant is a size an image is resized to, keeping its aspect ratio.
+// Images smaller than the variant are not enlarged
+type Variant struct {
+	Name      string
+	MaxWidth  uint
+	MaxHeight uint
+}
+
+// Image is an image saved to a blob store as a set of resized variants
+type Image struct {
+	ContentType string           `json:"content_type"`
+	Variants    []*StoredVariant `json:"variants"`
+}
+
+// StoredVariant is a variant of an Image saved to a blob store
+type StoredVariant struct {
+	Name   string `json:"name"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Key    string `json:"key"`
+}
+
+// Variant returns the stored variant with the given name
+func (i *Image) Variant(name string) *StoredVariant {
+	for _, variant := range i.Variants {
+		if variant.Name == name {
+			return variant
+		}
+	}
+	return nil
+}
+
+// Keys returns the blob store keys of every variant
+func (i *Image) Keys() []string {
+	keys := make([]string, len(i.Variants))
+	for n, variant := range i.Variants {
+		keys[n] = variant.Key
+	}
+	return keys
+}
+
+// Value implements driver.Valuer so an Image can be saved as a jsonb column
+func (i Image) Value() (driver.Value, error) {
+	return json.Marshal(i)
+}
+
+// Scan implements sql.Scanner so an Image can be loaded from a jsonb column
+func (i *Image) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case []byte:
+		return json.Unmarshal(value, i)
+	case string:
+		return json.Unmarshal([]byte(value), i)
+	}
+	return fmt.Errorf("cannot scan %T into an image", src)
+}
+
+// Pipeline resizes images on a pool of workers sized to the number of CPUs, saving the results to a blob store
+type Pipeline struct {
+	store blobstore.Store
+	pool  *tunny.Pool
+}
+
+// NewPipeline builds a Pipeline that saves images to `store`
+func NewPipeline(store blobstore.Store) *Pipeline {
+	return &Pipeline{
+		store: store,
+		pool:  tunny.NewCallback(runtime.NumCPU()),
+	}
+}
+
+// Run runs a job on the pipeline's pool of workers, waiting for it to complete
+func (p *Pipeline) Run(job func()) {
+	p.pool.Process(job)
+}
+
+// URL returns the URL a stored variant is served from
+func (p *Pipeline) URL(variant *StoredVariant) string {
+	return p.store.URL(variant.Key)
+}
+
+// StoreDataURL decodes a base64 png or jpeg data URL and saves it as an Image, see Store
+func (p *Pipeline) StoreDataURL(keyPrefix string, dataURL string, variants []Variant) (*Image, error) {
+	src, format, err := DecodeDataURL(dataURL)
+	if err != nil {
+		return nil, err
+	}
+	return p.Store(keyPrefix, src, format, variants)
+}
+
+// Store resizes an image to each variant and saves them under `keyPrefix`, named after the variant.
+// If any variant fails to save, the variants that were saved are deleted
+func (p *Pipeline) Store(keyPrefix string, src image.Image, format string, variants []Variant) (*Image, error) {
+	return p.storeVariants(keyPrefix, src, format, variants, true)
+}
+
+// StoreShared is Store for images whose `keyPrefix` is addressed by their content, so their variants may
+// already be saved and referenced by other images. Variants are left in place if any variant fails to save
+func (p *Pipeline) StoreShared(keyPrefix string, src image.Image, format string, variants []Variant) (*Image, error) {
+	return p.storeVariants(keyPrefix, src, format, variants, false)
+}
+
+func (p *Pipeline) storeVariants(keyPrefix string, src image.Image, format string, variants []Variant, cleanup bool) (*Image, error) {
+	contentType, ext, err := formatContentType(format)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := make([]*StoredVariant, len(variants))
+	errs := make([]error, len(variants))
+	var wg sync.WaitGroup
+	for i, variant := range variants {
+		wg.Add(1)
+		go func(i int, variant Variant) {
+			defer wg.Done()
+			p.Run(func() {
+				stored[i], errs[i] = p.storeVariant(keyPrefix+"/"+variant.Name+"."+ext, contentType, src, format, variant)
+			})
+		}(i, variant)
+	}
+	wg.Wait()
+
+	img := &Image{ContentType: contentType, Variants: stored}
+	for _, err := range errs {
+		if err != nil {
+			if cleanup {
+				p.Delete(img)
+			}
+			return nil, err
+		}
+	}
+	return img, nil
+}
+
+// Delete removes every saved variant of an image, ignoring those that were never saved
+func (p *Pipeline) Delete(img *Image) {
+	for _, variant := range img.Variants {
+		if variant != nil {
+			p.store.Delete(variant.Key) // nolint: errcheck, gosec
+		}
+	}
+}
+
+func (p *Pipeline) storeVariant(key string, contentType string, src image.Image, format string, variant Variant) (*StoredVariant, error) {
+	resized := resize.Thumbnail(variant.MaxWidth, variant.MaxHeight, src, resize.Lanczos3)
+	data, err := Encode(resized, format)
+	if err != nil {
+		return nil, err
+	}
+	if err = p.store.Put(key, contentType, data); err != nil {
+		return nil, err
+	}
+
+	size := resized.Bounds().Size()
+	return &StoredVariant{Name: variant.Name, Width: size.X, Height: size.Y, Key: key}, nil
+}
+
+// DecodeDataURL decodes a base64 png or jpeg data URL, returning the image and its format
+func DecodeDataURL(dataURL string) (image.Image, string, error) {
+	decoded, err := dataurl.DecodeString(dataURL)
+	if err != nil {
+		return nil, "", ErrorBadDataURL
+	}
+	if decoded.Type != "image" {
+		return nil, "", ErrorUnsupportedFormat
+	}
+	return Decode(decoded.Data)
+}
+
+// Decode decodes a png or jpeg image, returning the image and its format
+func Decode(data []byte) (image.Image, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrorUnsupportedFormat
+	}
+	if format != FormatPNG && format != FormatJPEG {
+		return nil, "", ErrorUnsupportedFormat
+	}
+	if config.Width*config.Height > maxSourcePixels {
+		return nil, "", ErrorImageTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	return src, format, nil
+}
+
+// Encode encodes an image as a png or jpeg
+func Encode(img image.Image, format string) ([]byte, error) {
+	var buff bytes.Buffer
+	var err error
+	switch format {
+	case FormatPNG:
+		err = png.Encode(&buff, img)
+	case FormatJPEG:
+		err = jpeg.Encode(&buff, img, &jpeg.Options{Quality: jpegQuality})
+	default:
+		return nil, ErrorUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buff.Bytes(), nil
+}
+
+func formatContentType(format string) (string, string, error) {
+	switch format {
+	case FormatPNG:
+		return "image/png", "png", nil
+	case FormatJPEG:
+		return "image/jpeg", "jpg", nil
+	}
+	return "", "", ErrorUnsupportedFormat
+}
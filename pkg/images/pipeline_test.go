@@ -0,0 +1,157 @@
+package images_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/joincivil/civil-api-server/pkg/blobstore"
+	"github.com/joincivil/civil-api-server/pkg/images"
+)
+
+var testVariants = []images.Variant{
+	{Name: "small", MaxWidth: 100, MaxHeight: 100},
+	{Name: "large", MaxWidth: 1000, MaxHeight: 1000},
+}
+
+func makePNGDataURL(t *testing.T, width int, height int) string {
+	var buff bytes.Buffer
+	if err := png.Encode(&buff, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("error encoding png: %v", err)
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buff.Bytes())
+}
+
+func TestPipelineStoreDataURL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "images")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+	store := blobstore.NewLocalStore(dir, "http://localhost/blobs")
+	pipeline := images.NewPipeline(store)
+
+	img, err := pipeline.StoreDataURL("boosts/1/cover", makePNGDataURL(t, 400, 200), testVariants)
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if img.ContentType != "image/png" || len(img.Variants) != 2 {
+		t.Fatalf("expected a png with both variants")
+	}
+
+	small := img.Variant("small")
+	if small.Width != 100 || small.Height != 50 || small.Key != "boosts/1/cover/small.png" {
+		t.Fatalf("expected the small variant to be resized to 100x50, got %+v", small)
+	}
+	large := img.Variant("large")
+	if large.Width != 400 || large.Height != 200 {
+		t.Fatalf("was not expecting the large variant to be enlarged, got %+v", large)
+	}
+	if pipeline.URL(small) != "http://localhost/blobs/boosts/1/cover/small.png" {
+		t.Fatalf("unexpected variant URL: %v", pipeline.URL(small))
+	}
+
+	data, err := store.Get(small.Key)
+	if err != nil {
+		t.Fatalf("was not expecting an error getting the variant: %v", err)
+	}
+	stored, format, err := images.Decode(data)
+	if err != nil || format != images.FormatPNG || stored.Bounds().Dx() != 100 {
+		t.Fatalf("expected the stored variant to be a 100 pixel wide png")
+	}
+
+	pipeline.Delete(img)
+	if _, err = store.Get(small.Key); err != blobstore.ErrorNotFound {
+		t.Fatalf("expected the variants to be deleted: %v", err)
+	}
+}
+
+// failingStore is a blob store that fails to save keys with the given suffix
+type failingStore struct {
+	blobstore.Store
+	suffix string
+}
+
+func (s *failingStore) Put(key string, contentType string, data []byte) error {
+	if strings.HasSuffix(key, s.suffix) {
+		return errors.New("put failed")
+	}
+	return s.Store.Put(key, contentType, data)
+}
+
+func TestPipelineStoreFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "images")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+	store := blobstore.NewLocalStore(dir, "http://localhost/blobs")
+	pipeline := images.NewPipeline(&failingStore{Store: store, suffix: "large.png"})
+	src, format, err := images.DecodeDataURL(makePNGDataURL(t, 400, 200))
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+
+	if _, err = pipeline.Store("boosts/1/cover", src, format, testVariants); err == nil {
+		t.Fatalf("was expecting an error when a variant fails to save")
+	}
+	if _, err = store.Get("boosts/1/cover/small.png"); err != blobstore.ErrorNotFound {
+		t.Fatalf("expected the saved variants to be deleted: %v", err)
+	}
+
+	// the small variant may be referenced by another image with the same content
+	if err = store.Put("avatars/abc/small.png", "image/png", []byte("shared")); err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if _, err = pipeline.StoreShared("avatars/abc", src, format, testVariants); err == nil {
+		t.Fatalf("was expecting an error when a variant fails to save")
+	}
+	if _, err = store.Get("avatars/abc/small.png"); err != nil {
+		t.Fatalf("expected the shared variants to be left in place: %v", err)
+	}
+}
+
+func TestPipelineBadDataURL(t *testing.T) {
+	pipeline := images.NewPipeline(nil)
+
+	_, err := pipeline.StoreDataURL("boosts/1/cover", "not a data url", testVariants)
+	if err != images.ErrorBadDataURL {
+		t.Fatalf("was expecting bad data URL error: %v", err)
+	}
+	_, err = pipeline.StoreDataURL("boosts/1/cover", "data:text/plain;base64,aGVsbG8=", testVariants)
+	if err != images.ErrorUnsupportedFormat {
+		t.Fatalf("was expecting unsupported format error: %v", err)
+	}
+	_, err = pipeline.StoreDataURL("boosts/1/cover", "data:image/png;base64,aGVsbG8=", testVariants)
+	if err != images.ErrorUnsupportedFormat {
+		t.Fatalf("was expecting unsupported format error for a corrupt png: %v", err)
+	}
+}
+
+func TestImageValueScan(t *testing.T) {
+	img := images.Image{
+		ContentType: "image/png",
+		Variants:    []*images.StoredVariant{{Name: "small", Width: 100, Height: 50, Key: "boosts/1/cover/small.png"}},
+	}
+	value, err := img.Value()
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+
+	scanned := &images.Image{}
+	if err = scanned.Scan(value); err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if scanned.ContentType != img.ContentType || *scanned.Variant("small") != *img.Variants[0] {
+		t.Fatalf("expected the scanned image to match, got %+v", scanned)
+	}
+	if err = scanned.Scan(1); err == nil {
+		t.Fatalf("was expecting an error scanning an int")
+	}
+}
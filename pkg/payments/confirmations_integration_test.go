@@ -0,0 +1,143 @@
+// +build integration
+
+package payments_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/joincivil/civil-api-server/pkg/payments"
+	"github.com/joincivil/civil-api-server/pkg/storefront"
+	"github.com/joincivil/civil-api-server/pkg/testruntime"
+	"github.com/joincivil/civil-api-server/pkg/testutils"
+	uuid "github.com/satori/go.uuid"
+)
+
+func TestEtherPaymentConfirmations(t *testing.T) {
+	db, err := testutils.GetTestDBConnection()
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	err = testruntime.RunMigrations(db)
+	if err != nil {
+		t.Fatalf("error running migrations: %v", err)
+	}
+	reader := testruntime.NewMockTransactionReader()
+	paymentHelper := testruntime.NewMockPaymentHelper(reader)
+	ethService := payments.NewEthereumPaymentService(reader, storefront.StaticCurrencyConversion{PriceOfETH: 100}, 3, common.Address{}, nil)
+	paymentService := payments.NewService(db, paymentHelper, ethService, nil, paymentHelper, nil)
+	channelAddress, _ := paymentHelper.GetEthereumPaymentAddress("test")
+
+	txID := addEtherTransaction(reader, 1, channelAddress)
+	payment, err := paymentService.CreateEtherPayment("test", "posts", "boost", uuid.NewV4().String(), "fake title", payments.EtherPayment{TransactionID: txID.String()}, nil)
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+
+	update := func(expectedStatus string, expectedConfirmations int) {
+		t.Helper()
+		retrieved, err := paymentService.GetPayment(payment.ID)
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		model := retrieved.(*payments.EtherPayment).PaymentModel
+		if err = paymentService.UpdateEtherPayment(&model); err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		retrieved, err = paymentService.GetPayment(payment.ID)
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		etherPayment := retrieved.(*payments.EtherPayment)
+		if etherPayment.Status != expectedStatus || etherPayment.Confirmations != expectedConfirmations {
+			t.Fatalf("expecting %v with %v confirmations, got %v with %v", expectedStatus, expectedConfirmations,
+				etherPayment.Status, etherPayment.Confirmations)
+		}
+	}
+
+	reader.AdvanceBlocks(5)
+	reader.AddReceipt(txID, minedReceipt(5, "0xa"))
+	update("confirming", 1)
+
+	// the receipt vanishes when the block is reorged out and the transaction returns to the mempool
+	reader.Reorg(txID, nil)
+	update("pending", 0)
+
+	reader.AdvanceBlocks(1)
+	reader.AddReceipt(txID, minedReceipt(6, "0xb"))
+	update("confirming", 1)
+
+	// the transaction moves to another block
+	reader.Reorg(txID, minedReceipt(6, "0xc"))
+	update("pending", 0)
+	update("confirming", 1)
+
+	reader.AdvanceBlocks(2)
+	update("complete", 3)
+}
+
+func TestTokenPaymentConfirmations(t *testing.T) {
+	db, err := testutils.GetTestDBConnection()
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	err = testruntime.RunMigrations(db)
+	if err != nil {
+		t.Fatalf("error running migrations: %v", err)
+	}
+	reader := testruntime.NewMockTransactionReader()
+	paymentHelper := testruntime.NewMockPaymentHelper(reader)
+	tokenService := payments.NewTokenPaymentService(reader, 3, []payments.Token{
+		{Symbol: "USDC", Address: usdcAddress, Decimals: 6},
+	}, payments.StaticTokenPrices{"USDC": 1})
+	paymentService := payments.NewService(db, paymentHelper, nil, tokenService, paymentHelper, nil)
+	channelAddress, _ := paymentHelper.GetEthereumPaymentAddress("test")
+
+	tx := types.NewTransaction(1, usdcAddress, big.NewInt(0), 100000, big.NewInt(1), nil)
+	reader.AddTransaction(tx.Hash(), tx)
+	minedTransfer := func(blockNumber uint64, blockHash string) *types.Receipt {
+		receipt := minedReceipt(blockNumber, blockHash)
+		receipt.Logs = []*types.Log{transferLog(usdcAddress, channelAddress, big.NewInt(5000000))}
+		return receipt
+	}
+	payment, err := paymentService.CreateTokenPayment("test", "posts", "boost", uuid.NewV4().String(), "fake title",
+		payments.TokenPayment{TransactionID: tx.Hash().String(), TokenAddress: usdcAddress.String()})
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+
+	update := func(expectedStatus string, expectedConfirmations int) {
+		t.Helper()
+		retrieved, err := paymentService.GetPayment(payment.ID)
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		model := retrieved.(*payments.TokenPayment).PaymentModel
+		if err = paymentService.UpdateTokenPayment(&model); err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		retrieved, err = paymentService.GetPayment(payment.ID)
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		tokenPayment := retrieved.(*payments.TokenPayment)
+		if tokenPayment.Status != expectedStatus || tokenPayment.Confirmations != expectedConfirmations {
+			t.Fatalf("expecting %v with %v confirmations, got %v with %v", expectedStatus, expectedConfirmations,
+				tokenPayment.Status, tokenPayment.Confirmations)
+		}
+	}
+
+	reader.AdvanceBlocks(5)
+	reader.AddReceipt(tx.Hash(), minedTransfer(5, "0xa"))
+	update("confirming", 1)
+
+	// the transfer moves to another block
+	reader.Reorg(tx.Hash(), minedTransfer(5, "0xb"))
+	update("pending", 0)
+	update("confirming", 1)
+
+	reader.AdvanceBlocks(2)
+	update("complete", 3)
+}
@@ -0,0 +1,89 @@
+package payments_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/joincivil/civil-api-server/pkg/payments"
+	"github.com/joincivil/civil-api-server/pkg/storefront"
+	"github.com/joincivil/civil-api-server/pkg/testruntime"
+	"github.com/joincivil/go-common/pkg/eth"
+)
+
+func addEtherTransaction(reader *testruntime.MockTransactionReader, nonce uint64, to common.Address) common.Hash {
+	tx := types.NewTransaction(nonce, to, big.NewInt(1e18), 21000, big.NewInt(1), nil)
+	reader.AddTransaction(tx.Hash(), tx)
+	return tx.Hash()
+}
+
+func minedReceipt(blockNumber uint64, blockHash string) *types.Receipt {
+	return &types.Receipt{Status: 1, BlockNumber: new(big.Int).SetUint64(blockNumber), BlockHash: common.HexToHash(blockHash)}
+}
+
+func TestValidateTransactionConfirmations(t *testing.T) {
+	reader := testruntime.NewMockTransactionReader()
+	service := payments.NewEthereumPaymentService(reader, storefront.StaticCurrencyConversion{PriceOfETH: 100}, 3, common.Address{}, nil)
+	channelAddress := common.HexToAddress("101")
+
+	txID := addEtherTransaction(reader, 1, channelAddress)
+	reader.AdvanceBlocks(10)
+	reader.AddReceipt(txID, minedReceipt(10, "0xa"))
+
+	res, err := service.ValidateTransaction(txID.String(), channelAddress)
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+	if res.Confirmations != 1 || res.Confirmed || res.BlockNumber != 10 {
+		t.Fatalf("expecting 1 confirmation, got %+v", res)
+	}
+
+	reader.AdvanceBlocks(2)
+	res, err = service.ValidateTransaction(txID.String(), channelAddress)
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+	if res.Confirmations != 3 || !res.Confirmed {
+		t.Fatalf("expecting the transaction to be confirmed, got %+v", res)
+	}
+}
+
+func TestSimulatedChainReader(t *testing.T) {
+	helper, err := eth.NewSimulatedBackendHelper()
+	if err != nil {
+		t.Fatalf("error setting up SimulatedBackend %v", err)
+	}
+	backend := helper.Blockchain.(*backends.SimulatedBackend)
+	service := payments.NewEthereumPaymentService(payments.NewSimulatedChainReader(backend), storefront.StaticCurrencyConversion{PriceOfETH: 100}, 2, common.Address{}, nil)
+	channelAddress := helper.Accounts["alice"].Address
+
+	tx := types.NewTransaction(0, channelAddress, big.NewInt(1e18), 21000, big.NewInt(1), nil)
+	tx, err = helper.Auth.Signer(types.HomesteadSigner{}, helper.Auth.From, tx)
+	if err != nil {
+		t.Fatalf("error signing transaction: %v", err)
+	}
+	if err = backend.SendTransaction(context.Background(), tx); err != nil {
+		t.Fatalf("error sending transaction: %v", err)
+	}
+	backend.Commit()
+
+	res, err := service.ValidateTransaction(tx.Hash().String(), channelAddress)
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+	if res.Amount != 1 || res.Confirmations != 1 || res.Confirmed {
+		t.Fatalf("expecting 1 ETH with 1 confirmation, got %+v", res)
+	}
+
+	backend.Commit()
+	res, err = service.ValidateTransaction(tx.Hash().String(), channelAddress)
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+	if res.Confirmations != 2 || !res.Confirmed {
+		t.Fatalf("expecting the transaction to be confirmed, got %+v", res)
+	}
+}
@@ -6,8 +6,13 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/joincivil/civil-api-server/pkg/storefront"
+	"github.com/joincivil/civil-api-server/pkg/utils"
 )
 
 var (
@@ -19,12 +24,59 @@ var (
 	ErrorReceiptNotFound = fmt.Errorf("receipt not found")
 	// ErrorInvalidRecipient is returned when a transaction was not sent to the expected address
 	ErrorInvalidRecipient = fmt.Errorf("invalid recipient")
+
+	// paymentEventTopic is the topic of the PaymentForwarder event
+	// Payment(address indexed channel, address indexed payer, uint256 amount, bytes32 ref)
+	paymentEventTopic = crypto.Keccak256Hash([]byte("Payment(address,address,uint256,bytes32)"))
 )
 
+// ChainReader reads transactions and the latest block header from the chain
+type ChainReader interface {
+	ethereum.TransactionReader
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// SimulatedChainReader adds HeaderByNumber to a SimulatedBackend so it can be used as a ChainReader
+type SimulatedChainReader struct {
+	*backends.SimulatedBackend
+}
+
+// NewSimulatedChainReader creates a SimulatedChainReader for the backend
+func NewSimulatedChainReader(backend *backends.SimulatedBackend) *SimulatedChainReader {
+	return &SimulatedChainReader{backend}
+}
+
+// HeaderByNumber returns the header of a committed block, the latest block when `number` is nil
+func (r *SimulatedChainReader) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil {
+		return r.Blockchain().CurrentHeader(), nil
+	}
+	header := r.Blockchain().GetHeaderByNumber(number.Uint64())
+	if header == nil {
+		return nil, ethereum.NotFound
+	}
+	return header, nil
+}
+
+// InternalTransfer is ETH sent by a contract while executing a transaction
+type InternalTransfer struct {
+	From  common.Address
+	To    common.Address
+	Value *big.Int
+}
+
+// InternalTransferTracer finds the ETH sent by contracts while executing a transaction, which isn't in the receipt
+type InternalTransferTracer interface {
+	InternalTransfers(ctx context.Context, txHash common.Hash) ([]InternalTransfer, error)
+}
+
 // EthereumPaymentService validates Layer1 payments
 type EthereumPaymentService struct {
-	chain              ethereum.TransactionReader
+	chain              ChainReader
 	currencyConversion storefront.CurrencyConversion
+	confirmations      uint64
+	forwarder          common.Address
+	tracer             InternalTransferTracer
 }
 
 // ValidateTransactionResponse is the response of ValidateTransaction
@@ -33,15 +85,46 @@ type ValidateTransactionResponse struct {
 	TransactionID  string
 	ExchangeRate   float64
 	PaymentAddress string
+	BlockNumber    uint64 `json:",omitempty"`
+	BlockHash      string `json:",omitempty"`
+	Confirmations  uint64 `json:",omitempty"`
+	// Confirmed is true once the transaction's block is deep enough that a reorg is unlikely
+	Confirmed bool `json:",omitempty"`
 }
 
 // NewEthereumPaymentService creates an EthereumPaymentService instance
-func NewEthereumPaymentService(transactionReader ethereum.TransactionReader, currencyConversion storefront.CurrencyConversion) *EthereumPaymentService {
+// that considers a transaction confirmed once its block has `confirmations` confirmations.
+// Payments from smart-contract wallets are accepted when sent through the PaymentForwarder contract at `forwarder`,
+// or when `tracer` finds the internal transfer. Either can be left empty to not accept them that way
+func NewEthereumPaymentService(chainReader ChainReader, currencyConversion storefront.CurrencyConversion, confirmations uint64,
+	forwarder common.Address, tracer InternalTransferTracer) *EthereumPaymentService {
 
 	return &EthereumPaymentService{
-		chain:              transactionReader,
+		chain:              chainReader,
 		currencyConversion: currencyConversion,
+		confirmations:      confirmations,
+		forwarder:          forwarder,
+		tracer:             tracer,
+	}
+}
+
+// NewEthereumPaymentServiceFromConfig creates an EthereumPaymentService using the graphql config
+func NewEthereumPaymentServiceFromConfig(chainReader ChainReader, currencyConversion storefront.CurrencyConversion, config *utils.GraphQLConfig) (*EthereumPaymentService, error) {
+	var forwarder common.Address
+	if forwarderAddress := config.ContractAddresses["PaymentForwarder"]; forwarderAddress != "" {
+		forwarder = common.HexToAddress(forwarderAddress)
 	}
+
+	var tracer InternalTransferTracer
+	if config.EthPaymentTraceInternalTransfers {
+		client, err := rpc.Dial(config.EthAPIURL)
+		if err != nil {
+			return nil, err
+		}
+		tracer = NewCallTracer(client)
+	}
+
+	return NewEthereumPaymentService(chainReader, currencyConversion, config.EthPaymentConfirmations, forwarder, tracer), nil
 }
 
 // ValidateTransaction accepts a transaction and determines whether it is valid
@@ -70,11 +153,12 @@ func (s *EthereumPaymentService) ValidateTransaction(transactionID string, expec
 
 	// ensure that we are actually transferring to the correct address
 	// this is to prevent trying to game the system but sending a tx from a payment that wasn't actually to them
-	if data.To().String() != expectedReceiver.String() {
-		return nil, ErrorInvalidRecipient
+	value, err := s.receivedValue(data, receipt, expectedReceiver)
+	if err != nil {
+		return nil, err
 	}
 	// convert the transction amount from wei to ether
-	var ether = new(big.Float).SetInt(data.Value())
+	var ether = new(big.Float).SetInt(value)
 	ether = ether.Quo(ether, big.NewFloat(1e18))
 	valueFloat, _ := ether.Float64()
 
@@ -84,10 +168,75 @@ func (s *EthereumPaymentService) ValidateTransaction(transactionID string, expec
 		return nil, fmt.Errorf("error getting exchange rate: err: %v", err)
 	}
 
+	blockNumber, confirmations, err := blockConfirmations(s.chain, receipt)
+	if err != nil {
+		return nil, err
+	}
+
 	return &ValidateTransactionResponse{
-		PaymentAddress: data.To().String(),
+		PaymentAddress: expectedReceiver.String(),
 		Amount:         valueFloat,
 		TransactionID:  data.Hash().String(),
 		ExchangeRate:   exchangeRate,
+		BlockNumber:    blockNumber,
+		BlockHash:      receipt.BlockHash.String(),
+		Confirmations:  confirmations,
+		Confirmed:      confirmations >= s.confirmations,
 	}, nil
 }
+
+// blockConfirmations returns the block a transaction was mined in and the number of confirmations of that block
+func blockConfirmations(chain ChainReader, receipt *types.Receipt) (uint64, uint64, error) {
+	head, err := chain.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error getting latest block: %v", err)
+	}
+	var blockNumber uint64
+	if receipt.BlockNumber != nil {
+		blockNumber = receipt.BlockNumber.Uint64()
+	}
+	var confirmations uint64
+	if head.Number.Uint64() >= blockNumber {
+		confirmations = head.Number.Uint64() - blockNumber + 1
+	}
+	return blockNumber, confirmations, nil
+}
+
+// receivedValue returns the wei the receiver got from a transaction, either sent directly, through the
+// payment forwarder contract, or by another contract such as a multisig when internal transfers are traced
+func (s *EthereumPaymentService) receivedValue(tx *types.Transaction, receipt *types.Receipt, receiver common.Address) (*big.Int, error) {
+	if tx.To() != nil && *tx.To() == receiver {
+		return tx.Value(), nil
+	}
+
+	// the forwarder logs a Payment event for each payment it forwards
+	total := new(big.Int)
+	if (s.forwarder != common.Address{}) {
+		for _, entry := range receipt.Logs {
+			if entry.Address != s.forwarder || len(entry.Topics) != 3 || entry.Topics[0] != paymentEventTopic || len(entry.Data) < 32 {
+				continue
+			}
+			if common.BytesToAddress(entry.Topics[1].Bytes()) != receiver {
+				continue
+			}
+			total.Add(total, new(big.Int).SetBytes(entry.Data[:32]))
+		}
+	}
+
+	if total.Sign() == 0 && s.tracer != nil {
+		transfers, err := s.tracer.InternalTransfers(context.Background(), tx.Hash())
+		if err != nil {
+			return nil, fmt.Errorf("error tracing transaction: %v", err)
+		}
+		for _, transfer := range transfers {
+			if transfer.To == receiver {
+				total.Add(total, transfer.Value)
+			}
+		}
+	}
+
+	if total.Sign() == 0 {
+		return nil, ErrorInvalidRecipient
+	}
+	return total, nil
+}
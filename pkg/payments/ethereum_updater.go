@@ -6,7 +6,7 @@ import (
 	log "github.com/golang/glog"
 )
 
-// PaymentUpdaterCron updates ethereum payments on a regular interval
+// PaymentUpdaterCron updates ether and token payments on a regular interval
 func PaymentUpdaterCron(service *Service) {
 
 	ticker := time.NewTicker(30 * time.Second)
@@ -16,6 +16,10 @@ func PaymentUpdaterCron(service *Service) {
 			if err != nil {
 				log.Errorf("error updating payments: %v", err)
 			}
+			err = service.UpdateTokenPayments()
+			if err != nil {
+				log.Errorf("error updating token payments: %v", err)
+			}
 		}
 	}()
 }
@@ -0,0 +1,154 @@
+package payments_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/joincivil/civil-api-server/pkg/generated/contract"
+	"github.com/joincivil/civil-api-server/pkg/payments"
+	"github.com/joincivil/civil-api-server/pkg/storefront"
+	"github.com/joincivil/go-common/pkg/eth"
+)
+
+type mockTracer map[common.Hash][]payments.InternalTransfer
+
+func (m mockTracer) InternalTransfers(ctx context.Context, txHash common.Hash) ([]payments.InternalTransfer, error) {
+	return m[txHash], nil
+}
+
+func ether(amount float64) *big.Int {
+	wei, _ := new(big.Float).Mul(big.NewFloat(amount), big.NewFloat(1e18)).Int(nil)
+	return wei
+}
+
+func TestContractWalletPayments(t *testing.T) {
+	helper, err := eth.NewSimulatedBackendHelper()
+	if err != nil {
+		t.Fatalf("error setting up SimulatedBackend %v", err)
+	}
+	backend := helper.Blockchain.(*backends.SimulatedBackend)
+	channelAddress := helper.Accounts["alice"].Address
+	wallet := helper.Accounts["bob"]
+
+	forwarderAddress, _, forwarder, err := contract.DeployPaymentForwarderContract(helper.Auth, backend)
+	if err != nil {
+		t.Fatalf("error deploying PaymentForwarderContract %v", err)
+	}
+	_, _, otherForwarder, err := contract.DeployPaymentForwarderContract(helper.Auth, backend)
+	if err != nil {
+		t.Fatalf("error deploying PaymentForwarderContract %v", err)
+	}
+	backend.Commit()
+
+	tracer := mockTracer{}
+	service := payments.NewEthereumPaymentService(payments.NewSimulatedChainReader(backend),
+		storefront.StaticCurrencyConversion{PriceOfETH: 100}, 1, forwarderAddress, tracer)
+
+	pay := func(forwarder *contract.PaymentForwarderContract, channel common.Address, amount float64) *types.Transaction {
+		t.Helper()
+		opts := helper.Transact()
+		opts.Value = ether(amount)
+		tx, err := forwarder.Pay(opts, channel, [32]byte{1})
+		if err != nil {
+			t.Fatalf("error paying through the forwarder: %v", err)
+		}
+		backend.Commit()
+		return tx
+	}
+
+	t.Run("forwarder payment", func(t *testing.T) {
+		before, _ := backend.BalanceAt(context.Background(), channelAddress, nil)
+		tx := pay(forwarder, channelAddress, 2)
+
+		res, err := service.ValidateTransaction(tx.Hash().String(), channelAddress)
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		if res.Amount != 2 || res.PaymentAddress != channelAddress.String() || res.ExchangeRate != 100 {
+			t.Fatalf("expecting 2 ETH to the channel, got %+v", res)
+		}
+
+		after, _ := backend.BalanceAt(context.Background(), channelAddress, nil)
+		if new(big.Int).Sub(after, before).Cmp(ether(2)) != 0 {
+			t.Fatalf("expecting the forwarder to send 2 ETH to the channel, got %v", new(big.Int).Sub(after, before))
+		}
+	})
+
+	t.Run("forwarder payment to another channel", func(t *testing.T) {
+		tx := pay(forwarder, helper.Accounts["carol"].Address, 1)
+		_, err := service.ValidateTransaction(tx.Hash().String(), channelAddress)
+		if err != payments.ErrorInvalidRecipient {
+			t.Fatalf("expecting ErrorInvalidRecipient, got %v", err)
+		}
+	})
+
+	t.Run("payment through an unknown forwarder", func(t *testing.T) {
+		tx := pay(otherForwarder, channelAddress, 1)
+		_, err := service.ValidateTransaction(tx.Hash().String(), channelAddress)
+		if err != payments.ErrorInvalidRecipient {
+			t.Fatalf("expecting ErrorInvalidRecipient, got %v", err)
+		}
+	})
+
+	t.Run("traced internal transfer", func(t *testing.T) {
+		// the wallet is sent ETH, and the trace shows it sending part of it on to the channel
+		tx := types.NewTransaction(0, wallet.Address, ether(1), 21000, big.NewInt(1), nil)
+		tx, err := helper.Accounts["dan"].Auth.Signer(types.HomesteadSigner{}, helper.Accounts["dan"].Address, tx)
+		if err != nil {
+			t.Fatalf("error signing transaction: %v", err)
+		}
+		if err = backend.SendTransaction(context.Background(), tx); err != nil {
+			t.Fatalf("error sending transaction: %v", err)
+		}
+		backend.Commit()
+
+		tracer[tx.Hash()] = []payments.InternalTransfer{
+			{From: helper.Accounts["dan"].Address, To: wallet.Address, Value: ether(1)},
+			{From: wallet.Address, To: channelAddress, Value: ether(0.25)},
+			{From: wallet.Address, To: channelAddress, Value: ether(0.25)},
+		}
+		res, err := service.ValidateTransaction(tx.Hash().String(), channelAddress)
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		if res.Amount != 0.5 {
+			t.Fatalf("expecting 0.5 ETH from the internal transfers, got %v", res.Amount)
+		}
+	})
+}
+
+type mockDebugAPI struct{}
+
+func (m *mockDebugAPI) TraceTransaction(ctx context.Context, hash common.Hash, config map[string]string) (json.RawMessage, error) {
+	return json.RawMessage(`{
+		"type": "CALL", "from": "0x00000000000000000000000000000000000000aa", "to": "0x00000000000000000000000000000000000000bb", "value": "0x0",
+		"calls": [
+			{"type": "CALL", "from": "0x00000000000000000000000000000000000000bb", "to": "0x00000000000000000000000000000000000000cc", "value": "0xde0b6b3a7640000"},
+			{"type": "DELEGATECALL", "from": "0x00000000000000000000000000000000000000bb", "to": "0x00000000000000000000000000000000000000dd", "value": "0xde0b6b3a7640000"},
+			{"type": "CALL", "from": "0x00000000000000000000000000000000000000bb", "to": "0x00000000000000000000000000000000000000ee", "value": "0x1", "error": "execution reverted",
+				"calls": [{"type": "CALL", "from": "0x00000000000000000000000000000000000000ee", "to": "0x00000000000000000000000000000000000000cc", "value": "0x1"}]}
+		]
+	}`), nil
+}
+
+func TestCallTracer(t *testing.T) {
+	server := rpc.NewServer()
+	if err := server.RegisterName("debug", &mockDebugAPI{}); err != nil {
+		t.Fatalf("error registering debug api: %v", err)
+	}
+	tracer := payments.NewCallTracer(rpc.DialInProc(server))
+
+	transfers, err := tracer.InternalTransfers(context.Background(), common.HexToHash("0x1"))
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+	if len(transfers) != 1 || transfers[0].To != common.HexToAddress("0xcc") || transfers[0].Value.Cmp(ether(1)) != 0 {
+		t.Fatalf("expecting one transfer of 1 ETH, got %+v", transfers)
+	}
+}
@@ -7,7 +7,8 @@ import (
 // PaymentModule is an fx Module
 var PaymentModule = fx.Options(
 	fx.Provide(
-		NewEthereumPaymentService,
+		NewEthereumPaymentServiceFromConfig,
+		NewTokenPaymentServiceFromConfig,
 		NewService,
 		NewStripeServiceFromConfig,
 	),
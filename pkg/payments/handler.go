@@ -69,6 +69,68 @@ func (s *Service) WebhookRouting(router chi.Router, stripeWebhookSigningSecret s
 				w.WriteHeader(http.StatusBadRequest)
 				return
 			}
+		} else if event.Type == "charge.refunded" {
+			var charge stripe.Charge
+			err := json.Unmarshal(event.Data.Raw, &charge)
+			if err != nil {
+				log.Errorf("Error parsing webhook JSON: %v\n", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			err = s.RefundStripeCharge(charge)
+			if err == ErrNoPaymentWithGivenChargeFound {
+				log.Errorf("Payment not found for refunded charge: %s\n", charge.ID)
+				w.WriteHeader(http.StatusOK)
+				return
+			} else if err != nil {
+				log.Errorf("Error updating refunded payment: %v\n", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		} else if event.Type == "invoice.paid" || event.Type == "invoice.payment_failed" {
+			var invoice stripe.Invoice
+			err := json.Unmarshal(event.Data.Raw, &invoice)
+			if err != nil {
+				log.Errorf("Error parsing webhook JSON: %v\n", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			if event.Type == "invoice.paid" {
+				err = s.RecordStripeInvoicePayment(invoice)
+			} else {
+				err = s.FailStripeInvoicePayment(invoice)
+			}
+			if err == ErrNoSubscriptionWithGivenReferenceFound {
+				// invoices that aren't for subscriptions, or for subscriptions not made through civil
+				log.Infof("Subscription not found for invoice: %s\n", invoice.ID)
+				w.WriteHeader(http.StatusOK)
+				return
+			} else if err != nil {
+				log.Errorf("Error updating subscription invoice: %v\n", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		} else if event.Type == "customer.subscription.deleted" {
+			var subscription stripe.Subscription
+			err := json.Unmarshal(event.Data.Raw, &subscription)
+			if err != nil {
+				log.Errorf("Error parsing webhook JSON: %v\n", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			err = s.EndStripeSubscription(subscription.ID)
+			if err == ErrNoSubscriptionWithGivenReferenceFound {
+				log.Errorf("Subscription not found for deleted subscription: %s\n", subscription.ID)
+				w.WriteHeader(http.StatusOK)
+				return
+			} else if err != nil {
+				log.Errorf("Error updating deleted subscription: %v\n", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
 		}
 		w.WriteHeader(http.StatusOK)
 	})
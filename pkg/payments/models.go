@@ -34,6 +34,7 @@ type PaymentModel struct {
 	Status          string  `gorm:"not null"`
 	CurrencyCode    string  `gorm:"not null"`
 	Amount          float64 `gorm:"not null"`
+	RefundedAmount  float64 `gorm:"not null;default:0"`
 	ExchangeRate    float64 `gorm:"not null"`
 	Comment         string
 	Reaction        string
@@ -49,6 +50,7 @@ type PaymentModel struct {
 	PaymentMethodID string
 	CustomerID      string
 	PaymentIntentID string `gorm:"index:idx_payment_intent_id"`
+	SubscriptionID  string `gorm:"index:idx_payment_subscription_id"`
 }
 
 // TableName returns the gorm table name for Base
@@ -85,6 +87,32 @@ func ModelToInterface(model *PaymentModel) (Payment, error) {
 	return payment.(Payment), nil
 }
 
+// StripeSubscription is a monthly payment from a supporter's channel to a channel,
+// billed by Stripe on the channel's connected account
+type StripeSubscription struct {
+	ID              string    `gorm:"type:uuid;primary_key"`
+	CreatedAt       time.Time `gorm:"not null"`
+	UpdatedAt       time.Time `gorm:"not null"`
+	DeletedAt       *time.Time
+	Reference       string  `gorm:"not null;unique_index:subscriptions_idx_reference"` // stripe subscription ID
+	Status          string  `gorm:"not null"`
+	CurrencyCode    string  `gorm:"not null"`
+	Amount          float64 `gorm:"not null"`
+	OwnerChannelID  string  `gorm:"not null;index:subscriptions_idx_owner_channel_id"`
+	PayerChannelID  string  `gorm:"not null;index:subscriptions_idx_payer_channel_id"`
+	EmailAddress    string
+	ShouldPublicize bool
+	PaymentMethodID string
+	StripeAccount   string `gorm:"not null"`
+	CustomerID      string // customer on the connected account
+	CanceledAt      *time.Time
+}
+
+// TableName returns the gorm table name for StripeSubscription
+func (StripeSubscription) TableName() string {
+	return "subscriptions"
+}
+
 // StripePayment is a payment that is created by Stripe
 type StripePayment struct {
 	PaymentModel `json:"-"`
@@ -109,6 +137,10 @@ type EtherPayment struct {
 	FromAddress    string `gorm:"-"`
 	EthAmount      string `gorm:"-"`
 	UsdAmount      string `gorm:"-"`
+
+	// the block the transaction was mined in, while the payment is confirming
+	BlockHash     string `gorm:"-"`
+	Confirmations int    `gorm:"-"`
 }
 
 // Type is the type of payment for EtherPayment
@@ -118,10 +150,15 @@ func (p EtherPayment) Type() string {
 
 // TokenPayment is a payment using an ERC20 token
 type TokenPayment struct {
-	PaymentModel  `json:"-"`
-	TransactionID string
-	TokenAddress  string
-	EmailAddress  string
+	PaymentModel   `json:"-"`
+	TransactionID  string
+	TokenAddress   string
+	EmailAddress   string
+	PaymentAddress string
+
+	// the block the transaction was mined in, while the payment is confirming
+	BlockHash     string
+	Confirmations int
 }
 
 // Type is the type of payment for TokenPayment
@@ -0,0 +1,150 @@
+// +build integration
+
+package payments_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jinzhu/gorm/dialects/postgres"
+	"github.com/joincivil/civil-api-server/pkg/payments"
+	"github.com/joincivil/civil-api-server/pkg/testruntime"
+	"github.com/joincivil/civil-api-server/pkg/testutils"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stripe/stripe-go"
+)
+
+func TestRefunds(t *testing.T) {
+	db, err := testutils.GetTestDBConnection()
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	err = testruntime.RunMigrations(db)
+	if err != nil {
+		t.Fatalf("error running migrations: %v", err)
+	}
+	paymentHelper := testruntime.NewMockPaymentHelper(testruntime.NewMockTransactionReader())
+	paymentService := payments.NewService(db, paymentHelper, nil, nil, paymentHelper, nil)
+
+	postID := uuid.NewV4().String()
+	createPayment := func(paymentType string, status string, amount float64) *payments.PaymentModel {
+		payment := &payments.PaymentModel{
+			ID:            uuid.NewV4().String(),
+			PaymentType:   paymentType,
+			Reference:     "pi_" + uuid.NewV4().String(),
+			Status:        status,
+			CurrencyCode:  "USD",
+			Amount:        amount,
+			ExchangeRate:  1,
+			OwnerID:       postID,
+			OwnerType:     "posts",
+			OwnerPostType: "boost",
+			Data:          postgres.Jsonb{RawMessage: json.RawMessage("{}")},
+		}
+		if err := db.Create(payment).Error; err != nil {
+			t.Fatalf("error creating payment: %v", err)
+		}
+		return payment
+	}
+	amount := func(value float64) *float64 {
+		return &value
+	}
+
+	t.Run("partial and full refunds", func(t *testing.T) {
+		payment := createPayment(payments.PaymentTypeStripe, "complete", 50)
+
+		refunded, err := paymentService.RefundStripePayment(payment.ID, amount(20))
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		if refunded.Status != "partially_refunded" || refunded.RefundedAmount != 20 {
+			t.Fatalf("expecting a partial refund of 20, got %v %v", refunded.Status, refunded.RefundedAmount)
+		}
+
+		_, err = paymentService.RefundStripePayment(payment.ID, amount(30.01))
+		if err != payments.ErrInvalidRefundAmount {
+			t.Fatalf("expecting ErrInvalidRefundAmount refunding more than the payment, got %v", err)
+		}
+
+		refunded, err = paymentService.RefundStripePayment(payment.ID, nil)
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		if refunded.Status != "refunded" || refunded.RefundedAmount != 50 {
+			t.Fatalf("expecting the payment to be fully refunded, got %v %v", refunded.Status, refunded.RefundedAmount)
+		}
+
+		_, err = paymentService.RefundStripePayment(payment.ID, nil)
+		if err != payments.ErrPaymentNotRefundable {
+			t.Fatalf("expecting ErrPaymentNotRefundable for a refunded payment, got %v", err)
+		}
+	})
+
+	t.Run("only complete stripe payments", func(t *testing.T) {
+		for _, payment := range []*payments.PaymentModel{
+			createPayment(payments.PaymentTypeStripe, "pending", 10),
+			createPayment(payments.PaymentTypeEther, "complete", 10),
+		} {
+			_, err := paymentService.RefundStripePayment(payment.ID, nil)
+			if err != payments.ErrPaymentNotRefundable {
+				t.Fatalf("expecting ErrPaymentNotRefundable, got %v", err)
+			}
+		}
+
+		payment := createPayment(payments.PaymentTypeStripe, "complete", 10)
+		_, err := paymentService.RefundStripePayment(payment.ID, amount(-1))
+		if err != payments.ErrInvalidRefundAmount {
+			t.Fatalf("expecting ErrInvalidRefundAmount for a negative refund, got %v", err)
+		}
+	})
+
+	t.Run("charge refunded webhook", func(t *testing.T) {
+		payment := createPayment(payments.PaymentTypeStripe, "complete", 40)
+
+		err := paymentService.RefundStripeCharge(stripe.Charge{ID: "ch_test", PaymentIntent: payment.Reference, AmountRefunded: 1000})
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		// an event for an earlier refund arriving late is ignored
+		err = paymentService.RefundStripeCharge(stripe.Charge{ID: "ch_test", PaymentIntent: payment.Reference, AmountRefunded: 500})
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+
+		retrieved, err := paymentService.GetPayment(payment.ID)
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		model := retrieved.(*payments.StripePayment).PaymentModel
+		if model.Status != "partially_refunded" || model.RefundedAmount != 10 {
+			t.Fatalf("expecting a partial refund of 10, got %v %v", model.Status, model.RefundedAmount)
+		}
+
+		err = paymentService.RefundStripeCharge(stripe.Charge{ID: "ch_unknown", AmountRefunded: 1000})
+		if err != payments.ErrNoPaymentWithGivenChargeFound {
+			t.Fatalf("expecting ErrNoPaymentWithGivenChargeFound, got %v", err)
+		}
+	})
+
+	t.Run("totals subtract refunds", func(t *testing.T) {
+		postID = uuid.NewV4().String()
+		createPayment(payments.PaymentTypeStripe, "complete", 30)
+		partial := createPayment(payments.PaymentTypeStripe, "complete", 20)
+		full := createPayment(payments.PaymentTypeStripe, "complete", 10)
+		if _, err := paymentService.RefundStripePayment(partial.ID, amount(5)); err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		if _, err := paymentService.RefundStripePayment(full.ID, nil); err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+
+		// 30 + 20 + 10 paid, 5 + 10 refunded
+		total, err := paymentService.TotalPayments(postID, "USD")
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		if total != 45 {
+			t.Fatalf("expecting a total of 45, got %v", total)
+		}
+	})
+}
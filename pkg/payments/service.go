@@ -40,12 +40,22 @@ const (
 	postTypeBoost        = "boost"
 	postTypeExternalLink = "externallink"
 
-	paymentComplete = "complete"
+	paymentPending           = "pending"
+	paymentConfirming        = "confirming"
+	paymentComplete          = "complete"
+	paymentRefunded          = "refunded"
+	paymentPartiallyRefunded = "partially_refunded"
 )
 
 var (
 	// ErrNoPaymentWithGivenPaymentIntentIDFound returned when payment not found in DB for given payment intent ID
 	ErrNoPaymentWithGivenPaymentIntentIDFound = errors.New("no payment found for given payment intent ID")
+	// ErrNoPaymentWithGivenChargeFound returned when payment not found in DB for a refunded charge
+	ErrNoPaymentWithGivenChargeFound = errors.New("no payment found for given charge")
+	// ErrPaymentNotRefundable returned when refunding a payment that isn't a complete Stripe payment
+	ErrPaymentNotRefundable = errors.New("only complete stripe payments can be refunded")
+	// ErrInvalidRefundAmount returned when a refund is negative or more than the amount left to refund
+	ErrInvalidRefundAmount = errors.New("refund amount must be positive and no more than the amount not yet refunded")
 )
 
 // StripeCharger defines the functions needed to create a charge with Stripe
@@ -57,6 +67,11 @@ type StripeCharger interface {
 	CreateStripePaymentIntent(request CreatePaymentIntentRequest) (StripePaymentIntent, error)
 	ClonePaymentMethod(request ClonePaymentMethodRequest) (ClonePaymentMethodResponse, error)
 	RemovePaymentMethod(paymentMethodID string) error
+	CreateRefund(request CreateRefundRequest) (CreateRefundResponse, error)
+	CreateSubscription(request CreateSubscriptionRequest) (CreateSubscriptionResponse, error)
+	PauseSubscription(request SubscriptionRequest) error
+	ResumeSubscription(request SubscriptionRequest) error
+	CancelSubscription(request SubscriptionRequest) error
 }
 
 // EthereumValidator defines the functions needed to create an Ethereum payment
@@ -64,6 +79,12 @@ type EthereumValidator interface {
 	ValidateTransaction(transactionID string, expectedAccount common.Address) (*ValidateTransactionResponse, error)
 }
 
+// TokenValidator defines the functions needed to create an ERC-20 token payment
+type TokenValidator interface {
+	GetToken(tokenAddress common.Address) (Token, error)
+	ValidateTokenTransaction(transactionID string, tokenAddress common.Address, expectedReceiver common.Address) (*ValidateTokenTransactionResponse, error)
+}
+
 // ChannelHelper defines the methods needed to interact with a channel
 type ChannelHelper interface {
 	GetEthereumPaymentAddress(channelID string) (common.Address, error)
@@ -78,16 +99,18 @@ type Service struct {
 	db       *gorm.DB
 	stripe   StripeCharger
 	ethereum EthereumValidator
+	tokens   TokenValidator
 	channel  ChannelHelper
 	emailer  *email.Emailer
 }
 
 // NewService builds an instance of posts.Service
-func NewService(db *gorm.DB, stripe StripeCharger, ethereum EthereumValidator, channel ChannelHelper, emailer *email.Emailer) *Service {
+func NewService(db *gorm.DB, stripe StripeCharger, ethereum EthereumValidator, tokens TokenValidator, channel ChannelHelper, emailer *email.Emailer) *Service {
 	return &Service{
 		db,
 		stripe,
 		ethereum,
+		tokens,
 		channel,
 		emailer,
 	}
@@ -105,41 +128,44 @@ func getTemplateRequest(templateID string, emailAddress string, tmplData email.T
 	}
 }
 
-// GetChannelTotalProceeds gets total proceeds for the channel, broken out by payment type
+// GetChannelTotalProceeds gets total proceeds for the channel less refunds, broken out by payment type.
+// Payments to the channel itself, such as subscriptions, are in the `subscription` type
 func (s *Service) GetChannelTotalProceeds(channelID string) *ProceedsQueryResult {
 	var result ProceedsQueryResult
 	s.db.Raw(fmt.Sprintf(`
 	SELECT 
-	posts.post_type, 
-	sum(amount * exchange_rate) as total_amount, 
-	sum(amount * exchange_rate ) FILTER (WHERE LOWER(p.currency_code) = 'usd') as usd, 
-	sum(amount * exchange_rate) FILTER (WHERE p.currency_code = 'ETH') as eth_usd_amount, 
-	sum(amount) FILTER (WHERE p.currency_code = 'ETH')  as ether 
+	coalesce(posts.post_type, '%v') as post_type, 
+	sum((amount - refunded_amount) * exchange_rate) as total_amount, 
+	sum((amount - refunded_amount) * exchange_rate ) FILTER (WHERE LOWER(p.currency_code) = 'usd') as usd, 
+	sum((amount - refunded_amount) * exchange_rate) FILTER (WHERE p.currency_code = 'ETH') as eth_usd_amount, 
+	sum(amount - refunded_amount) FILTER (WHERE p.currency_code = 'ETH')  as ether 
 	from payments p 
-	inner join posts 
-	on p.owner_id::uuid = posts.id and p.owner_type = 'posts' 
-	where posts.channel_id = ? 
-	group by post_type 
-	order by post_type;`), channelID).Scan(&result)
+	left join posts 
+	on p.owner_type = 'posts' and p.owner_id::uuid = posts.id 
+	where posts.channel_id = ? or (p.owner_type = '%v' and p.owner_channel_id = ?) 
+	group by 1 
+	order by 1;`, proceedsTypeSubscription, ownerTypeChannel), channelID, channelID).Scan(&result)
 	return &result
 }
 
-// GetChannelTotalProceedsByBoostType gets total proceeds for the channel, broken out by payment type
+// GetChannelTotalProceedsByBoostType gets total proceeds for the channel, broken out by payment type.
+// Use the `subscription` type for payments to the channel itself
 func (s *Service) GetChannelTotalProceedsByBoostType(channelID string, boostType string) *ProceedsQueryResult {
 	var result ProceedsQueryResult
 	s.db.Raw(fmt.Sprintf(`
 	SELECT 
-	posts.post_type, 
-	sum(amount * exchange_rate) as total_amount, 
-	sum(amount * exchange_rate ) FILTER (WHERE LOWER(p.currency_code) = 'usd') as usd, 
-	sum(amount * exchange_rate) FILTER (WHERE p.currency_code = 'ETH') as eth_usd_amount, 
-	sum(amount) FILTER (WHERE p.currency_code = 'ETH')  as ether 
+	coalesce(posts.post_type, '%v') as post_type, 
+	sum((amount - refunded_amount) * exchange_rate) as total_amount, 
+	sum((amount - refunded_amount) * exchange_rate ) FILTER (WHERE LOWER(p.currency_code) = 'usd') as usd, 
+	sum((amount - refunded_amount) * exchange_rate) FILTER (WHERE p.currency_code = 'ETH') as eth_usd_amount, 
+	sum(amount - refunded_amount) FILTER (WHERE p.currency_code = 'ETH')  as ether 
 	from payments p 
-	inner join posts 
-	on p.owner_id::uuid = posts.id and p.owner_type = 'posts' and p.owner_post_type = ?
-	where posts.channel_id = ?
-	group by post_type 
-	order by post_type;`), boostType, channelID).Scan(&result)
+	left join posts 
+	on p.owner_type = 'posts' and p.owner_id::uuid = posts.id 
+	where (posts.channel_id = ? and p.owner_post_type = ?) or 
+	(p.owner_type = '%v' and p.owner_channel_id = ? and ? = '%v') 
+	group by 1 
+	order by 1;`, proceedsTypeSubscription, ownerTypeChannel, proceedsTypeSubscription), channelID, boostType, channelID, boostType).Scan(&result)
 	return &result
 }
 
@@ -244,11 +270,11 @@ func (s *Service) CreateEtherPayment(ownerChannelID string, ownerType string, ow
 	}, nil
 }
 
-// GetPendingEtherPayments gets all pending ether payments
+// GetPendingEtherPayments gets all pending and confirming ether payments
 func (s *Service) GetPendingEtherPayments() ([]PaymentModel, error) {
 
 	var payments []PaymentModel
-	if err := s.db.Where("status = 'pending' AND payment_type = 'ether'").Find(&payments).Error; err != nil {
+	if err := s.db.Where("status IN (?) AND payment_type = 'ether'", []string{paymentPending, paymentConfirming}).Find(&payments).Error; err != nil {
 		return nil, err
 	}
 
@@ -290,7 +316,13 @@ func (s *Service) UpdateEtherPayment(payment *PaymentModel) error {
 	expectedReceiver := common.HexToAddress(etherPayment.PaymentAddress)
 	res, err := s.ethereum.ValidateTransaction(payment.Reference, expectedReceiver)
 	var err2 error
-	if err == ErrorTransactionFailed {
+	if etherPayment.BlockHash != "" && isReorged(etherPayment.BlockHash, res, err) {
+		// the block the transaction was mined in is no longer part of the chain,
+		// so start over and wait for the transaction to be mined again
+		log.Infof("Ether payment %v reorged out of block %v\n", payment.ID, etherPayment.BlockHash)
+		update.Status = paymentPending
+		update.Data = postgres.Jsonb{RawMessage: json.RawMessage(fmt.Sprintf("{\"PaymentAddress\":\"%v\"}", etherPayment.PaymentAddress))}
+	} else if err == ErrorTransactionFailed {
 		update.Status = "failed"
 	} else if err == ErrorReceiptNotFound || err == ErrorTransactionNotFound {
 		return nil
@@ -307,7 +339,10 @@ func (s *Service) UpdateEtherPayment(payment *PaymentModel) error {
 			return err
 		}
 
-		if res.Amount != 0 {
+		if !res.Confirmed {
+			update.Status = paymentConfirming
+			update.Data = postgres.Jsonb{RawMessage: data}
+		} else if res.Amount != 0 {
 			update.Status = paymentComplete
 			update.Data = postgres.Jsonb{RawMessage: data}
 			update.ExchangeRate = res.ExchangeRate
@@ -334,7 +369,7 @@ func (s *Service) UpdateEtherPayment(payment *PaymentModel) error {
 				}
 			}
 
-			err := s.sendETHPaymentReceivedEmails(payment, res.Amount*res.ExchangeRate)
+			err := s.sendPaymentReceivedEmails(payment, res.Amount*res.ExchangeRate, "ETH")
 			if err != nil {
 				log.Errorf("Error sending boost payment received email: %v\n", err)
 			}
@@ -354,12 +389,20 @@ func (s *Service) UpdateEtherPayment(payment *PaymentModel) error {
 	return nil
 }
 
-func (s *Service) sendETHPaymentReceivedEmails(payment *PaymentModel, amount float64) error {
+// isReorged returns true when the receipt of a transaction has vanished or moved out of the block it was mined in
+func isReorged(blockHash string, res *ValidateTransactionResponse, err error) bool {
+	if err == ErrorReceiptNotFound || err == ErrorTransactionNotFound {
+		return true
+	}
+	return err == nil && res.BlockHash != blockHash
+}
+
+func (s *Service) sendPaymentReceivedEmails(payment *PaymentModel, amount float64, paymentType string) error {
 	channelAdminChannels, err := s.channel.GetChannelAdminUserChannels(payment.OwnerChannelID)
 	if err != nil {
 		return err
 	}
-	receivedTmplData, err := s.getPaymentReceivedEmailTemplateData(amount, *payment, "ETH")
+	receivedTmplData, err := s.getPaymentReceivedEmailTemplateData(amount, *payment, paymentType)
 	if err != nil {
 		log.Errorf("Error getting email template data for payment received: %v\n", err)
 	}
@@ -382,6 +425,183 @@ func (s *Service) sendETHPaymentReceivedEmails(payment *PaymentModel, amount flo
 	return nil
 }
 
+// CreateTokenPayment checks the token is accepted and stores the transaction as a pending Payment in the database,
+// it is confirmed by UpdateTokenPayments
+func (s *Service) CreateTokenPayment(ownerChannelID string, ownerType string, ownerPostType string, ownerID string, ownerTitle string, tokenPayment TokenPayment) (TokenPayment, error) {
+	hash := common.HexToHash(tokenPayment.TransactionID)
+	if (hash == common.Hash{}) {
+		return TokenPayment{}, errors.New("invalid tx id")
+	}
+	if !common.IsHexAddress(tokenPayment.TokenAddress) {
+		return TokenPayment{}, ErrorTokenNotAllowed
+	}
+	token, err := s.tokens.GetToken(common.HexToAddress(tokenPayment.TokenAddress))
+	if err != nil {
+		return TokenPayment{}, err
+	}
+
+	expectedAddress, err := s.channel.GetEthereumPaymentAddress(ownerChannelID)
+	if err != nil {
+		return TokenPayment{}, err
+	}
+
+	data, err := tokenPaymentData(tokenPayment.TransactionID, token.Address.String(), expectedAddress.String())
+	if err != nil {
+		return TokenPayment{}, err
+	}
+
+	payment := PaymentModel{}
+	// generate a new ID
+	id := uuid.NewV4()
+
+	payment.ID = id.String()
+	payment.PaymentType = PaymentTypeToken
+	payment.Reference = tokenPayment.TransactionID
+
+	payment.Status = "pending"
+	payment.OwnerID = ownerID
+	payment.OwnerType = ownerType
+	payment.OwnerPostType = ownerPostType
+	payment.OwnerChannelID = ownerChannelID
+	payment.OwnerTitle = ownerTitle
+	payment.CurrencyCode = token.Symbol
+	payment.ExchangeRate = 0
+	payment.Amount = 0
+	payment.EmailAddress = tokenPayment.EmailAddress
+	payment.Reaction = tokenPayment.Reaction
+	payment.Comment = tokenPayment.Comment
+	payment.Data = data
+
+	payment.PayerChannelID = tokenPayment.PayerChannelID
+	payment.ShouldPublicize = tokenPayment.ShouldPublicize
+
+	if err = s.db.Create(&payment).Error; err != nil {
+		log.Errorf("An error occurred: %v\n", err)
+		return TokenPayment{}, err
+	}
+
+	return TokenPayment{
+		PaymentModel:   payment,
+		TransactionID:  tokenPayment.TransactionID,
+		TokenAddress:   token.Address.String(),
+		EmailAddress:   tokenPayment.EmailAddress,
+		PaymentAddress: expectedAddress.String(),
+	}, nil
+}
+
+// GetPendingTokenPayments gets all pending and confirming token payments
+func (s *Service) GetPendingTokenPayments() ([]PaymentModel, error) {
+
+	var payments []PaymentModel
+	if err := s.db.Where("status IN (?) AND payment_type = ?", []string{paymentPending, paymentConfirming}, PaymentTypeToken).Find(&payments).Error; err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+// UpdateTokenPayments finds pending token payments, checks the status, and updates them accordingly
+func (s *Service) UpdateTokenPayments() error {
+
+	payments, err := s.GetPendingTokenPayments()
+	if err != nil {
+		return err
+	}
+
+	for _, payment := range payments {
+		err := s.UpdateTokenPayment(&payment)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UpdateTokenPayment handles a single token payment and updates it if needed
+func (s *Service) UpdateTokenPayment(payment *PaymentModel) error {
+	// create a payment model to hold the updated fields
+	update := &PaymentModel{}
+
+	// convert to interface to unmarshal up the Data field
+	paymentInterface, err := ModelToInterface(payment)
+	if err != nil {
+		return err
+	}
+	tokenPayment := paymentInterface.(*TokenPayment)
+
+	// expectedReceiver should be the channel ETH address
+	expectedReceiver := common.HexToAddress(tokenPayment.PaymentAddress)
+	res, err := s.tokens.ValidateTokenTransaction(payment.Reference, common.HexToAddress(tokenPayment.TokenAddress), expectedReceiver)
+	var validated *ValidateTransactionResponse
+	if res != nil {
+		validated = &res.ValidateTransactionResponse
+	}
+	if tokenPayment.BlockHash != "" && isReorged(tokenPayment.BlockHash, validated, err) {
+		// the same reorgs as ether payments can undo a token transfer, so start over and wait for it to be mined again
+		log.Infof("Token payment %v reorged out of block %v\n", payment.ID, tokenPayment.BlockHash)
+		data, err := tokenPaymentData(tokenPayment.TransactionID, tokenPayment.TokenAddress, tokenPayment.PaymentAddress)
+		if err != nil {
+			return err
+		}
+		update.Status = paymentPending
+		update.Data = data
+	} else if err == ErrorTransactionFailed {
+		update.Status = "failed"
+	} else if err == ErrorReceiptNotFound || err == ErrorTransactionNotFound {
+		return nil
+	} else if err == ErrorNoTokenPrice {
+		// leave the payment pending until there is a price for the token
+		log.Errorf("No price to update token payment %v: %v\n", payment.ID, tokenPayment.TokenAddress)
+		return nil
+	} else if err == ErrorInvalidRecipient || err == ErrorTokenNotAllowed {
+		update.Status = "invalid"
+	} else if err != nil {
+		log.Errorf("Error updating payment: %v\n", err)
+		return err
+	} else {
+		data, err := json.Marshal(res)
+		if err != nil {
+			log.Errorf("Error updating payment: %v\n", err)
+			return err
+		}
+
+		if !res.Confirmed {
+			update.Status = paymentConfirming
+			update.Data = postgres.Jsonb{RawMessage: data}
+		} else {
+			update.Status = paymentComplete
+			update.Data = postgres.Jsonb{RawMessage: data}
+			update.ExchangeRate = res.ExchangeRate
+			update.Amount = res.Amount
+
+			err = s.sendPaymentReceivedEmails(payment, res.Amount*res.ExchangeRate, res.CurrencyCode)
+			if err != nil {
+				log.Errorf("Error sending boost payment received email: %v\n", err)
+			}
+		}
+	}
+
+	if err = s.db.Model(&payment).Update(update).Error; err != nil {
+		log.Errorf("Error updating payment: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// tokenPaymentData is the data of a token payment before its transaction is validated
+func tokenPaymentData(transactionID string, tokenAddress string, paymentAddress string) (postgres.Jsonb, error) {
+	data, err := json.Marshal(map[string]string{
+		"TransactionID":  transactionID,
+		"TokenAddress":   tokenAddress,
+		"PaymentAddress": paymentAddress,
+	})
+	if err != nil {
+		return postgres.Jsonb{}, err
+	}
+	return postgres.Jsonb{RawMessage: json.RawMessage(data)}, nil
+}
+
 // GetStripeCustomerInfo returns stripe customer info for display on client
 func (s *Service) GetStripeCustomerInfo(channelID string) (StripeCustomerInfo, error) {
 	customerID, err := s.channel.GetStripeCustomerID(channelID)
@@ -666,6 +886,88 @@ func (s *Service) FailStripePaymentIntent(paymentIntentID string) (bool, error)
 	return true, nil
 }
 
+// RefundStripePayment refunds `amount` of a complete Stripe payment, or everything not yet refunded if `amount` is nil.
+// Callers must check the user is allowed to refund the payment
+func (s *Service) RefundStripePayment(paymentID string, amount *float64) (*StripePayment, error) {
+	var payment PaymentModel
+	if err := s.db.Where(&PaymentModel{ID: paymentID}).First(&payment).Error; err != nil {
+		log.Errorf("Error getting payment: %v\n", err)
+		return nil, err
+	}
+	if payment.PaymentType != PaymentTypeStripe ||
+		(payment.Status != paymentComplete && payment.Status != paymentPartiallyRefunded) {
+		return nil, ErrPaymentNotRefundable
+	}
+
+	remaining := toCents(payment.Amount - payment.RefundedAmount)
+	refundAmount := remaining
+	if amount != nil {
+		refundAmount = toCents(*amount)
+	}
+	if refundAmount <= 0 || refundAmount > remaining {
+		return nil, ErrInvalidRefundAmount
+	}
+
+	stripeAccount, err := s.channel.GetStripePaymentAccount(payment.OwnerChannelID)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.stripe.CreateRefund(CreateRefundRequest{
+		Reference:     payment.Reference,
+		Amount:        refundAmount,
+		StripeAccount: stripeAccount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.setRefundedAmount(&payment, float64(res.AmountRefunded)/100.0); err != nil {
+		return nil, err
+	}
+	return &StripePayment{PaymentModel: payment}, nil
+}
+
+// RefundStripeCharge records the amount refunded from a charge after a charge.refunded webhook event received.
+// Refunds may be made from the Stripe dashboard as well as with RefundStripePayment
+func (s *Service) RefundStripeCharge(charge stripe.Charge) error {
+	// payments made with payment intents are referenced by the payment intent rather than the charge
+	reference := charge.ID
+	if charge.PaymentIntent != "" {
+		reference = charge.PaymentIntent
+	}
+
+	var payment PaymentModel
+	if err := s.db.Where("payment_type = ? AND reference = ?", PaymentTypeStripe, reference).First(&payment).Error; err != nil {
+		log.Errorf("Error getting payment: %v\n", err)
+		return ErrNoPaymentWithGivenChargeFound
+	}
+
+	return s.setRefundedAmount(&payment, float64(charge.AmountRefunded)/100.0)
+}
+
+// setRefundedAmount sets the total amount refunded from a payment and its status. Stripe reports the total
+// refunded, so events that arrive late or more than once don't reduce the amount already recorded
+func (s *Service) setRefundedAmount(payment *PaymentModel, refundedAmount float64) error {
+	if toCents(refundedAmount) <= toCents(payment.RefundedAmount) {
+		return nil
+	}
+
+	status := paymentPartiallyRefunded
+	if toCents(refundedAmount) >= toCents(payment.Amount) {
+		status = paymentRefunded
+	}
+	update := map[string]interface{}{"refunded_amount": refundedAmount, "status": status}
+	if err := s.db.Model(payment).Updates(update).Error; err != nil {
+		log.Errorf("Error updating payment: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
 // CreateStripePaymentIntent creates a stripe payment intent and "unconfirmed" payment in DB and returns payment intent
 func (s *Service) CreateStripePaymentIntent(ownerChannelID string, ownerType string, postType string, ownerID string, newsroomName string, boostTitle string, payment StripePayment) (StripePaymentIntent, error) {
 	stripeAccount, err := s.channel.GetStripePaymentAccount(ownerChannelID)
@@ -721,7 +1023,7 @@ func (s *Service) CreateStripePaymentIntent(ownerChannelID string, ownerType str
 // so should only be called after checking user is authorized to view this data
 func (s *Service) GetPaymentsByPayerChannel(channelID string) ([]Payment, error) {
 	var pays []PaymentModel
-	if err := s.db.Where(&PaymentModel{OwnerType: "posts", PayerChannelID: channelID}).Find(&pays).Error; err != nil {
+	if err := s.db.Where("payer_channel_id = ? AND owner_type IN (?)", channelID, []string{"posts", ownerTypeChannel}).Find(&pays).Error; err != nil {
 		log.Errorf("An error occurred: %v\n", err)
 		return nil, err
 	}
@@ -763,6 +1065,21 @@ func (s *Service) GetPayments(postID string) ([]Payment, error) {
 	return paymentsSlice, nil
 }
 
+// GetSupporterEmailAddresses returns the distinct email addresses given with completed payments to a Post, including partially refunded ones
+func (s *Service) GetSupporterEmailAddresses(postID string) ([]string, error) {
+	var addresses []string
+	err := s.db.Model(&PaymentModel{}).
+		Where(&PaymentModel{OwnerType: "posts", OwnerID: postID}).
+		Where("status IN (?)", []string{paymentComplete, paymentPartiallyRefunded}).
+		Where("email_address <> ''").
+		Pluck("DISTINCT email_address", &addresses).Error
+	if err != nil {
+		log.Errorf("An error occurred: %v\n", err)
+		return nil, err
+	}
+	return addresses, nil
+}
+
 // GetGroupedSanitizedPayments returns the payments associated with a Post, grouped by channelID if payment should be publicized
 func (s *Service) GetGroupedSanitizedPayments(postID string) ([]*SanitizedPayment, error) {
 	var pays []SanitizedPayment
@@ -772,19 +1089,19 @@ func (s *Service) GetGroupedSanitizedPayments(postID string) ([]*SanitizedPaymen
 		SELECT * FROM(
 
 			SELECT * FROM(
-				SELECT SUM(amount * exchange_rate) as usd_equivalent,
+				SELECT SUM((amount - refunded_amount) * exchange_rate) as usd_equivalent,
 					max(created_at) as most_recent_update,  
 					payer_channel_id
-				FROM payments WHERE owner_id = '%s' AND status = 'complete' AND should_publicize = true GROUP BY payer_channel_id
+				FROM payments WHERE owner_id = '%s' AND status IN ('complete', 'partially_refunded') AND should_publicize = true GROUP BY payer_channel_id
 			) publicized_group
 
 			UNION
 
 			SELECT * FROM( 
-				SELECT (amount * exchange_rate) as usd_equivalent,
+				SELECT ((amount - refunded_amount) * exchange_rate) as usd_equivalent,
 					created_at as most_recent_update, 
 					'' as payer_channel_id
-				FROM payments WHERE owner_id = '%s' AND status = 'complete' AND should_publicize = false
+				FROM payments WHERE owner_id = '%s' AND status IN ('complete', 'partially_refunded') AND should_publicize = false
 			) unpublicized_ungroup
 
 		) data 
@@ -837,13 +1154,13 @@ func (s *Service) GetPaymentByReference(reference string) (Payment, error) {
 	return ModelToInterface(paymentModel)
 }
 
-// TotalPayments returns the USD equivalent of all payments associated with the post
+// TotalPayments returns the USD equivalent of all payments associated with the post, less refunds
 func (s *Service) TotalPayments(postID string, currencyCode string) (float64, error) {
 	if currencyCode != "USD" {
 		return 0, errors.New("USD is the only `currencyCode` supported")
 	}
 	var totals []float64
-	s.db.Table("payments").Where(&PaymentModel{OwnerType: "posts", OwnerID: postID}).Select("coalesce(sum(amount * exchange_rate), 0) as total").Pluck("total", &totals)
+	s.db.Table("payments").Where(&PaymentModel{OwnerType: "posts", OwnerID: postID}).Select("coalesce(sum((amount - refunded_amount) * exchange_rate), 0) as total").Pluck("total", &totals)
 
 	return totals[0], nil
 }
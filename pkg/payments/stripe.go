@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -17,9 +18,16 @@ import (
 	"github.com/stripe/stripe-go/customer"
 	"github.com/stripe/stripe-go/paymentintent"
 	"github.com/stripe/stripe-go/paymentmethod"
+	"github.com/stripe/stripe-go/plan"
+	"github.com/stripe/stripe-go/refund"
+	"github.com/stripe/stripe-go/sub"
 )
 
-const stripeOAuthURI = "https://connect.stripe.com/oauth/token"
+const (
+	stripeOAuthURI = "https://connect.stripe.com/oauth/token"
+
+	paymentIntentIDPrefix = "pi_"
+)
 
 // StripeService provides methods to interact with the Stripe payment provider
 type StripeService struct {
@@ -88,6 +96,50 @@ type CreatePaymentIntentRequest struct {
 	Metadata        map[string]string
 }
 
+// CreateRefundRequest contains the data needed to refund a charge or payment intent
+type CreateRefundRequest struct {
+	// Reference is the ID of the charge or payment intent to refund
+	Reference string
+	// Amount is the amount to refund in cents, or 0 to refund the remainder of the charge
+	Amount        int64
+	StripeAccount string
+}
+
+// CreateRefundResponse contains the result of a refund
+type CreateRefundResponse struct {
+	ID string
+	// AmountRefunded is the total amount refunded from the charge in cents, including earlier refunds
+	AmountRefunded int64
+}
+
+// CreateSubscriptionRequest contains the data needed to create a monthly subscription on a connected account
+type CreateSubscriptionRequest struct {
+	// Amount is the monthly amount in cents
+	Amount int64
+	// CustomerID is the platform customer the payment method was saved to
+	CustomerID      string
+	PaymentMethodID string
+	Email           string
+	// Description is the name of the plan shown on invoices
+	Description   string
+	StripeAccount string
+	Metadata      map[string]string
+}
+
+// CreateSubscriptionResponse contains the result of creating a subscription
+type CreateSubscriptionResponse struct {
+	ID     string
+	Status string
+	// CustomerID is the customer created on the connected account
+	CustomerID string
+}
+
+// SubscriptionRequest contains the data needed to update a subscription on a connected account
+type SubscriptionRequest struct {
+	SubscriptionID string
+	StripeAccount  string
+}
+
 // NewStripeService constructs an instance of the stripe Service
 func NewStripeService(apiKey string, applePayDomains []string) *StripeService {
 	return &StripeService{
@@ -287,6 +339,162 @@ func (s *StripeService) CreateStripePaymentIntent(request CreatePaymentIntentReq
 	}, nil
 }
 
+// CreateRefund refunds all or part of a charge on a connected account
+func (s *StripeService) CreateRefund(request CreateRefundRequest) (CreateRefundResponse, error) {
+	stripe.Key = s.apiKey
+
+	chargeID := request.Reference
+	if strings.HasPrefix(request.Reference, paymentIntentIDPrefix) {
+		params := &stripe.PaymentIntentParams{}
+		params.SetStripeAccount(request.StripeAccount)
+		pi, err := paymentintent.Get(request.Reference, params)
+		if err != nil {
+			log.Errorf("error getting payment intent to refund: %v", err)
+			return CreateRefundResponse{}, err
+		}
+		if pi.Charges == nil || len(pi.Charges.Data) == 0 {
+			return CreateRefundResponse{}, errors.Errorf("payment intent %v has no charges to refund", pi.ID)
+		}
+		chargeID = pi.Charges.Data[0].ID
+	}
+
+	params := &stripe.RefundParams{
+		Charge: stripe.String(chargeID),
+	}
+	if request.Amount > 0 {
+		params.Amount = stripe.Int64(request.Amount)
+	}
+	params.SetStripeAccount(request.StripeAccount)
+	params.AddExpand("charge")
+
+	re, err := refund.New(params)
+	if err != nil {
+		log.Errorf("error creating refund: %v", err)
+		return CreateRefundResponse{}, err
+	}
+
+	amountRefunded := re.Amount
+	if re.Charge != nil {
+		amountRefunded = re.Charge.AmountRefunded
+	}
+
+	return CreateRefundResponse{
+		ID:             re.ID,
+		AmountRefunded: amountRefunded,
+	}, nil
+}
+
+// CreateSubscription creates a monthly subscription on a connected account. The payment method is cloned from
+// the platform customer to a new customer on the connected account, which is billed for the subscription
+func (s *StripeService) CreateSubscription(request CreateSubscriptionRequest) (CreateSubscriptionResponse, error) {
+	stripe.Key = s.apiKey
+
+	pm, err := s.ClonePaymentMethod(ClonePaymentMethodRequest{
+		CustomerID:      request.CustomerID,
+		PaymentMethodID: request.PaymentMethodID,
+		StripeAccountID: request.StripeAccount,
+	})
+	if err != nil {
+		return CreateSubscriptionResponse{}, err
+	}
+
+	customerParams := &stripe.CustomerParams{
+		PaymentMethod: stripe.String(pm.PaymentMethodID),
+		InvoiceSettings: &stripe.CustomerInvoiceSettingsParams{
+			DefaultPaymentMethod: stripe.String(pm.PaymentMethodID),
+		},
+	}
+	if request.Email != "" {
+		customerParams.Email = stripe.String(request.Email)
+	}
+	customerParams.SetStripeAccount(request.StripeAccount)
+	c, err := customer.New(customerParams)
+	if err != nil {
+		log.Errorf("error creating subscription customer: %v", err)
+		return CreateSubscriptionResponse{}, err
+	}
+
+	planParams := &stripe.PlanParams{
+		Amount:   stripe.Int64(request.Amount),
+		Currency: stripe.String(string(stripe.CurrencyUSD)),
+		Interval: stripe.String(string(stripe.PlanIntervalMonth)),
+		Product: &stripe.PlanProductParams{
+			Name: stripe.String(request.Description),
+		},
+	}
+	planParams.SetStripeAccount(request.StripeAccount)
+	p, err := plan.New(planParams)
+	if err != nil {
+		log.Errorf("error creating subscription plan: %v", err)
+		return CreateSubscriptionResponse{}, err
+	}
+
+	params := &stripe.SubscriptionParams{
+		Customer:             stripe.String(c.ID),
+		DefaultPaymentMethod: stripe.String(pm.PaymentMethodID),
+		Items: []*stripe.SubscriptionItemsParams{
+			{Plan: stripe.String(p.ID)},
+		},
+	}
+	for k, v := range request.Metadata {
+		params.AddMetadata(k, v)
+	}
+	params.SetStripeAccount(request.StripeAccount)
+	subscription, err := sub.New(params)
+	if err != nil {
+		log.Errorf("error creating subscription: %v", err)
+		return CreateSubscriptionResponse{}, err
+	}
+
+	return CreateSubscriptionResponse{
+		ID:         subscription.ID,
+		Status:     string(subscription.Status),
+		CustomerID: c.ID,
+	}, nil
+}
+
+// PauseSubscription stops invoicing a subscription until it is resumed
+func (s *StripeService) PauseSubscription(request SubscriptionRequest) error {
+	stripe.Key = s.apiKey
+
+	// this version of stripe-go doesn't have params for pause_collection
+	params := &stripe.SubscriptionParams{}
+	params.AddExtra("pause_collection[behavior]", "void")
+	params.SetStripeAccount(request.StripeAccount)
+	_, err := sub.Update(request.SubscriptionID, params)
+	if err != nil {
+		log.Errorf("error pausing subscription: %v", err)
+	}
+	return err
+}
+
+// ResumeSubscription resumes invoicing a paused subscription
+func (s *StripeService) ResumeSubscription(request SubscriptionRequest) error {
+	stripe.Key = s.apiKey
+
+	params := &stripe.SubscriptionParams{}
+	params.AddExtra("pause_collection", "")
+	params.SetStripeAccount(request.StripeAccount)
+	_, err := sub.Update(request.SubscriptionID, params)
+	if err != nil {
+		log.Errorf("error resuming subscription: %v", err)
+	}
+	return err
+}
+
+// CancelSubscription cancels a subscription immediately
+func (s *StripeService) CancelSubscription(request SubscriptionRequest) error {
+	stripe.Key = s.apiKey
+
+	params := &stripe.SubscriptionCancelParams{}
+	params.SetStripeAccount(request.StripeAccount)
+	_, err := sub.Cancel(request.SubscriptionID, params)
+	if err != nil {
+		log.Errorf("error canceling subscription: %v", err)
+	}
+	return err
+}
+
 // https://stripe.com/docs/connect/standard-accounts?origin_team=T9L4Z5JAU#token-request
 // "Finalize the account connection" https://stripe.com/docs/connect/quickstart
 type responseData struct {
@@ -0,0 +1,275 @@
+package payments
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/jinzhu/gorm/dialects/postgres"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stripe/stripe-go"
+)
+
+const (
+	subscriptionActive   = "active"
+	subscriptionPastDue  = "past_due"
+	subscriptionPaused   = "paused"
+	subscriptionCanceled = "canceled"
+
+	ownerTypeChannel = "channels"
+	// proceedsTypeSubscription is the proceeds type of payments owned by a channel rather than a post
+	proceedsTypeSubscription = "subscription"
+
+	// minSubscriptionAmount is the smallest monthly amount in USD
+	minSubscriptionAmount = 1
+)
+
+var (
+	// ErrNoSubscriptionWithGivenReferenceFound returned when subscription not found in DB for a webhook event
+	ErrNoSubscriptionWithGivenReferenceFound = errors.New("no subscription found for given reference")
+	// ErrNoSavedPaymentMethod returned when subscribing without a payment method saved with SavePaymentMethod
+	ErrNoSavedPaymentMethod = errors.New("a saved payment method is required to subscribe")
+	// ErrInvalidSubscriptionAmount returned when the monthly amount of a subscription is too small
+	ErrInvalidSubscriptionAmount = errors.New("subscription amount must be at least $1")
+	// ErrInvalidSubscriptionStatus returned when pausing, resuming or canceling a subscription that can't be
+	ErrInvalidSubscriptionStatus = errors.New("subscription can't be changed in its current status")
+)
+
+// CreateStripeSubscription subscribes the payer channel to a monthly payment to the owner channel,
+// paid with a payment method the payer saved with SavePaymentMethod. Invoice payments are recorded by the webhook
+func (s *Service) CreateStripeSubscription(ownerChannelID string, ownerTitle string, subscription StripeSubscription) (*StripeSubscription, error) {
+	if subscription.Amount < minSubscriptionAmount {
+		return nil, ErrInvalidSubscriptionAmount
+	}
+	customerID, err := s.channel.GetStripeCustomerID(subscription.PayerChannelID)
+	if err != nil || customerID == "" {
+		return nil, ErrNoSavedPaymentMethod
+	}
+	stripeAccount, err := s.channel.GetStripePaymentAccount(ownerChannelID)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.stripe.CreateSubscription(CreateSubscriptionRequest{
+		Amount:          toCents(subscription.Amount),
+		CustomerID:      customerID,
+		PaymentMethodID: subscription.PaymentMethodID,
+		Email:           subscription.EmailAddress,
+		Description:     ownerTitle + " Monthly Support",
+		StripeAccount:   stripeAccount,
+		Metadata:        map[string]string{"ownerChannelID": ownerChannelID, "payerChannelID": subscription.PayerChannelID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// generate a new ID for the subscription
+	id := uuid.NewV4()
+	subscription.ID = id.String()
+	subscription.Reference = res.ID
+	subscription.Status = subscriptionStatus(res.Status)
+	subscription.CurrencyCode = "USD"
+	subscription.OwnerChannelID = ownerChannelID
+	subscription.StripeAccount = stripeAccount
+	subscription.CustomerID = res.CustomerID
+
+	if err = s.db.Create(&subscription).Error; err != nil {
+		log.Errorf("An error occurred: %v\n", err)
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// subscriptionStatus maps the status of a stripe subscription to the status saved in the DB
+func subscriptionStatus(status string) string {
+	switch stripe.SubscriptionStatus(status) {
+	case stripe.SubscriptionStatusActive, stripe.SubscriptionStatusTrialing:
+		return subscriptionActive
+	case stripe.SubscriptionStatusCanceled, stripe.SubscriptionStatusIncompleteExpired:
+		return subscriptionCanceled
+	}
+	return subscriptionPastDue
+}
+
+// GetStripeSubscription returns the subscription with the given ID
+func (s *Service) GetStripeSubscription(subscriptionID string) (*StripeSubscription, error) {
+	var subscription StripeSubscription
+	if err := s.db.Where(&StripeSubscription{ID: subscriptionID}).First(&subscription).Error; err != nil {
+		log.Errorf("An error occurred: %v\n", err)
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// GetStripeSubscriptionsByPayerChannel returns the subscriptions of a supporter's channel, newest first
+func (s *Service) GetStripeSubscriptionsByPayerChannel(channelID string) ([]*StripeSubscription, error) {
+	var subscriptions []*StripeSubscription
+	if err := s.db.Where(&StripeSubscription{PayerChannelID: channelID}).Order("created_at desc").Find(&subscriptions).Error; err != nil {
+		log.Errorf("An error occurred: %v\n", err)
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// PauseStripeSubscription stops billing a subscription until it is resumed
+func (s *Service) PauseStripeSubscription(subscriptionID string) (*StripeSubscription, error) {
+	subscription, err := s.GetStripeSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.Status != subscriptionActive && subscription.Status != subscriptionPastDue {
+		return nil, ErrInvalidSubscriptionStatus
+	}
+
+	err = s.stripe.PauseSubscription(SubscriptionRequest{SubscriptionID: subscription.Reference, StripeAccount: subscription.StripeAccount})
+	if err != nil {
+		return nil, err
+	}
+	return subscription, s.updateSubscription(subscription, map[string]interface{}{"status": subscriptionPaused})
+}
+
+// ResumeStripeSubscription resumes billing a paused subscription
+func (s *Service) ResumeStripeSubscription(subscriptionID string) (*StripeSubscription, error) {
+	subscription, err := s.GetStripeSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.Status != subscriptionPaused {
+		return nil, ErrInvalidSubscriptionStatus
+	}
+
+	err = s.stripe.ResumeSubscription(SubscriptionRequest{SubscriptionID: subscription.Reference, StripeAccount: subscription.StripeAccount})
+	if err != nil {
+		return nil, err
+	}
+	return subscription, s.updateSubscription(subscription, map[string]interface{}{"status": subscriptionActive})
+}
+
+// CancelStripeSubscription cancels a subscription immediately
+func (s *Service) CancelStripeSubscription(subscriptionID string) (*StripeSubscription, error) {
+	subscription, err := s.GetStripeSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.Status == subscriptionCanceled {
+		return nil, ErrInvalidSubscriptionStatus
+	}
+
+	err = s.stripe.CancelSubscription(SubscriptionRequest{SubscriptionID: subscription.Reference, StripeAccount: subscription.StripeAccount})
+	if err != nil {
+		return nil, err
+	}
+	return subscription, s.cancelSubscription(subscription)
+}
+
+// RecordStripeInvoicePayment saves the payment of a subscription invoice after invoice.paid webhook event received
+func (s *Service) RecordStripeInvoicePayment(invoice stripe.Invoice) error {
+	subscription, err := s.getStripeSubscriptionByReference(invoice.Subscription)
+	if err != nil {
+		return err
+	}
+
+	// refunds find payments by payment intent or charge, so invoice payments are referenced the same way
+	var reference string
+	if invoice.PaymentIntent != nil {
+		reference = invoice.PaymentIntent.ID
+	} else if invoice.Charge != nil {
+		reference = invoice.Charge.ID
+	}
+	if reference == "" || invoice.AmountPaid == 0 {
+		return nil
+	}
+
+	// webhook events may be delivered more than once
+	var count int
+	s.db.Model(&PaymentModel{}).Where("payment_type = ? AND reference = ?", PaymentTypeStripe, reference).Count(&count)
+	if count > 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(invoice)
+	if err != nil {
+		log.Errorf("Error marshalling invoice data: %v\n", err)
+		return err
+	}
+
+	payment := PaymentModel{
+		ID:              uuid.NewV4().String(),
+		PaymentType:     PaymentTypeStripe,
+		Reference:       reference,
+		Status:          paymentComplete,
+		CurrencyCode:    strings.ToUpper(string(invoice.Currency)),
+		Amount:          float64(invoice.AmountPaid) / 100.0,
+		ExchangeRate:    1,
+		Data:            postgres.Jsonb{RawMessage: json.RawMessage(data)},
+		OwnerID:         subscription.OwnerChannelID,
+		OwnerType:       ownerTypeChannel,
+		OwnerChannelID:  subscription.OwnerChannelID,
+		EmailAddress:    subscription.EmailAddress,
+		PayerChannelID:  subscription.PayerChannelID,
+		ShouldPublicize: subscription.ShouldPublicize,
+		CustomerID:      subscription.CustomerID,
+		SubscriptionID:  subscription.ID,
+	}
+	if err = s.db.Create(&payment).Error; err != nil {
+		log.Errorf("An error occurred: %v\n", err)
+		return err
+	}
+
+	if subscription.Status == subscriptionPastDue {
+		return s.updateSubscription(subscription, map[string]interface{}{"status": subscriptionActive})
+	}
+	return nil
+}
+
+// FailStripeInvoicePayment marks a subscription past due after invoice.payment_failed webhook event received.
+// Stripe retries the payment, and the subscription is active again once an invoice is paid
+func (s *Service) FailStripeInvoicePayment(invoice stripe.Invoice) error {
+	subscription, err := s.getStripeSubscriptionByReference(invoice.Subscription)
+	if err != nil {
+		return err
+	}
+	if subscription.Status != subscriptionActive {
+		return nil
+	}
+	return s.updateSubscription(subscription, map[string]interface{}{"status": subscriptionPastDue})
+}
+
+// EndStripeSubscription marks a subscription canceled after customer.subscription.deleted webhook event received,
+// such as when Stripe gives up retrying payments or the subscription is canceled from the Stripe dashboard
+func (s *Service) EndStripeSubscription(reference string) error {
+	subscription, err := s.getStripeSubscriptionByReference(reference)
+	if err != nil {
+		return err
+	}
+	if subscription.Status == subscriptionCanceled {
+		return nil
+	}
+	return s.cancelSubscription(subscription)
+}
+
+func (s *Service) getStripeSubscriptionByReference(reference string) (*StripeSubscription, error) {
+	var subscription StripeSubscription
+	if reference == "" {
+		return nil, ErrNoSubscriptionWithGivenReferenceFound
+	}
+	if err := s.db.Where("reference = ?", reference).First(&subscription).Error; err != nil {
+		log.Errorf("Error getting subscription: %v\n", err)
+		return nil, ErrNoSubscriptionWithGivenReferenceFound
+	}
+	return &subscription, nil
+}
+
+func (s *Service) cancelSubscription(subscription *StripeSubscription) error {
+	return s.updateSubscription(subscription, map[string]interface{}{"status": subscriptionCanceled, "canceled_at": time.Now()})
+}
+
+func (s *Service) updateSubscription(subscription *StripeSubscription, update map[string]interface{}) error {
+	if err := s.db.Model(subscription).Updates(update).Error; err != nil {
+		log.Errorf("Error updating subscription: %v\n", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+// +build integration
+
+package payments_test
+
+import (
+	"testing"
+
+	"github.com/joincivil/civil-api-server/pkg/payments"
+	"github.com/joincivil/civil-api-server/pkg/testruntime"
+	"github.com/joincivil/civil-api-server/pkg/testutils"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stripe/stripe-go"
+)
+
+func TestSubscriptions(t *testing.T) {
+	db, err := testutils.GetTestDBConnection()
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	err = testruntime.RunMigrations(db)
+	if err != nil {
+		t.Fatalf("error running migrations: %v", err)
+	}
+	paymentHelper := testruntime.NewMockPaymentHelper(testruntime.NewMockTransactionReader())
+	paymentService := payments.NewService(db, paymentHelper, nil, nil, paymentHelper, nil)
+
+	ownerChannelID := uuid.NewV4().String()
+	payerChannelID := uuid.NewV4().String()
+
+	_, err = paymentService.CreateStripeSubscription(ownerChannelID, "newsroom", payments.StripeSubscription{
+		Amount:          0.5,
+		PayerChannelID:  payerChannelID,
+		PaymentMethodID: "pm_small",
+	})
+	if err != payments.ErrInvalidSubscriptionAmount {
+		t.Fatalf("expecting ErrInvalidSubscriptionAmount, got %v", err)
+	}
+
+	subscription, err := paymentService.CreateStripeSubscription(ownerChannelID, "newsroom", payments.StripeSubscription{
+		Amount:          5,
+		PayerChannelID:  payerChannelID,
+		PaymentMethodID: "pm_" + uuid.NewV4().String(),
+		ShouldPublicize: true,
+	})
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+	if subscription.Status != "active" || subscription.OwnerChannelID != ownerChannelID ||
+		subscription.StripeAccount != "stripe"+ownerChannelID {
+		t.Fatalf("unexpected subscription: %+v", subscription)
+	}
+
+	subscriptions, err := paymentService.GetStripeSubscriptionsByPayerChannel(payerChannelID)
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+	if len(subscriptions) != 1 || subscriptions[0].ID != subscription.ID {
+		t.Fatalf("expecting the payer's subscription, got %v", subscriptions)
+	}
+
+	t.Run("invoice payments", func(t *testing.T) {
+		invoice := stripe.Invoice{
+			ID:            "in_" + uuid.NewV4().String(),
+			Subscription:  subscription.Reference,
+			AmountPaid:    500,
+			Currency:      stripe.CurrencyUSD,
+			PaymentIntent: &stripe.PaymentIntent{ID: "pi_" + uuid.NewV4().String()},
+		}
+		// the webhook may be delivered more than once
+		for i := 0; i < 2; i++ {
+			if err := paymentService.RecordStripeInvoicePayment(invoice); err != nil {
+				t.Fatalf("not expecting error: %v", err)
+			}
+		}
+
+		var paid []payments.PaymentModel
+		db.Where(&payments.PaymentModel{SubscriptionID: subscription.ID}).Find(&paid)
+		if len(paid) != 1 {
+			t.Fatalf("expecting one payment, got %v", len(paid))
+		}
+		if paid[0].Amount != 5 || paid[0].Status != "complete" || paid[0].Reference != invoice.PaymentIntent.ID ||
+			paid[0].OwnerChannelID != ownerChannelID || paid[0].PayerChannelID != payerChannelID || paid[0].CurrencyCode != "USD" {
+			t.Fatalf("unexpected payment: %+v", paid[0])
+		}
+
+		proceeds := paymentService.GetChannelTotalProceeds(ownerChannelID)
+		if proceeds.PostType != "subscription" || proceeds.TotalAmount != "5" || proceeds.Usd != "5" {
+			t.Fatalf("expecting subscription proceeds of 5, got %+v", proceeds)
+		}
+		proceeds = paymentService.GetChannelTotalProceedsByBoostType(ownerChannelID, "subscription")
+		if proceeds.TotalAmount != "5" {
+			t.Fatalf("expecting subscription proceeds of 5, got %+v", proceeds)
+		}
+		history, err := paymentService.GetPaymentsByPayerChannel(payerChannelID)
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		if len(history) != 1 || history[0].(*payments.StripePayment).SubscriptionID != subscription.ID {
+			t.Fatalf("expecting the invoice payment in the payer's history, got %v", history)
+		}
+
+		err = paymentService.RecordStripeInvoicePayment(stripe.Invoice{Subscription: "sub_unknown"})
+		if err != payments.ErrNoSubscriptionWithGivenReferenceFound {
+			t.Fatalf("expecting ErrNoSubscriptionWithGivenReferenceFound, got %v", err)
+		}
+	})
+
+	t.Run("failed invoice payments", func(t *testing.T) {
+		err := paymentService.FailStripeInvoicePayment(stripe.Invoice{Subscription: subscription.Reference})
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		retrieved, _ := paymentService.GetStripeSubscription(subscription.ID)
+		if retrieved.Status != "past_due" {
+			t.Fatalf("expecting the subscription to be past due, got %v", retrieved.Status)
+		}
+
+		err = paymentService.RecordStripeInvoicePayment(stripe.Invoice{
+			Subscription:  subscription.Reference,
+			AmountPaid:    500,
+			Currency:      stripe.CurrencyUSD,
+			PaymentIntent: &stripe.PaymentIntent{ID: "pi_" + uuid.NewV4().String()},
+		})
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		retrieved, _ = paymentService.GetStripeSubscription(subscription.ID)
+		if retrieved.Status != "active" {
+			t.Fatalf("expecting a paid invoice to make the subscription active, got %v", retrieved.Status)
+		}
+	})
+
+	t.Run("pause, resume and cancel", func(t *testing.T) {
+		_, err := paymentService.ResumeStripeSubscription(subscription.ID)
+		if err != payments.ErrInvalidSubscriptionStatus {
+			t.Fatalf("expecting ErrInvalidSubscriptionStatus resuming an active subscription, got %v", err)
+		}
+
+		paused, err := paymentService.PauseStripeSubscription(subscription.ID)
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		if paused.Status != "paused" {
+			t.Fatalf("expecting the subscription to be paused, got %v", paused.Status)
+		}
+
+		resumed, err := paymentService.ResumeStripeSubscription(subscription.ID)
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		if resumed.Status != "active" {
+			t.Fatalf("expecting the subscription to be active, got %v", resumed.Status)
+		}
+
+		canceled, err := paymentService.CancelStripeSubscription(subscription.ID)
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		if canceled.Status != "canceled" || canceled.CanceledAt == nil {
+			t.Fatalf("expecting the subscription to be canceled, got %v", canceled.Status)
+		}
+
+		_, err = paymentService.PauseStripeSubscription(subscription.ID)
+		if err != payments.ErrInvalidSubscriptionStatus {
+			t.Fatalf("expecting ErrInvalidSubscriptionStatus pausing a canceled subscription, got %v", err)
+		}
+	})
+
+	t.Run("subscription deleted webhook", func(t *testing.T) {
+		deleted, err := paymentService.CreateStripeSubscription(ownerChannelID, "newsroom", payments.StripeSubscription{
+			Amount:          10,
+			PayerChannelID:  payerChannelID,
+			PaymentMethodID: "pm_" + uuid.NewV4().String(),
+		})
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+
+		if err = paymentService.EndStripeSubscription(deleted.Reference); err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		retrieved, _ := paymentService.GetStripeSubscription(deleted.ID)
+		if retrieved.Status != "canceled" {
+			t.Fatalf("expecting the subscription to be canceled, got %v", retrieved.Status)
+		}
+	})
+}
@@ -0,0 +1,227 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/joincivil/civil-api-server/pkg/storefront"
+	"github.com/joincivil/civil-api-server/pkg/utils"
+)
+
+const (
+	cvlTokenSymbol   = "CVL"
+	cvlTokenDecimals = 18
+)
+
+var (
+	// ErrorTokenNotAllowed is returned when a payment is made with a token that isn't accepted
+	ErrorTokenNotAllowed = fmt.Errorf("token not accepted for payments")
+	// ErrorNoTokenPrice is returned when there is no USD price for a token
+	ErrorNoTokenPrice = fmt.Errorf("no price for token")
+
+	// transferEventTopic is the topic of the ERC-20 event Transfer(address indexed from, address indexed to, uint256 value)
+	transferEventTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+)
+
+// Token is an ERC-20 token accepted for payments
+type Token struct {
+	Symbol   string
+	Address  common.Address
+	Decimals int
+}
+
+// TokenPriceSource provides the price of tokens in USD
+type TokenPriceSource interface {
+	TokenToUSD(symbol string) (float64, error)
+}
+
+// StaticTokenPrices is a TokenPriceSource with a fixed price for each token symbol
+type StaticTokenPrices map[string]float64
+
+// TokenToUSD returns the price of 1 token in USD
+func (p StaticTokenPrices) TokenToUSD(symbol string) (float64, error) {
+	price, ok := p[symbol]
+	if !ok || price <= 0 {
+		return 0, ErrorNoTokenPrice
+	}
+	return price, nil
+}
+
+// TokenPrices is a TokenPriceSource that prices CVL along the token sale's pricing curve,
+// and other tokens with fixed prices
+type TokenPrices struct {
+	cvl   *storefront.PricingManager
+	fixed StaticTokenPrices
+}
+
+// NewTokenPrices creates a TokenPrices using the token sale pricing and fixed prices for each token symbol
+func NewTokenPrices(cvl *storefront.PricingManager, fixed map[string]float64) *TokenPrices {
+	return &TokenPrices{cvl: cvl, fixed: StaticTokenPrices(fixed)}
+}
+
+// TokenToUSD returns the price of 1 token in USD
+func (p *TokenPrices) TokenToUSD(symbol string) (float64, error) {
+	if symbol == cvlTokenSymbol && p.cvl != nil {
+		price := p.cvl.GetQuote(1)
+		if price <= 0 {
+			return 0, ErrorNoTokenPrice
+		}
+		return price, nil
+	}
+	return p.fixed.TokenToUSD(symbol)
+}
+
+// TokenPaymentService validates ERC-20 token payments
+type TokenPaymentService struct {
+	chain         ChainReader
+	confirmations uint64
+	tokens        map[common.Address]Token
+	prices        TokenPriceSource
+}
+
+// ValidateTokenTransactionResponse is the response of ValidateTokenTransaction
+type ValidateTokenTransactionResponse struct {
+	ValidateTransactionResponse
+	TokenAddress string
+	CurrencyCode string
+}
+
+// NewTokenPaymentService creates a TokenPaymentService that accepts payments in `tokens`, and considers
+// a transaction confirmed once its block has `confirmations` confirmations
+func NewTokenPaymentService(chainReader ChainReader, confirmations uint64, tokens []Token, prices TokenPriceSource) *TokenPaymentService {
+	allowed := make(map[common.Address]Token, len(tokens))
+	for _, token := range tokens {
+		allowed[token.Address] = token
+	}
+
+	return &TokenPaymentService{
+		chain:         chainReader,
+		confirmations: confirmations,
+		tokens:        allowed,
+		prices:        prices,
+	}
+}
+
+// NewTokenPaymentServiceFromConfig creates a TokenPaymentService accepting CVL and the tokens in the graphql config
+func NewTokenPaymentServiceFromConfig(chainReader ChainReader, prices TokenPriceSource, config *utils.GraphQLConfig) (*TokenPaymentService, error) {
+	var tokens []Token
+	if cvlAddress := config.ContractAddresses["CVLToken"]; cvlAddress != "" {
+		tokens = append(tokens, Token{Symbol: cvlTokenSymbol, Address: common.HexToAddress(cvlAddress), Decimals: cvlTokenDecimals})
+	}
+	for _, value := range config.TokenPaymentTokens {
+		token, err := ParseToken(value)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return NewTokenPaymentService(chainReader, config.EthPaymentConfirmations, tokens, prices), nil
+}
+
+// ParseToken parses a token configured as <symbol>:<contract address>:<decimals>
+func ParseToken(value string) (Token, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 || parts[0] == "" || !common.IsHexAddress(parts[1]) {
+		return Token{}, fmt.Errorf("invalid token %q, expected <symbol>:<contract address>:<decimals>", value)
+	}
+	decimals, err := strconv.Atoi(parts[2])
+	if err != nil || decimals < 0 {
+		return Token{}, fmt.Errorf("invalid token decimals %q", parts[2])
+	}
+	return Token{Symbol: strings.ToUpper(parts[0]), Address: common.HexToAddress(parts[1]), Decimals: decimals}, nil
+}
+
+// GetToken returns the accepted token with the given contract address
+func (s *TokenPaymentService) GetToken(tokenAddress common.Address) (Token, error) {
+	token, ok := s.tokens[tokenAddress]
+	if !ok {
+		return Token{}, ErrorTokenNotAllowed
+	}
+	return token, nil
+}
+
+// ValidateTokenTransaction accepts a transaction and determines whether it transferred an accepted token
+// to the expected receiver, summing the Transfer events of the token in the receipt
+func (s *TokenPaymentService) ValidateTokenTransaction(transactionID string, tokenAddress common.Address, expectedReceiver common.Address) (*ValidateTokenTransactionResponse, error) {
+	token, err := s.GetToken(tokenAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	// retrieve the transaction information
+	data, _, err := s.chain.TransactionByHash(context.Background(), common.HexToHash(transactionID))
+	if err == ethereum.NotFound {
+		return nil, ErrorTransactionNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("error with transaction: %v", err)
+	}
+
+	// retrieve the transaction receipt
+	receipt, err := s.chain.TransactionReceipt(context.Background(), common.HexToHash(transactionID))
+	if err == ethereum.NotFound {
+		return nil, ErrorReceiptNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("error with transaction: %v", err)
+	}
+
+	// confirm the tx was successful
+	if receipt.Status != 1 {
+		return nil, ErrorTransactionFailed
+	}
+
+	// the transaction is usually a call to the token contract, but may be to another contract that
+	// transfers the token, so the logs are checked rather than the transaction's input
+	total := new(big.Int)
+	for _, entry := range receipt.Logs {
+		if entry.Address != token.Address || len(entry.Topics) != 3 || entry.Topics[0] != transferEventTopic {
+			continue
+		}
+		if common.BytesToAddress(entry.Topics[2].Bytes()) != expectedReceiver {
+			continue
+		}
+		total.Add(total, new(big.Int).SetBytes(entry.Data))
+	}
+	if total.Sign() == 0 {
+		return nil, ErrorInvalidRecipient
+	}
+
+	// convert the amount from the token's base units
+	amount := new(big.Float).SetInt(total)
+	amount = amount.Quo(amount, new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(token.Decimals)), nil)))
+	valueFloat, _ := amount.Float64()
+
+	// retrieve the current exchange rate to USD
+	exchangeRate, err := s.prices.TokenToUSD(token.Symbol)
+	if err == ErrorNoTokenPrice {
+		return nil, err
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting exchange rate: err: %v", err)
+	}
+
+	blockNumber, confirmations, err := blockConfirmations(s.chain, receipt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ValidateTokenTransactionResponse{
+		ValidateTransactionResponse: ValidateTransactionResponse{
+			PaymentAddress: expectedReceiver.String(),
+			Amount:         valueFloat,
+			TransactionID:  data.Hash().String(),
+			ExchangeRate:   exchangeRate,
+			BlockNumber:    blockNumber,
+			BlockHash:      receipt.BlockHash.String(),
+			Confirmations:  confirmations,
+			Confirmed:      confirmations >= s.confirmations,
+		},
+		TokenAddress: token.Address.String(),
+		CurrencyCode: token.Symbol,
+	}, nil
+}
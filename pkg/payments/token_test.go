@@ -0,0 +1,155 @@
+package payments_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/joincivil/civil-api-server/pkg/payments"
+	"github.com/joincivil/civil-api-server/pkg/storefront"
+	"github.com/joincivil/civil-api-server/pkg/testruntime"
+)
+
+var (
+	usdcAddress  = common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	daiAddress   = common.HexToAddress("0x6B175474E89094C44Da98b954EedeAC495271d0F")
+	otherAddress = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	channelAddr  = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	payerAddr    = common.HexToAddress("0x00000000000000000000000000000000000000cc")
+)
+
+func transferLog(token common.Address, to common.Address, value *big.Int) *types.Log {
+	return &types.Log{
+		Address: token,
+		Topics: []common.Hash{
+			crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")),
+			common.BytesToHash(payerAddr.Bytes()),
+			common.BytesToHash(to.Bytes()),
+		},
+		Data: common.LeftPadBytes(value.Bytes(), 32),
+	}
+}
+
+func addTokenTransaction(reader *testruntime.MockTransactionReader, nonce uint64, status uint64, logs ...*types.Log) string {
+	tx := types.NewTransaction(nonce, usdcAddress, big.NewInt(0), 100000, big.NewInt(1), nil)
+	reader.AddTransaction(tx.Hash(), tx)
+	reader.AddReceipt(tx.Hash(), &types.Receipt{Status: status, Logs: logs})
+	return tx.Hash().String()
+}
+
+func TestParseToken(t *testing.T) {
+	token, err := payments.ParseToken("usdc:" + usdcAddress.String() + ":6")
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+	if token.Symbol != "USDC" || token.Address != usdcAddress || token.Decimals != 6 {
+		t.Fatalf("unexpected token: %+v", token)
+	}
+
+	for _, value := range []string{"USDC", "USDC:0x123:6", "USDC:" + usdcAddress.String() + ":six"} {
+		if _, err := payments.ParseToken(value); err == nil {
+			t.Fatalf("expecting an error parsing %q", value)
+		}
+	}
+}
+
+func TestValidateTokenTransaction(t *testing.T) {
+	reader := testruntime.NewMockTransactionReader()
+	service := payments.NewTokenPaymentService(reader, 1, []payments.Token{
+		{Symbol: "USDC", Address: usdcAddress, Decimals: 6},
+		{Symbol: "DAI", Address: daiAddress, Decimals: 18},
+	}, payments.StaticTokenPrices{"USDC": 1})
+
+	t.Run("scales by decimals", func(t *testing.T) {
+		txID := addTokenTransaction(reader, 1, 1,
+			transferLog(usdcAddress, channelAddr, big.NewInt(12500000)),
+			transferLog(usdcAddress, otherAddress, big.NewInt(1000000)),
+			transferLog(otherAddress, channelAddr, big.NewInt(1000000)),
+		)
+		res, err := service.ValidateTokenTransaction(txID, usdcAddress, channelAddr)
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		if res.Amount != 12.5 || res.ExchangeRate != 1 || res.CurrencyCode != "USDC" || res.TokenAddress != usdcAddress.String() {
+			t.Fatalf("unexpected response: %+v", res)
+		}
+	})
+
+	t.Run("wrong recipient", func(t *testing.T) {
+		txID := addTokenTransaction(reader, 2, 1, transferLog(usdcAddress, otherAddress, big.NewInt(1000000)))
+		_, err := service.ValidateTokenTransaction(txID, usdcAddress, channelAddr)
+		if err != payments.ErrorInvalidRecipient {
+			t.Fatalf("expecting ErrorInvalidRecipient, got %v", err)
+		}
+	})
+
+	t.Run("failed transaction", func(t *testing.T) {
+		txID := addTokenTransaction(reader, 3, 0, transferLog(usdcAddress, channelAddr, big.NewInt(1000000)))
+		_, err := service.ValidateTokenTransaction(txID, usdcAddress, channelAddr)
+		if err != payments.ErrorTransactionFailed {
+			t.Fatalf("expecting ErrorTransactionFailed, got %v", err)
+		}
+	})
+
+	t.Run("token not allowed", func(t *testing.T) {
+		txID := addTokenTransaction(reader, 4, 1, transferLog(otherAddress, channelAddr, big.NewInt(1000000)))
+		_, err := service.ValidateTokenTransaction(txID, otherAddress, channelAddr)
+		if err != payments.ErrorTokenNotAllowed {
+			t.Fatalf("expecting ErrorTokenNotAllowed, got %v", err)
+		}
+	})
+
+	t.Run("no token price", func(t *testing.T) {
+		txID := addTokenTransaction(reader, 5, 1, transferLog(daiAddress, channelAddr, big.NewInt(1000000)))
+		_, err := service.ValidateTokenTransaction(txID, daiAddress, channelAddr)
+		if err != payments.ErrorNoTokenPrice {
+			t.Fatalf("expecting ErrorNoTokenPrice without a DAI price, got %v", err)
+		}
+	})
+
+	t.Run("waits for confirmations", func(t *testing.T) {
+		confirming := payments.NewTokenPaymentService(reader, 3, []payments.Token{
+			{Symbol: "USDC", Address: usdcAddress, Decimals: 6},
+		}, payments.StaticTokenPrices{"USDC": 1})
+		reader.AdvanceBlocks(1)
+		txID := addTokenTransaction(reader, 6, 1, transferLog(usdcAddress, channelAddr, big.NewInt(1000000)))
+		reader.Receipts[txID].BlockNumber = new(big.Int).SetUint64(reader.BlockNumber)
+
+		res, err := confirming.ValidateTokenTransaction(txID, usdcAddress, channelAddr)
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		if res.Confirmations != 1 || res.Confirmed {
+			t.Fatalf("expecting 1 confirmation, got %+v", res)
+		}
+
+		reader.AdvanceBlocks(2)
+		res, err = confirming.ValidateTokenTransaction(txID, usdcAddress, channelAddr)
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		if res.Confirmations != 3 || !res.Confirmed {
+			t.Fatalf("expecting the transaction to be confirmed, got %+v", res)
+		}
+	})
+}
+
+func TestTokenPrices(t *testing.T) {
+	prices := payments.NewTokenPrices(storefront.NewPricingManager(1000, 1000, 0.5), map[string]float64{"USDC": 1})
+
+	price, err := prices.TokenToUSD("CVL")
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+	if price <= 0.5 || price >= 0.6 {
+		t.Fatalf("expecting CVL to be priced at the start of the token sale curve, got %v", price)
+	}
+	if price, err = prices.TokenToUSD("USDC"); err != nil || price != 1 {
+		t.Fatalf("expecting USDC to be priced at 1, got %v %v", price, err)
+	}
+	if _, err = prices.TokenToUSD("DAI"); err != payments.ErrorNoTokenPrice {
+		t.Fatalf("expecting ErrorNoTokenPrice, got %v", err)
+	}
+}
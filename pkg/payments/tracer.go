@@ -0,0 +1,59 @@
+package payments
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// CallTracer finds internal transfers with the debug_traceTransaction call tracer of geth and compatible nodes
+type CallTracer struct {
+	client *rpc.Client
+}
+
+// callFrame is a call in the result of the call tracer
+type callFrame struct {
+	Type  string         `json:"type"`
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Value *hexutil.Big   `json:"value"`
+	Error string         `json:"error"`
+	Calls []callFrame    `json:"calls"`
+}
+
+// NewCallTracer creates a CallTracer using an RPC client for a node with the debug API enabled
+func NewCallTracer(client *rpc.Client) *CallTracer {
+	return &CallTracer{client}
+}
+
+// InternalTransfers returns the ETH sent by each call in the transaction, including the transaction itself
+func (t *CallTracer) InternalTransfers(ctx context.Context, txHash common.Hash) ([]InternalTransfer, error) {
+	var frame callFrame
+	err := t.client.CallContext(ctx, &frame, "debug_traceTransaction", txHash, map[string]string{"tracer": "callTracer"})
+	if err != nil {
+		return nil, err
+	}
+	return frame.transfers(nil), nil
+}
+
+// transfers collects the ETH sent by the call and its subcalls, skipping calls that reverted
+func (f callFrame) transfers(transfers []InternalTransfer) []InternalTransfer {
+	if f.Error != "" {
+		return transfers
+	}
+	// delegate calls and call codes run code with the caller's balance, and static calls can't send ETH
+	switch f.Type {
+	case "DELEGATECALL", "CALLCODE", "STATICCALL":
+	default:
+		if f.Value != nil && f.Value.ToInt().Sign() > 0 {
+			transfers = append(transfers, InternalTransfer{From: f.From, To: f.To, Value: new(big.Int).Set(f.Value.ToInt())})
+		}
+	}
+	for _, call := range f.Calls {
+		transfers = call.transfers(transfers)
+	}
+	return transfers
+}
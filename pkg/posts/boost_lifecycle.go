@@ -0,0 +1,320 @@
+package posts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/joincivil/go-common/pkg/email"
+
+	"github.com/joincivil/civil-api-server/pkg/channels"
+	"github.com/joincivil/civil-api-server/pkg/payments"
+	"github.com/joincivil/civil-api-server/pkg/utils"
+)
+
+const (
+	defaultBoostNotifyWindow = 7 * 24 * time.Hour
+
+	boostEmailFromName    = "Civil"
+	boostEmailFromAddress = "support@civil.co"
+	boostEmailAsmGroupID  = 8328 // Civil Registry Alerts
+
+	boostCurrencyCode = "USD"
+)
+
+// BoostPaymentHelper defines the payment methods needed to follow a Boost through its lifecycle
+type BoostPaymentHelper interface {
+	TotalPayments(postID string, currencyCode string) (float64, error)
+	GetChannelTotalProceedsByBoostType(channelID string, boostType string) *payments.ProceedsQueryResult
+	GetSupporterEmailAddresses(postID string) ([]string, error)
+}
+
+// BoostChannelHelper defines the channel methods needed to notify the admins of a Boost's channel
+type BoostChannelHelper interface {
+	GetChannelAdminUserChannels(channelID string) ([]*channels.Channel, error)
+}
+
+// BoostEmailer sends templated emails
+type BoostEmailer interface {
+	SendTemplateEmail(req *email.SendTemplateEmailRequest) error
+}
+
+// BoostLifecycleConfig configures the emails sent on Boost status transitions
+type BoostLifecycleConfig struct {
+	// SiteURL is the base URL used to link to a boost
+	SiteURL string
+	// NotifyWindow is how long after a boost ends that emails are still sent. Boosts that ended earlier,
+	// such as those that ended before lifecycles were tracked, are settled without sending email
+	NotifyWindow               time.Duration
+	EndedEmailTemplateID       string
+	GoalReachedEmailTemplateID string
+	UpdateEmailTemplateID      string
+}
+
+// BoostLifecycleService tracks Boosts from active through ended to goal met or goal missed, emailing channel admins
+// a summary of proceeds when a boost ends and supporters when a goal is reached or an update is posted
+type BoostLifecycleService struct {
+	persister PostPersister
+	payments  BoostPaymentHelper
+	channels  BoostChannelHelper
+	emailer   BoostEmailer
+	config    BoostLifecycleConfig
+}
+
+// NewBoostLifecycleService builds an instance of BoostLifecycleService
+func NewBoostLifecycleService(persister PostPersister, paymentHelper BoostPaymentHelper, channelHelper BoostChannelHelper,
+	emailer BoostEmailer, config BoostLifecycleConfig) *BoostLifecycleService {
+	if config.NotifyWindow <= 0 {
+		config.NotifyWindow = defaultBoostNotifyWindow
+	}
+
+	return &BoostLifecycleService{
+		persister: persister,
+		payments:  paymentHelper,
+		channels:  channelHelper,
+		emailer:   emailer,
+		config:    config,
+	}
+}
+
+// NewBoostLifecycleServiceFromConfig builds an instance of BoostLifecycleService using the graphql config
+func NewBoostLifecycleServiceFromConfig(persister PostPersister, paymentHelper BoostPaymentHelper, channelHelper BoostChannelHelper,
+	emailer *email.Emailer, config *utils.GraphQLConfig) *BoostLifecycleService {
+	return NewBoostLifecycleService(persister, paymentHelper, channelHelper, emailer, BoostLifecycleConfig{
+		SiteURL:                    config.SyndicationSiteURL,
+		NotifyWindow:               time.Duration(config.BoostLifecycleNotifyWindowHours) * time.Hour,
+		EndedEmailTemplateID:       config.BoostEndedEmailTemplateID,
+		GoalReachedEmailTemplateID: config.BoostGoalReachedEmailTemplateID,
+		UpdateEmailTemplateID:      config.BoostUpdateEmailTemplateID,
+	})
+}
+
+// Status returns the lifecycle status of a Boost. A boost past its end date that has not yet been processed is ended
+func (s *BoostLifecycleService) Status(boost *Boost) (string, error) {
+	if boost.Draft {
+		return BoostStatusDraft, nil
+	}
+	lifecycle, err := s.persister.GetBoostLifecycle(boost.ID)
+	if err != nil && err != ErrorNotFound {
+		return "", err
+	}
+	if lifecycle != nil && (lifecycle.IsSettled() || lifecycle.Status == BoostStatusEnded) {
+		return lifecycle.Status, nil
+	}
+	if !time.Now().Before(boost.DateEnd) {
+		return BoostStatusEnded, nil
+	}
+	return BoostStatusActive, nil
+}
+
+// PercentFunded returns the USD equivalent of payments to a Boost as a percentage of its goal
+func (s *BoostLifecycleService) PercentFunded(boost *Boost) (float64, error) {
+	raised, err := s.payments.TotalPayments(boost.ID, boostCurrencyCode)
+	if err != nil {
+		return 0, err
+	}
+	return percentFunded(boost, raised), nil
+}
+
+// ProcessBoosts applies the status transitions due for every unsettled Boost
+func (s *BoostLifecycleService) ProcessBoosts() error {
+	boosts, err := s.persister.GetUnsettledBoosts()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, boost := range boosts {
+		if _, err := s.Process(boost, now); err != nil {
+			log.Errorf("error processing lifecycle of boost %v: %v", boost.ID, err)
+		}
+	}
+	return nil
+}
+
+// Process records the goal being reached and the boost ending as of `now`, sending the emails for each transition
+func (s *BoostLifecycleService) Process(boost *Boost, now time.Time) (*BoostLifecycle, error) {
+	lifecycle, err := s.persister.GetBoostLifecycle(boost.ID)
+	if err == ErrorNotFound {
+		lifecycle = &BoostLifecycle{PostID: boost.ID}
+	} else if err != nil {
+		return nil, err
+	}
+	if lifecycle.IsSettled() {
+		return lifecycle, nil
+	}
+	if lifecycle.Status == "" || lifecycle.Status == BoostStatusDraft {
+		lifecycle.Status = BoostStatusActive
+	}
+
+	raised, err := s.payments.TotalPayments(boost.ID, boostCurrencyCode)
+	if err != nil {
+		return nil, err
+	}
+	notify := now.Sub(boost.DateEnd) <= s.config.NotifyWindow
+
+	if boost.GoalAmount > 0 && raised >= boost.GoalAmount && lifecycle.GoalReachedAt == nil {
+		lifecycle.GoalReachedAt = &now
+	}
+	if lifecycle.GoalReachedAt != nil && lifecycle.SupportersNotifiedAt == nil {
+		if notify {
+			s.sendGoalReachedEmails(boost, raised)
+		}
+		lifecycle.SupportersNotifiedAt = &now
+	}
+
+	if !now.Before(boost.DateEnd) {
+		if lifecycle.EndedAt == nil {
+			lifecycle.Status = BoostStatusEnded
+			lifecycle.EndedAt = &now
+			// persist the ended status before emailing so a failure to settle never repeats the emails
+			if err = s.persister.SaveBoostLifecycle(lifecycle); err != nil {
+				return nil, err
+			}
+		}
+		if lifecycle.AdminsNotifiedAt == nil {
+			if notify {
+				s.sendEndedEmails(boost, raised)
+			}
+			lifecycle.AdminsNotifiedAt = &now
+		}
+
+		lifecycle.Status = BoostStatusGoalMissed
+		if lifecycle.GoalReachedAt != nil {
+			lifecycle.Status = BoostStatusGoalMet
+		}
+		lifecycle.SettledAt = &now
+	}
+
+	if err = s.persister.SaveBoostLifecycle(lifecycle); err != nil {
+		return nil, err
+	}
+	return lifecycle, nil
+}
+
+func (s *BoostLifecycleService) sendGoalReachedEmails(boost *Boost, raised float64) {
+	if s.config.GoalReachedEmailTemplateID == "" {
+		return
+	}
+	addresses, err := s.payments.GetSupporterEmailAddresses(boost.ID)
+	if err != nil {
+		log.Errorf("error getting supporters of boost %v: %v", boost.ID, err)
+		return
+	}
+
+	tmplData := s.boostTemplateData(boost, raised)
+	for _, address := range addresses {
+		err := s.sendEmail(s.config.GoalReachedEmailTemplateID, address, tmplData)
+		if err != nil {
+			log.Errorf("error sending boost goal reached email: %v", err)
+		}
+	}
+}
+
+// NotifyBoostUpdate emails everyone who gave an email address with a completed payment to the Boost about an update
+func (s *BoostLifecycleService) NotifyBoostUpdate(boost *Boost, update *BoostUpdate) {
+	if s.config.UpdateEmailTemplateID == "" {
+		return
+	}
+	addresses, err := s.payments.GetSupporterEmailAddresses(boost.ID)
+	if err != nil {
+		log.Errorf("error getting supporters of boost %v: %v", boost.ID, err)
+		return
+	}
+
+	tmplData := email.TemplateData{
+		"boost_id":         boost.ID,
+		"boost_short_desc": boost.Title,
+		"boost_url":        s.boostURL(boost),
+		"update_id":        update.ID,
+		"update_text":      update.Text,
+	}
+	for _, address := range addresses {
+		err := s.sendEmail(s.config.UpdateEmailTemplateID, address, tmplData)
+		if err != nil {
+			log.Errorf("error sending boost update email: %v", err)
+		}
+	}
+}
+
+func (s *BoostLifecycleService) sendEndedEmails(boost *Boost, raised float64) {
+	if s.config.EndedEmailTemplateID == "" {
+		return
+	}
+	admins, err := s.channels.GetChannelAdminUserChannels(boost.ChannelID)
+	if err != nil {
+		log.Errorf("error getting admins of boost channel %v: %v", boost.ChannelID, err)
+		return
+	}
+
+	tmplData := s.boostTemplateData(boost, raised)
+	proceeds := s.payments.GetChannelTotalProceedsByBoostType(boost.ChannelID, TypeBoost)
+	tmplData["channel_boost_proceeds_total_usd"] = proceeds.TotalAmount
+	tmplData["channel_boost_proceeds_usd"] = proceeds.Usd
+	tmplData["channel_boost_proceeds_eth_usd"] = proceeds.EthUsdAmount
+	tmplData["channel_boost_proceeds_ether"] = proceeds.Ether
+
+	for _, admin := range admins {
+		if admin.EmailAddress == "" {
+			continue
+		}
+		err := s.sendEmail(s.config.EndedEmailTemplateID, admin.EmailAddress, tmplData)
+		if err != nil {
+			log.Errorf("error sending boost ended email: %v", err)
+		}
+	}
+}
+
+func (s *BoostLifecycleService) boostTemplateData(boost *Boost, raised float64) email.TemplateData {
+	return email.TemplateData{
+		"boost_id":           boost.ID,
+		"boost_short_desc":   boost.Title,
+		"boost_url":          s.boostURL(boost),
+		"goal_amount_usd":    boost.GoalAmount,
+		"raised_amount_usd":  raised,
+		"percent_funded":     percentFunded(boost, raised),
+		"boost_date_end":     boost.DateEnd.Format("January 2, 2006"),
+		"boost_goal_reached": boost.GoalAmount > 0 && raised >= boost.GoalAmount,
+	}
+}
+
+func (s *BoostLifecycleService) boostURL(boost *Boost) string {
+	return fmt.Sprintf("%v/boosts/%v", strings.TrimRight(s.config.SiteURL, "/"), boost.ID)
+}
+
+func (s *BoostLifecycleService) sendEmail(templateID string, emailAddress string, tmplData email.TemplateData) error {
+	return s.emailer.SendTemplateEmail(&email.SendTemplateEmailRequest{
+		ToName:       emailAddress,
+		ToEmail:      emailAddress,
+		FromName:     boostEmailFromName,
+		FromEmail:    boostEmailFromAddress,
+		TemplateID:   templateID,
+		TemplateData: tmplData,
+		AsmGroupID:   boostEmailAsmGroupID,
+	})
+}
+
+// BoostLifecycleCron periodically processes Boost status transitions
+func BoostLifecycleCron(service *BoostLifecycleService, config *utils.GraphQLConfig) {
+	if config.BoostLifecyclePollSecs <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(config.BoostLifecyclePollSecs) * time.Second)
+	go func() {
+		for range ticker.C {
+			err := service.ProcessBoosts()
+			if err != nil {
+				log.Errorf("error processing boost lifecycles: %v", err)
+			}
+		}
+	}()
+}
+
+func percentFunded(boost *Boost, raised float64) float64 {
+	if boost.GoalAmount <= 0 {
+		return 0
+	}
+	return raised / boost.GoalAmount * 100
+}
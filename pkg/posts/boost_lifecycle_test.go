@@ -0,0 +1,198 @@
+package posts_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joincivil/go-common/pkg/email"
+
+	"github.com/joincivil/civil-api-server/pkg/channels"
+	"github.com/joincivil/civil-api-server/pkg/payments"
+	"github.com/joincivil/civil-api-server/pkg/posts"
+)
+
+type boostPaymentHelper struct {
+	raised float64
+}
+
+func (h *boostPaymentHelper) TotalPayments(postID string, currencyCode string) (float64, error) {
+	return h.raised, nil
+}
+
+func (h *boostPaymentHelper) GetChannelTotalProceedsByBoostType(channelID string, boostType string) *payments.ProceedsQueryResult {
+	return &payments.ProceedsQueryResult{PostType: boostType, TotalAmount: "150"}
+}
+
+func (h *boostPaymentHelper) GetSupporterEmailAddresses(postID string) ([]string, error) {
+	return []string{"supporter1@civil.co", "supporter2@civil.co"}, nil
+}
+
+type boostChannelHelper struct{}
+
+func (h *boostChannelHelper) GetChannelAdminUserChannels(channelID string) ([]*channels.Channel, error) {
+	return []*channels.Channel{{EmailAddress: "admin@civil.co"}, {}}, nil
+}
+
+type recordingEmailer struct {
+	sent []*email.SendTemplateEmailRequest
+}
+
+func (e *recordingEmailer) SendTemplateEmail(req *email.SendTemplateEmailRequest) error {
+	e.sent = append(e.sent, req)
+	return nil
+}
+
+func TestBoostLifecycle(t *testing.T) {
+	persister := initPersister(t)
+	paymentHelper := &boostPaymentHelper{raised: 50}
+	emailer := &recordingEmailer{}
+	service := posts.NewBoostLifecycleService(persister, paymentHelper, &boostChannelHelper{}, emailer, posts.BoostLifecycleConfig{
+		NotifyWindow:               24 * time.Hour,
+		EndedEmailTemplateID:       "ended",
+		GoalReachedEmailTemplateID: "goal",
+	})
+
+	boost := makeValidBoost()
+	boost.GoalAmount = 100
+	boost.DateEnd = time.Now().Add(time.Hour)
+	created := helperCreatePost(t, persister, boost).(*posts.Boost)
+
+	status, err := service.Status(created)
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if status != posts.BoostStatusActive {
+		t.Fatalf("expected new boost to be active, got %v", status)
+	}
+	percent, err := service.PercentFunded(created)
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if percent != 50 {
+		t.Fatalf("expected boost to be 50 percent funded, got %v", percent)
+	}
+
+	lifecycle, err := service.Process(created, time.Now())
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if lifecycle.Status != posts.BoostStatusActive || lifecycle.GoalReachedAt != nil || len(emailer.sent) != 0 {
+		t.Fatalf("expected an underfunded active boost to stay active without email")
+	}
+
+	paymentHelper.raised = 120
+	lifecycle, err = service.Process(created, time.Now())
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if lifecycle.GoalReachedAt == nil || lifecycle.Status != posts.BoostStatusActive {
+		t.Fatalf("expected the goal to be recorded as reached while the boost is active")
+	}
+	if len(emailer.sent) != 2 || emailer.sent[0].TemplateID != "goal" {
+		t.Fatalf("expected goal reached emails to both supporters, got %v", len(emailer.sent))
+	}
+
+	// processing again does not repeat the emails
+	_, err = service.Process(created, time.Now())
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if len(emailer.sent) != 2 {
+		t.Fatalf("expected no more emails, got %v", len(emailer.sent))
+	}
+
+	lifecycle, err = service.Process(created, created.DateEnd.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if lifecycle.Status != posts.BoostStatusGoalMet || lifecycle.EndedAt == nil || lifecycle.SettledAt == nil {
+		t.Fatalf("expected ended boost to be settled as goal met, got %v", lifecycle.Status)
+	}
+	if len(emailer.sent) != 3 || emailer.sent[2].TemplateID != "ended" || emailer.sent[2].ToEmail != "admin@civil.co" {
+		t.Fatalf("expected an ended email to the channel admin")
+	}
+	if emailer.sent[2].TemplateData["channel_boost_proceeds_total_usd"] != "150" {
+		t.Fatalf("expected ended email to include the channel's boost proceeds")
+	}
+
+	status, err = service.Status(created)
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if status != posts.BoostStatusGoalMet {
+		t.Fatalf("expected settled status, got %v", status)
+	}
+}
+
+func TestBoostLifecycleGoalMissed(t *testing.T) {
+	persister := initPersister(t)
+	emailer := &recordingEmailer{}
+	service := posts.NewBoostLifecycleService(persister, &boostPaymentHelper{raised: 10}, &boostChannelHelper{}, emailer, posts.BoostLifecycleConfig{
+		NotifyWindow:         24 * time.Hour,
+		EndedEmailTemplateID: "ended",
+	})
+
+	recent := makeValidBoost()
+	recent.DateEnd = time.Now().Add(-time.Hour)
+	recentBoost := helperCreatePost(t, persister, recent).(*posts.Boost)
+
+	status, err := service.Status(recentBoost)
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if status != posts.BoostStatusEnded {
+		t.Fatalf("expected unprocessed boost past its end date to be ended, got %v", status)
+	}
+
+	lifecycle, err := service.Process(recentBoost, time.Now())
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if lifecycle.Status != posts.BoostStatusGoalMissed || len(emailer.sent) != 1 {
+		t.Fatalf("expected boost to be settled as goal missed with an email to the admin")
+	}
+
+	// boosts that ended before the notify window are settled quietly
+	old := makeValidBoost()
+	old.DateEnd = time.Now().Add(-48 * time.Hour)
+	oldBoost := helperCreatePost(t, persister, old).(*posts.Boost)
+
+	lifecycle, err = service.Process(oldBoost, time.Now())
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if lifecycle.Status != posts.BoostStatusGoalMissed || len(emailer.sent) != 1 {
+		t.Fatalf("expected old boost to be settled without email")
+	}
+
+	unsettled, err := persister.GetUnsettledBoosts()
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	for _, boost := range unsettled {
+		if boost.ID == recentBoost.ID || boost.ID == oldBoost.ID {
+			t.Fatalf("was not expecting settled boosts to be returned as unsettled")
+		}
+	}
+}
+
+func TestNotifyBoostUpdate(t *testing.T) {
+	emailer := &recordingEmailer{}
+	service := posts.NewBoostLifecycleService(nil, &boostPaymentHelper{}, &boostChannelHelper{}, emailer, posts.BoostLifecycleConfig{
+		SiteURL:               "https://registry.civil.co/",
+		UpdateEmailTemplateID: "update",
+	})
+
+	boost := &posts.Boost{PostModel: posts.PostModel{ID: "boost1"}, Title: "new microphones"}
+	update := &posts.BoostUpdate{PostModel: posts.PostModel{ID: "update1"}, Text: "we bought them"}
+	service.NotifyBoostUpdate(boost, update)
+
+	if len(emailer.sent) != 2 {
+		t.Fatalf("expected an email to each supporter, got %v", len(emailer.sent))
+	}
+	data := emailer.sent[0].TemplateData
+	if emailer.sent[0].TemplateID != "update" || data["update_text"] != "we bought them" ||
+		data["boost_url"] != "https://registry.civil.co/boosts/boost1" {
+		t.Fatalf("unexpected boost update email: %+v", emailer.sent[0])
+	}
+}
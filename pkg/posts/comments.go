@@ -0,0 +1,153 @@
+package posts
+
+import (
+	"errors"
+)
+
+const (
+	defaultMaxCommentDepth = 5
+)
+
+// errors
+var (
+	ErrThreadLocked   = errors.New("comment thread is locked")
+	ErrCommentTooDeep = errors.New("comment is nested too deeply")
+	ErrNotAComment    = errors.New("post is not a comment")
+)
+
+// commentDepth returns the depth of a new reply to `parentPost`, failing if the reply would be nested too deeply
+// or any comment it replies to has been locked
+func (s *Service) commentDepth(parentPost Post) (int, error) {
+	parentComment, ok := parentPost.(*Comment)
+	if !ok {
+		return 0, nil
+	}
+
+	_, ancestors, err := s.commentThread(parentComment)
+	if err != nil {
+		return 0, err
+	}
+	for _, ancestor := range ancestors {
+		moderation, err := s.GetCommentModeration(ancestor.ID)
+		if err != nil && err != ErrorNotFound {
+			return 0, err
+		}
+		if moderation != nil && moderation.Locked {
+			return 0, ErrThreadLocked
+		}
+	}
+
+	depth := parentComment.Depth + 1
+	if depth > s.maxCommentDepth {
+		return 0, ErrCommentTooDeep
+	}
+	return depth, nil
+}
+
+// commentThread returns the post at the root of a comment's thread, and the comment and its ancestor comments
+func (s *Service) commentThread(comment *Comment) (Post, []*Comment, error) {
+	ancestors := []*Comment{comment}
+	for {
+		current := ancestors[len(ancestors)-1]
+		if current.ParentID == nil {
+			return nil, nil, ErrBadParentID
+		}
+		parent, err := s.GetPost(*current.ParentID)
+		if err != nil {
+			return nil, nil, err
+		}
+		parentComment, ok := parent.(*Comment)
+		if !ok {
+			return parent, ancestors, nil
+		}
+		if len(ancestors) > s.maxCommentDepth {
+			// guards against a cycle of comments, which would otherwise never reach a root
+			return nil, nil, ErrCommentTooDeep
+		}
+		ancestors = append(ancestors, parentComment)
+	}
+}
+
+// GetComments returns the comments directly under a post. Hidden comments are included when the viewer
+// is an admin of the channel the thread belongs to
+func (s *Service) GetComments(viewerUserID string, parentID string, sort CommentSort, limit int, offset int) (*PostSearchResult, error) {
+	parent, err := s.GetPost(parentID)
+	if err != nil {
+		return nil, err
+	}
+	root := parent
+	if parentComment, ok := parent.(*Comment); ok {
+		root, _, err = s.commentThread(parentComment)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	includeHidden := false
+	if viewerUserID != "" {
+		includeHidden, err = s.channelService.IsChannelAdmin(viewerUserID, root.GetPostModel().ChannelID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.SearchComments(parentID, sort, includeHidden, limit, offset)
+}
+
+// HideComment hides or restores a comment, which only admins of the channel the thread belongs to can do
+func (s *Service) HideComment(requestorUserID string, commentID string, hidden bool) (*CommentModeration, error) {
+	return s.moderateComment(requestorUserID, commentID, func(moderation *CommentModeration) {
+		moderation.Hidden = hidden
+	})
+}
+
+// PinComment pins a comment above its siblings, or unpins it
+func (s *Service) PinComment(requestorUserID string, commentID string, pinned bool) (*CommentModeration, error) {
+	return s.moderateComment(requestorUserID, commentID, func(moderation *CommentModeration) {
+		moderation.Pinned = pinned
+	})
+}
+
+// LockCommentThread prevents, or allows again, new replies anywhere beneath a comment
+func (s *Service) LockCommentThread(requestorUserID string, commentID string, locked bool) (*CommentModeration, error) {
+	return s.moderateComment(requestorUserID, commentID, func(moderation *CommentModeration) {
+		moderation.Locked = locked
+	})
+}
+
+func (s *Service) moderateComment(requestorUserID string, commentID string, apply func(*CommentModeration)) (*CommentModeration, error) {
+	post, err := s.GetPost(commentID)
+	if err != nil {
+		return nil, err
+	}
+	comment, ok := post.(*Comment)
+	if !ok {
+		return nil, ErrNotAComment
+	}
+	root, _, err := s.commentThread(comment)
+	if err != nil {
+		return nil, err
+	}
+
+	isAdmin, err := s.channelService.IsChannelAdmin(requestorUserID, root.GetPostModel().ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, ErrorNotAuthorized
+	}
+
+	moderation, err := s.GetCommentModeration(commentID)
+	if err == ErrorNotFound {
+		moderation = &CommentModeration{PostID: commentID}
+	} else if err != nil {
+		return nil, err
+	}
+	apply(moderation)
+	moderation.ModeratorID = requestorUserID
+
+	if err = s.SaveCommentModeration(moderation); err != nil {
+		return nil, err
+	}
+	return moderation, nil
+}
@@ -0,0 +1,73 @@
+package posts
+
+import (
+	"errors"
+
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/joincivil/civil-api-server/pkg/images"
+)
+
+// coverImageVariants are the sizes a Boost's cover image is saved in
+var coverImageVariants = []images.Variant{
+	{Name: "small", MaxWidth: 480, MaxHeight: 480},
+	{Name: "medium", MaxWidth: 960, MaxHeight: 960},
+	{Name: "large", MaxWidth: 1920, MaxHeight: 1920},
+}
+
+// errors
+var (
+	ErrNotABoost = errors.New("post is not a boost")
+)
+
+// BoostImageStorer saves images to a blob store as resized variants
+type BoostImageStorer interface {
+	StoreDataURL(keyPrefix string, dataURL string, variants []images.Variant) (*images.Image, error)
+	Delete(img *images.Image)
+}
+
+// SetBoostCoverImage replaces the cover image of a Boost with a png or jpeg data URL, or removes it if `dataURL`
+// is nil. Only admins of the boost's channel can do this
+func (s *Service) SetBoostCoverImage(requestorUserID string, postID string, dataURL *string) (*Boost, error) {
+	post, err := s.GetPost(postID)
+	if err != nil {
+		return nil, err
+	}
+	boost, ok := post.(*Boost)
+	if !ok {
+		return nil, ErrNotABoost
+	}
+
+	isAdmin, err := s.channelService.IsChannelAdmin(requestorUserID, boost.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, ErrorNotAuthorized
+	}
+	if err = s.requireChannelNotSuspended(boost.ChannelID); err != nil {
+		return nil, err
+	}
+
+	previous := boost.CoverImage
+	boost.CoverImage = nil
+	if dataURL != nil {
+		// each image gets its own prefix, so that a replaced image is never served from a stale cache
+		keyPrefix := "boosts/" + boost.ID + "/cover/" + uuid.NewV4().String()
+		boost.CoverImage, err = s.imageStorer.StoreDataURL(keyPrefix, *dataURL, coverImageVariants)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = s.UpdateBoostCoverImage(boost); err != nil {
+		if boost.CoverImage != nil {
+			s.imageStorer.Delete(boost.CoverImage)
+		}
+		return nil, err
+	}
+	if previous != nil {
+		s.imageStorer.Delete(previous)
+	}
+	return boost, nil
+}
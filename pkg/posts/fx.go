@@ -5,7 +5,10 @@ import "go.uber.org/fx"
 // PostModule builds post services
 var PostModule = fx.Options(
 	fx.Provide(
+		NewStoryfeedRegistryFromConfig,
 		NewDBPostPersister,
-		NewService,
+		NewServiceFromConfig,
+		NewExternalLinkRefresherFromConfig,
+		NewBoostLifecycleServiceFromConfig,
 	),
 )
@@ -12,9 +12,14 @@ type Paging struct {
 
 // SearchInput provides fields to filter and search for posts
 type SearchInput struct {
+	Query        string
 	PostType     string
 	ChannelID    string
 	AuthorID     string
 	CreatedAfter time.Time
+	// IncludeDrafts includes draft and scheduled posts in the results
+	IncludeDrafts bool
+	// Tag restricts the results to posts with the tag of this slug
+	Tag string
 	Paging
 }
@@ -1,10 +1,16 @@
 package posts
 
 import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jinzhu/gorm/dialects/postgres"
+	"github.com/joincivil/civil-api-server/pkg/images"
 	"github.com/joincivil/civil-api-server/pkg/payments"
+	uuid "github.com/satori/go.uuid"
 )
 
 // TYPES
@@ -12,6 +18,7 @@ const (
 	TypeBoost               = "boost"
 	TypeExternalLink        = "externallink"
 	TypeComment             = "comment"
+	TypeBoostUpdate         = "boost_update"
 	TypePost                = "posts"
 	TypeCommentAnnouncement = "comment_announcement"
 	TypeCommentPrompt       = "comment_prompt"
@@ -34,14 +41,65 @@ type Pagination struct {
 
 // PostSearchResult includes SearchResults of Posts
 type PostSearchResult struct {
-	Posts []Post
+	Posts      []Post
+	Highlights []*PostSearchHighlight
+	// Cursors holds the storyfeed position of each post, in the same order as Posts
+	Cursors []*StoryfeedCursor
 	Pagination
 }
 
+// PostSearchHighlight contains the relevance and highlighted snippet of a post matched by a full-text search
+type PostSearchHighlight struct {
+	PostID string
+	Rank   float64
+	// Snippet is html escaped, with the matches wrapped in <mark> tags
+	Snippet string
+}
+
 // StoryfeedFilter contains fields used to filter storyfeed query
 type StoryfeedFilter struct {
-	Alg       string
-	ChannelID *string
+	Alg          string
+	ChannelID    *string
+	FollowedOnly *bool
+	// FollowerUserID is the user whose followed channels are used when FollowedOnly is set
+	FollowerUserID string
+	// Tag restricts the storyfeed to posts with the tag of this slug
+	Tag string
+}
+
+// StoryfeedCursor is the position of a post within a storyfeed, used to fetch the posts that follow it
+type StoryfeedCursor struct {
+	Rank     int64
+	SortDate time.Time
+	ID       string
+	// AsOf is when the first page of the storyfeed was fetched. Posts published after it are left out of later pages
+	AsOf time.Time
+}
+
+// String returns the cursor serialized as "rank,sort_date,id,as_of"
+func (c *StoryfeedCursor) String() string {
+	return fmt.Sprintf("%d,%s,%s,%s", c.Rank, c.SortDate.Format(time.RFC3339Nano), c.ID, c.AsOf.Format(time.RFC3339Nano))
+}
+
+// ParseStoryfeedCursor parses a cursor serialized with StoryfeedCursor.String
+func ParseStoryfeedCursor(s string) (*StoryfeedCursor, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return nil, ErrorBadStoryfeedCursor
+	}
+	rank, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, ErrorBadStoryfeedCursor
+	}
+	sortDate, err := time.Parse(time.RFC3339Nano, parts[1])
+	if err != nil {
+		return nil, ErrorBadStoryfeedCursor
+	}
+	asOf, err := time.Parse(time.RFC3339Nano, parts[3])
+	if err != nil {
+		return nil, ErrorBadStoryfeedCursor
+	}
+	return &StoryfeedCursor{Rank: rank, SortDate: sortDate, ID: parts[2], AsOf: asOf}, nil
 }
 
 // PostModel contains fields common to all types of Posts
@@ -50,13 +108,19 @@ type PostModel struct {
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 	DeletedAt    *time.Time
-	ParentID     *string `gorm:"type:uuid;index:idx_post_parent_id"`
-	ChannelID    string  `gorm:"type:uuid;index:idx_post_channel_id"`
-	AuthorID     string  `gorm:"type:uuid;index:idx_post_author_id;not null"`
-	PostType     string  `gorm:"index:idx_post_type"`
-	Reference    *string `gorm:"unique_index:idx_post_reference"`
+	ParentID     *string    `gorm:"type:uuid;index:idx_post_parent_id"`
+	ChannelID    string     `gorm:"type:uuid;index:idx_post_channel_id"`
+	AuthorID     string     `gorm:"type:uuid;index:idx_post_author_id;not null"`
+	PostType     string     `gorm:"index:idx_post_type"`
+	Reference    *string    `gorm:"unique_index:idx_post_reference"`
+	Draft        bool       `gorm:"not null;default:false"` // only visible to channel admins until published
+	PublishAt    *time.Time `gorm:"index:idx_post_publish_at"`
+	Hidden       bool       `gorm:"not null;default:false"` // hidden by platform moderators
 	Data         postgres.Jsonb
 	PostPayments []*payments.PaymentModel `gorm:"polymorphic:Owner;"`
+	Tags         []Tag                    `gorm:"many2many:post_tags;jointable_foreignkey:post_id;association_jointable_foreignkey:tag_id"`
+	// TagNames are the tags to set on the post when it is created or edited, nil leaves the tags unchanged
+	TagNames []string `gorm:"-"`
 }
 
 // TableName returns the gorm table name for Base
@@ -90,6 +154,8 @@ type Boost struct {
 	What         string      `json:"what,omitempty"`
 	About        string      `json:"about,omitempty"`
 	Items        []BoostItem `json:"items,omitempty"`
+	// CoverImage is set with Service.SetBoostCoverImage, it is not changed by edits
+	CoverImage *images.Image `json:"cover_image,omitempty"`
 }
 
 // BoostItem describes the items within a boost
@@ -103,11 +169,46 @@ func (b Boost) GetType() string {
 	return TypeBoost
 }
 
+// Boost lifecycle statuses. A boost is a draft until it is published, is active until its end date,
+// and once ended is settled as goal met or goal missed
+const (
+	BoostStatusDraft      = "draft"
+	BoostStatusActive     = "active"
+	BoostStatusEnded      = "ended"
+	BoostStatusGoalMet    = "goal_met"
+	BoostStatusGoalMissed = "goal_missed"
+)
+
+// BoostLifecycle tracks the status transitions of a Boost and the notifications sent for them
+type BoostLifecycle struct {
+	PostID               string `gorm:"type:uuid;primary_key"`
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Status               string `gorm:"not null;index:idx_boostlifecycle_status"`
+	GoalReachedAt        *time.Time
+	EndedAt              *time.Time
+	SettledAt            *time.Time
+	AdminsNotifiedAt     *time.Time
+	SupportersNotifiedAt *time.Time
+}
+
+// TableName returns the gorm table name for BoostLifecycle
+func (BoostLifecycle) TableName() string {
+	return "boost_lifecycles"
+}
+
+// IsSettled returns whether the boost has reached a final status
+func (l *BoostLifecycle) IsSettled() bool {
+	return l.Status == BoostStatusGoalMet || l.Status == BoostStatusGoalMissed
+}
+
 // Comment is a type of Post that contains just type
 type Comment struct {
 	PostModel   `json:"-"`
 	Text        string `json:"text"`
 	CommentType string `json:"comment_type"`
+	// Depth is how deeply the comment is nested, 0 for a comment directly under a post and one more than its parent for a reply
+	Depth int `json:"depth,omitempty"`
 }
 
 // GetType returns the post type "Comment"
@@ -115,6 +216,126 @@ func (b Comment) GetType() string {
 	return TypeComment
 }
 
+// CommentSort is the order comments in a thread are returned in
+type CommentSort string
+
+// Comment sorts. Pinned comments always come first
+const (
+	CommentSortNewest        CommentSort = "NEWEST"
+	CommentSortOldest        CommentSort = "OLDEST"
+	CommentSortMostSupported CommentSort = "MOST_SUPPORTED"
+)
+
+// CommentModeration is the moderation applied to a Comment by an admin of the channel the thread is under
+type CommentModeration struct {
+	PostID      string `gorm:"type:uuid;primary_key"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ModeratorID string `gorm:"type:uuid"`
+	// Hidden comments are only shown to channel admins
+	Hidden bool `gorm:"not null;default:false"`
+	// Pinned comments are shown before their siblings
+	Pinned bool `gorm:"not null;default:false"`
+	// Locked comments do not accept new replies anywhere beneath them
+	Locked bool `gorm:"not null;default:false"`
+}
+
+// TableName returns the gorm table name for CommentModeration
+func (CommentModeration) TableName() string {
+	return "comment_moderations"
+}
+
+// ReactionEmojis is the fixed set of emoji users can react to posts with
+var ReactionEmojis = []string{"👍", "❤️", "😂", "😮", "😢", "🔥"}
+
+// IsReactionEmoji returns whether the emoji is one users can react with
+func IsReactionEmoji(emoji string) bool {
+	for _, reactionEmoji := range ReactionEmojis {
+		if emoji == reactionEmoji {
+			return true
+		}
+	}
+	return false
+}
+
+// PostReaction is a free reaction to a Post, users can react to a post once with each emoji
+type PostReaction struct {
+	ID        string `gorm:"type:uuid;primary_key"`
+	CreatedAt time.Time
+	PostID    string `gorm:"type:uuid;not null;unique_index:idx_post_reaction_post_user_emoji"`
+	UserID    string `gorm:"type:uuid;not null;unique_index:idx_post_reaction_post_user_emoji"`
+	Emoji     string `gorm:"not null;unique_index:idx_post_reaction_post_user_emoji"`
+}
+
+// TableName returns the gorm table name for PostReaction
+func (PostReaction) TableName() string {
+	return "post_reactions"
+}
+
+// BeforeCreate is a GORM hook that sets the ID before it its persisted
+func (r *PostReaction) BeforeCreate() (err error) {
+	id := uuid.NewV4()
+	r.ID = id.String()
+	return
+}
+
+// ReactionCount is the number of reactions to a Post with an emoji
+type ReactionCount struct {
+	PostID string
+	Emoji  string
+	Count  int
+	// ViewerReacted is whether the user viewing the post reacted with the emoji
+	ViewerReacted bool
+}
+
+// Tag categorizes Posts by topic, such as local news or elections
+type Tag struct {
+	ID        string `gorm:"type:uuid;primary_key"`
+	CreatedAt time.Time
+	Name      string `gorm:"not null"`
+	// Slug is the normalized form of the name, two tags whose names differ only in case or punctuation are the same tag
+	Slug string `gorm:"not null;unique_index:idx_tag_slug"`
+}
+
+// TableName returns the gorm table name for Tag
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// BeforeCreate is a GORM hook that sets the ID before it its persisted
+func (t *Tag) BeforeCreate() (err error) {
+	if t.ID == "" {
+		t.ID = uuid.NewV4().String()
+	}
+	return
+}
+
+// TagCount is the number of published posts with a Tag
+type TagCount struct {
+	Name  string
+	Slug  string
+	Count int
+}
+
+// BoostUpdate is a type of Post, always a child of a Boost, that tells supporters how the boost is progressing
+type BoostUpdate struct {
+	PostModel `json:"-"`
+	// Text is the rich text body of the update
+	Text   string             `json:"text"`
+	Images []BoostUpdateImage `json:"images,omitempty"`
+}
+
+// BoostUpdateImage is an image included in a BoostUpdate
+type BoostUpdateImage struct {
+	URL     string `json:"url"`
+	Caption string `json:"caption,omitempty"`
+}
+
+// GetType returns the post type "BoostUpdate"
+func (b BoostUpdate) GetType() string {
+	return TypeBoostUpdate
+}
+
 // ExternalLink is a type of Post that links to another web page
 type ExternalLink struct {
 	PostModel     `json:"-"`
@@ -128,3 +349,68 @@ type ExternalLink struct {
 func (b ExternalLink) GetType() string {
 	return TypeExternalLink
 }
+
+// ExternalLinkRefresh tracks the periodic OpenGraph refresh of an ExternalLink post, along with
+// the metadata the post had before its most recent change
+type ExternalLinkRefresh struct {
+	PostID                string `gorm:"type:uuid;primary_key"`
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+	NextRefreshAt         time.Time `gorm:"index:idx_extlinkrefresh_next_refresh_at"`
+	IntervalSecs          int64
+	LastAttemptAt         *time.Time
+	LastSuccessAt         *time.Time
+	LastChangedAt         *time.Time
+	FailureCount          int
+	LastError             string
+	PreviousOpenGraphData []byte
+	PreviousPublishedTime *time.Time
+}
+
+// TableName returns the gorm table name for ExternalLinkRefresh
+func (ExternalLinkRefresh) TableName() string {
+	return "external_link_refreshes"
+}
+
+// PostRevision is an immutable record of an edit made to a Post
+type PostRevision struct {
+	ID        string `gorm:"type:uuid;primary_key"`
+	CreatedAt time.Time
+	PostID    string `gorm:"type:uuid;not null;unique_index:idx_post_revision_number"`
+	Revision  int    `gorm:"not null;unique_index:idx_post_revision_number"`
+	EditorID  string `gorm:"type:uuid;not null"`
+	// Data is a snapshot of the post data after the edit
+	Data postgres.Jsonb
+	// Changes holds the PostRevisionChanges made by the edit
+	Changes postgres.Jsonb
+}
+
+// TableName returns the gorm table name for PostRevision
+func (PostRevision) TableName() string {
+	return "post_revisions"
+}
+
+// BeforeCreate is a GORM hook that sets the ID before it its persisted
+func (r *PostRevision) BeforeCreate() (err error) {
+	if r.ID == "" {
+		r.ID = uuid.NewV4().String()
+	}
+	return
+}
+
+// GetChanges returns the field level changes made by the edit
+func (r *PostRevision) GetChanges() ([]*PostRevisionChange, error) {
+	var changes []*PostRevisionChange
+	if len(r.Changes.RawMessage) == 0 {
+		return changes, nil
+	}
+	err := json.Unmarshal(r.Changes.RawMessage, &changes)
+	return changes, err
+}
+
+// PostRevisionChange is the old and new value of a single field changed by an edit, values are JSON encoded
+type PostRevisionChange struct {
+	Field    string          `json:"field"`
+	OldValue json.RawMessage `json:"old_value,omitempty"`
+	NewValue json.RawMessage `json:"new_value,omitempty"`
+}
@@ -1,5 +1,7 @@
 package posts
 
+import "time"
+
 // PostPersister is an interface for CRUD of Posts
 type PostPersister interface {
 	GetPost(id string) (Post, error)
@@ -9,9 +11,33 @@ type PostPersister interface {
 	CreatePost(authorID string, post Post) (Post, error)
 	EditPost(requestorUserID string, postID string, patch Post) (Post, error)
 	DeletePost(requestorUserID string, id string) error
+	UpdatePostPublishing(postID string, draft bool, publishAt *time.Time) (Post, error)
+	SetPostHidden(postID string, hidden bool) error
+	PublishDuePosts(now time.Time) ([]Post, error)
+	GetPostRevisions(postID string) ([]*PostRevision, error)
+	GetPostRevision(postID string, n int) (*PostRevision, error)
+	IsPostEditedAfterPayments(postID string) (bool, error)
 	SearchPosts(search *SearchInput) (*PostSearchResult, error)
 	SearchPostsMostRecentPerChannel(search *SearchInput) (*PostSearchResult, error)
-	SearchPostsRanked(limit int, offset int, filter *StoryfeedFilter) (*PostSearchResult, error)
+	SearchPostsRanked(limit int, after *StoryfeedCursor, filter *StoryfeedFilter) (*PostSearchResult, error)
 	SearchChildren(parentID string, limit int, offset int) (*PostSearchResult, error)
+	SearchComments(parentID string, sort CommentSort, includeHidden bool, limit int, offset int) (*PostSearchResult, error)
+	GetCommentModeration(postID string) (*CommentModeration, error)
+	SaveCommentModeration(moderation *CommentModeration) error
+	AddReaction(userID string, postID string, emoji string) error
+	RemoveReaction(userID string, postID string, emoji string) error
+	GetReactionCounts(postIDs []string, viewerUserID string) ([][]*ReactionCount, error)
+	SetPostTags(postID string, tags []Tag) ([]Tag, error)
+	GetTagsForPosts(postIDs []string) ([][]*Tag, error)
+	GetPopularTags(limit int) ([]*TagCount, error)
+	GetExternalLinksDueForRefresh(createdAfter time.Time, unrefreshedBefore time.Time, limit int) ([]*ExternalLink, error)
+	GetExternalLinkRefresh(postID string) (*ExternalLinkRefresh, error)
+	SaveExternalLinkRefresh(refresh *ExternalLinkRefresh) error
+	UpdateExternalLinkMetadata(link *ExternalLink) error
+	UpdateBoostCoverImage(boost *Boost) error
+	GetUnsettledBoosts() ([]*Boost, error)
+	GetBoostLifecycle(postID string) (*BoostLifecycle, error)
+	SaveBoostLifecycle(lifecycle *BoostLifecycle) error
+	StoryfeedAlgorithms() []StoryfeedAlgorithm
 	CreateViews() error
 }
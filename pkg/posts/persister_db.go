@@ -1,14 +1,20 @@
 package posts
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	log "github.com/golang/glog"
 	"github.com/jinzhu/gorm"
 	"github.com/jinzhu/gorm/dialects/postgres"
+	"github.com/joincivil/civil-api-server/pkg/channels"
+	"github.com/joincivil/civil-api-server/pkg/payments"
 	paginator "github.com/pilagod/gorm-cursor-paginator"
 	uuid "github.com/satori/go.uuid"
+	"sort"
+	"strconv"
 	"time"
 )
 
@@ -27,6 +33,18 @@ var (
 	ErrorNoReferenceURLFound = errors.New("no canonical URL or Open Graph URL found on submitted page")
 	// ErrorBadFilterProvided is thrown when storyfeed cannot be queried due to bad filter input
 	ErrorBadFilterProvided = errors.New("bad storyfeed filter provided")
+	// ErrorBadSearchCursor is thrown when a full-text search is given a cursor it did not produce
+	ErrorBadSearchCursor = errors.New("bad search cursor provided")
+	// ErrorBadStoryfeedCursor is thrown when a storyfeed cursor cannot be parsed
+	ErrorBadStoryfeedCursor = errors.New("bad storyfeed cursor provided")
+	// ErrorNotExternalLink is thrown when an ExternalLink operation is attempted on another type of post
+	ErrorNotExternalLink = errors.New("post is not an external link")
+	// ErrorExternalLinkUnavailable is thrown when the page an external link points to responds with an error status
+	ErrorExternalLinkUnavailable = errors.New("external link responded with an error status")
+	// ErrorBadImageURL is thrown when an image URL is not an absolute http or https URL
+	ErrorBadImageURL = errors.New("bad image URL submitted")
+	// ErrorChannelSuspended is thrown when posting to a channel that platform moderators have suspended
+	ErrorChannelSuspended = errors.New("channel is suspended from posting")
 )
 
 const (
@@ -34,188 +52,102 @@ const (
 	chronologicalBoostViewName        = "vw_post_boost_chronological"
 	fairThenChronologicalViewName     = "vw_post_fair_then_chronological_2"
 	fairWithInterleavedBoostsViewName = "vw_post_fair_with_interleaved_boosts_2"
+	trendingViewName                  = "vw_post_trending"
+
+	// StoryfeedAlgFairWithInterleavedBoosts is the name of the fair then chronological storyfeed with boosts interleaved
+	StoryfeedAlgFairWithInterleavedBoosts = fairWithInterleavedBoostsViewName
+
+	searchIndexName    = "idx_post_search_document"
+	defaultSearchLimit = 10
+
+	postTagsTableName = "post_tags"
 )
 
 // DBPostPersister implements PostPersister interface using Gorm for database persistence
 type DBPostPersister struct {
-	db *gorm.DB
+	db         *gorm.DB
+	storyfeeds *StoryfeedRegistry
 }
 
 // NewDBPostPersister builds a new DbPostPersister
-func NewDBPostPersister(db *gorm.DB) PostPersister {
+func NewDBPostPersister(db *gorm.DB, storyfeeds *StoryfeedRegistry) PostPersister {
 	return &DBPostPersister{
 		db,
+		storyfeeds,
 	}
 }
 
 // CreateViews creates the views if they don't exist
 func (p *DBPostPersister) CreateViews() error {
-	createStoryfeedViewQuery := CreateChronologicalStoryfeedViewQuery(chronologicalExternallinkViewName)
-	db := p.db.Exec(createStoryfeedViewQuery)
-	if db.Error != nil {
-		return fmt.Errorf("Error creating chronological storyfeed view in postgres: %v", db.Error)
-	}
-	createBoostfeedViewQuery := CreateChronologicalBoostfeedViewQuery(chronologicalBoostViewName)
-	db = p.db.Exec(createBoostfeedViewQuery)
-	if db.Error != nil {
-		return fmt.Errorf("Error creating chronological boostfeed view in postgres: %v", db.Error)
+	for _, alg := range p.storyfeeds.Algorithms() {
+		db := p.db.Exec(alg.CreateViewQuery())
+		if db.Error != nil {
+			return fmt.Errorf("Error creating %s storyfeed view in postgres: %v", alg.Name(), db.Error)
+		}
 	}
-	createStoryfeedViewQuery = CreateFairThenChronologicalStoryfeedViewQuery(fairThenChronologicalViewName)
-	db = p.db.Exec(createStoryfeedViewQuery)
+	db := p.db.Exec(CreatePostSearchFunctionsQuery())
 	if db.Error != nil {
-		return fmt.Errorf("Error creating fair then chronological storyfeed view in postgres: %v", db.Error)
+		return fmt.Errorf("Error creating post search functions in postgres: %v", db.Error)
 	}
-	createStoryfeedViewQuery = CreateFairWithInterleavedBoostsStoryfeedViewQuery(fairWithInterleavedBoostsViewName)
-	db = p.db.Exec(createStoryfeedViewQuery)
+	db = p.db.Exec(CreatePostSearchIndexQuery(searchIndexName))
 	if db.Error != nil {
-		return fmt.Errorf("Error creating fair with interleaved boosts storyfeed view in postgres: %v", db.Error)
+		return fmt.Errorf("Error creating post search index in postgres: %v", db.Error)
 	}
 	return nil
 }
 
-// CreateChronologicalStoryfeedViewQuery returns the query to create the storyfeed view
-func CreateChronologicalStoryfeedViewQuery(viewName string) string {
-	queryString := fmt.Sprintf(`
-	CREATE OR REPLACE VIEW %s as (
-		select *, coalesce((data ->> 'published_time')::timestamptz, created_at)::timestamptz as sort_date 
-		from posts 
-		where post_type = 'externallink'
-		order by sort_date desc
-	)
-    `, viewName)
-	return queryString
-}
-
-func (p *DBPostPersister) getRawChannelChronologicalStoryfeedQuery(channelID string) *gorm.DB {
-	return p.db.Raw("select *, coalesce((data ->> 'published_time')::timestamptz, created_at)::timestamptz as sort_date from posts where post_type = 'externallink' and channel_id = ? order by sort_date desc", channelID)
-}
-
-// CreateChronologicalBoostfeedViewQuery returns the query to create the boostfeed view
-func CreateChronologicalBoostfeedViewQuery(viewName string) string {
-	queryString := fmt.Sprintf(`
-	CREATE OR REPLACE VIEW %s as (
-		select * 
-		from posts 
-		where post_type = 'boost'
-		order by created_at desc
-	)
-    `, viewName)
-	return queryString
-}
-
-func (p *DBPostPersister) getRawChannelChronologicalBoostfeedQuery(channelID string) *gorm.DB {
-	return p.db.Raw("select *, coalesce((data ->> 'published_time')::timestamptz, created_at)::timestamptz as sort_date from posts where post_type = 'boost' and channel_id = ? order by sort_date desc", channelID)
-}
-
-// CreateFairThenChronologicalStoryfeedViewQuery returns the query to create the storyfeed view
-func CreateFairThenChronologicalStoryfeedViewQuery(viewName string) string {
-	queryString := fmt.Sprintf(`
-	CREATE OR REPLACE VIEW %s as (
-		select *, (case when post_num = 1 then 1 ELSE null end) as rank  FROM
-		(
-			select
-				*,
-				count(1) over (partition by channel_id order by (sort_date) desc) as post_num
-				from
-				(
-				select 
-					*, 
-					coalesce((data ->> 'published_time')::timestamptz, created_at)::timestamptz as sort_date
-					from posts
-					where post_type = 'externallink'
-				) data2
-				
-		) data
-		order by rank, sort_date desc
-	)
-    `, viewName)
-	return queryString
-}
-
-// the "fair the chronological" is identical to "chronological" for a single channel
-func (p *DBPostPersister) getRawChannelFairThenChronologicalStoryfeedQuery(channelID string) *gorm.DB {
-	return p.db.Raw("select *, coalesce((data ->> 'published_time')::timestamptz, created_at)::timestamptz as sort_date from posts where post_type = 'externallink' and channel_id = ? order by sort_date desc", channelID)
-}
-
-// CreateFairWithInterleavedBoostsStoryfeedViewQuery returns the query to create the storyfeed view
-func CreateFairWithInterleavedBoostsStoryfeedViewQuery(viewName string) string {
-	// nolint: gosec
-	queryString := fmt.Sprintf(`
-	CREATE OR REPLACE VIEW %s as (
-		SELECT * FROM (
-			select *, (case when post_num = 1 then 1 ELSE null end) as rank, ROW_NUMBER() OVER (ORDER BY (case when post_num = 1 then 1 ELSE null end), sort_date desc) as row_rank  FROM
-			(
-				select
-					*,
-					count(1) over (partition by channel_id order by (sort_date) desc) as post_num
-					from
-					(
-					select 
-						*, 
-						coalesce((data ->> 'published_time')::timestamptz, created_at)::timestamptz as sort_date
-						from posts
-						where post_type = 'externallink'
-					) data2
-					
-			) data
-			order by row_rank desc
-		) data3
-
-		UNION
-
-		SELECT * FROM
-		(
-			SELECT *, 1 as rank, ROW_NUMBER() OVER (ORDER BY sort_date) * 5 as row_rank FROM
-			(
-				SELECT *, created_at as sort_date, 1 as post_num FROM posts where post_type = 'boost' and (data ->> 'date_end')::timestamp > now()
-				
-			) data2
-			order by sort_date desc
-		) data4
-		order by row_rank
-	)
-    `, viewName)
-	return queryString
-}
-
-func (p *DBPostPersister) getRawChannelFairWithInterleavedBoostsStoryfeedQuery(channelID string) *gorm.DB {
-	return p.db.Raw(`
-		SELECT * FROM (
-			SELECT *, (CASE WHEN post_num = 1 THEN 1 ELSE NULL END) AS rank, ROW_NUMBER() OVER (ORDER BY (CASE WHEN post_num = 1 THEN 1 ELSE NULL END), sort_date desc) AS row_rank  FROM
-			(
-				SELECT
-					*,
-					COUNT(1) OVER (PARTITION BY channel_id order by (sort_date) desc) AS post_num
-					FROM
-					(
-					SELECT 
-						*, 
-						coalesce((data ->> 'published_time')::timestamptz, created_at)::timestamptz AS sort_date
-						FROM posts
-						WHERE post_type = 'externallink'
-						AND channel_id = ?
-					) data2
-					
-			) data
-			ORDER BY row_rank desc
-		) data3
-
-		UNION
-
-		SELECT * FROM
-		(
-			SELECT *, 1 AS rank, ROW_NUMBER() OVER (ORDER BY sort_date) * 3 AS row_rank FROM
-			(
-				SELECT *, created_at AS sort_date, 1 AS post_num
-				FROM posts
-				WHERE post_type = 'boost' AND
-				(data ->> 'date_end')::timestamp > now() AND
-				channel_id = ?
-				
-			) data2
-			ORDER BY sort_date desc
-		) data4
-		ORDER BY row_rank`, channelID, channelID)
+// StoryfeedAlgorithms returns the storyfeed algorithms that can be used with SearchPostsRanked
+func (p *DBPostPersister) StoryfeedAlgorithms() []StoryfeedAlgorithm {
+	return p.storyfeeds.Algorithms()
+}
+
+// CreatePostSearchFunctionsQuery returns the query to create the functions used for full-text search of posts.
+// open_graph_data is stored as base64 encoded JSON, so it is decoded before the title and description are pulled out.
+// Snippets are highlighted in the text with html escaped, so the only markup they contain is the highlighting.
+// The functions are marked IMMUTABLE so they can be used in an expression index.
+func CreatePostSearchFunctionsQuery() string {
+	return `
+	CREATE OR REPLACE FUNCTION post_open_graph_data(data jsonb) RETURNS jsonb AS $$
+		SELECT CASE WHEN jsonb_typeof(data -> 'open_graph_data') = 'string'
+			THEN convert_from(decode(data ->> 'open_graph_data', 'base64'), 'UTF8')::jsonb
+			ELSE NULL END
+	$$ LANGUAGE sql IMMUTABLE;
+
+	CREATE OR REPLACE FUNCTION post_search_text(data jsonb) RETURNS text AS $$
+		SELECT concat_ws(' ',
+			data ->> 'text',
+			data ->> 'why',
+			data ->> 'what',
+			data ->> 'about',
+			post_open_graph_data(data) ->> 'title',
+			post_open_graph_data(data) ->> 'description'
+		)
+	$$ LANGUAGE sql IMMUTABLE;
+
+	CREATE OR REPLACE FUNCTION post_search_snippet_text(data jsonb) RETURNS text AS $$
+		SELECT replace(replace(replace(replace(post_search_text(data),
+			'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;')
+	$$ LANGUAGE sql IMMUTABLE;
+
+	CREATE OR REPLACE FUNCTION post_search_document(data jsonb) RETURNS tsvector AS $$
+		SELECT
+			setweight(to_tsvector('english', coalesce(data ->> 'text', '')), 'A') ||
+			setweight(to_tsvector('english', coalesce(post_open_graph_data(data) ->> 'title', '')), 'A') ||
+			setweight(to_tsvector('english', concat_ws(' ',
+				data ->> 'why',
+				data ->> 'what',
+				data ->> 'about',
+				post_open_graph_data(data) ->> 'description'
+			)), 'B')
+	$$ LANGUAGE sql IMMUTABLE;
+	`
+}
+
+// CreatePostSearchIndexQuery returns the query to create the GIN index used for full-text search of posts
+func CreatePostSearchIndexQuery(indexName string) string {
+	return fmt.Sprintf(`
+	CREATE INDEX IF NOT EXISTS %s ON posts USING gin(post_search_document(data))
+	`, indexName)
 }
 
 // CreatePost creates a new Post and saves it to the database
@@ -230,6 +162,10 @@ func (p *DBPostPersister) CreatePost(authorID string, post Post) (Post, error) {
 			return nil, ErrorBadBoostEndDate
 		}
 	}
+	// posts scheduled for the future stay drafts until they are published
+	if base.PublishAt != nil && base.PublishAt.After(time.Now()) {
+		base.Draft = true
+	}
 	base.AuthorID = authorID
 	if err = p.db.Create(base).Error; err != nil {
 		log.Errorf("An error occurred: %v\n", err)
@@ -260,7 +196,7 @@ func (p *DBPostPersister) GetNumChildrenOfPost(id string) (int, error) {
 	}
 	var postModels []PostModel
 
-	if err := p.db.Where(&PostModel{ParentID: &id}).Find(&postModels).Error; err != nil {
+	if err := p.db.Where(&PostModel{ParentID: &id}).Where("draft = ? AND hidden = ?", false, false).Find(&postModels).Error; err != nil {
 		return 0, ErrorNotFound
 	}
 
@@ -274,7 +210,7 @@ func (p *DBPostPersister) GetChildrenOfPost(id string) ([]Post, error) {
 	}
 	var postModels []PostModel
 
-	if err := p.db.Where(&PostModel{ParentID: &id}).Find(&postModels).Error; err != nil {
+	if err := p.db.Where(&PostModel{ParentID: &id}).Where("draft = ? AND hidden = ?", false, false).Find(&postModels).Error; err != nil {
 		return nil, ErrorNotFound
 	}
 
@@ -324,6 +260,11 @@ func (p *DBPostPersister) EditPost(requestorUserID string, postID string, patch
 		return nil, ErrorNotAuthorized
 	}
 
+	previous, err := json.Marshal(dbPost)
+	if err != nil {
+		return nil, err
+	}
+
 	// turn the patch into a JSON object
 	jsonPatch, err := json.Marshal(patch)
 	if err != nil {
@@ -346,15 +287,146 @@ func (p *DBPostPersister) EditPost(requestorUserID string, postID string, patch
 	jsonData := json.RawMessage(jsonPost)
 	patched := postgres.Jsonb{RawMessage: jsonData}
 
+	changes, err := diffPostData(previous, jsonPost)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := p.db.Begin()
 	// update the Post with the new patched JSON object
-	if err = p.db.Model(&PostModel{ID: postID}).Update("data", patched).Error; err != nil {
+	if err = tx.Model(&PostModel{ID: postID}).Update("data", patched).Error; err != nil {
+		tx.Rollback()
 		log.Errorf("error updating post: %v", err)
 		return nil, err
 	}
 
+	// record the edit as a revision, unless nothing changed
+	if len(changes) > 0 {
+		if err = createPostRevision(tx, postID, requestorUserID, patched, changes); err != nil {
+			tx.Rollback()
+			log.Errorf("error creating post revision: %v", err)
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
 	return dbPost, nil
 }
 
+// GetPostRevisions retrieves the revisions of a post, oldest first
+func (p *DBPostPersister) GetPostRevisions(postID string) ([]*PostRevision, error) {
+	var revisions []*PostRevision
+	err := p.db.Where(&PostRevision{PostID: postID}).Order("revision").Find(&revisions).Error
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// GetPostRevision retrieves revision `n` of a post, revisions are numbered from 1
+func (p *DBPostPersister) GetPostRevision(postID string, n int) (*PostRevision, error) {
+	revision := &PostRevision{}
+	if p.db.Where(&PostRevision{PostID: postID, Revision: n}).First(revision).RecordNotFound() {
+		return nil, ErrorNotFound
+	}
+	return revision, nil
+}
+
+// IsPostEditedAfterPayments returns whether the post was edited after it received its first completed payment,
+// including payments that were since partially refunded
+func (p *DBPostPersister) IsPostEditedAfterPayments(postID string) (bool, error) {
+	var result struct {
+		Edited bool
+	}
+	// nolint: gosec
+	err := p.db.Raw(fmt.Sprintf(`
+		select exists(
+			select 1 from %s r
+			where r.post_id = ?
+			and r.created_at > (
+				select min(created_at) from %s
+				where owner_id = ? and owner_type = ? and status in ('complete', 'partially_refunded') and deleted_at is null
+			)
+		) as edited`, PostRevision{}.TableName(), payments.PaymentModel{}.TableName()),
+		postID, postID, TypePost).Scan(&result).Error
+	if err != nil {
+		return false, err
+	}
+	return result.Edited, nil
+}
+
+func createPostRevision(tx *gorm.DB, postID string, editorID string, data postgres.Jsonb, changes []*PostRevisionChange) error {
+	var latest struct {
+		Revision int
+	}
+	err := tx.Raw("select coalesce(max(revision), 0) as revision from "+PostRevision{}.TableName()+" where post_id = ?", postID).
+		Scan(&latest).Error
+	if err != nil {
+		return err
+	}
+
+	jsonChanges, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&PostRevision{
+		PostID:   postID,
+		Revision: latest.Revision + 1,
+		EditorID: editorID,
+		Data:     data,
+		Changes:  postgres.Jsonb{RawMessage: json.RawMessage(jsonChanges)},
+	}).Error
+}
+
+// diffPostData compares the top level fields of two JSON encoded posts and returns the fields that changed, sorted by name
+func diffPostData(previous []byte, current []byte) ([]*PostRevisionChange, error) {
+	var before, after map[string]json.RawMessage
+	if err := json.Unmarshal(previous, &before); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(current, &after); err != nil {
+		return nil, err
+	}
+
+	fields := map[string]bool{}
+	for field := range before {
+		fields[field] = true
+	}
+	for field := range after {
+		fields[field] = true
+	}
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	changes := []*PostRevisionChange{}
+	for _, field := range names {
+		oldValue, newValue := before[field], after[field]
+		if bytes.Equal(compactJSON(oldValue), compactJSON(newValue)) {
+			continue
+		}
+		changes = append(changes, &PostRevisionChange{Field: field, OldValue: oldValue, NewValue: newValue})
+	}
+	return changes, nil
+}
+
+func compactJSON(value json.RawMessage) []byte {
+	if len(value) == 0 {
+		return nil
+	}
+	buf := &bytes.Buffer{}
+	if err := json.Compact(buf, value); err != nil {
+		return value
+	}
+	return buf.Bytes()
+}
+
 // DeletePost removes a post from the database (soft_deletes by setting deleted_at flag)
 func (p *DBPostPersister) DeletePost(requestorUserID string, id string) error {
 
@@ -366,37 +438,268 @@ func (p *DBPostPersister) DeletePost(requestorUserID string, id string) error {
 	return nil
 }
 
-func (p *DBPostPersister) getRawStoryfeedQuery(limit int, offset int, storyfeedViewName string, channelID *string) *gorm.DB {
-	if channelID == nil {
-		return p.db.Raw(fmt.Sprintf("select * from %s limit %d offset %d", storyfeedViewName, limit, offset))
+// UpdatePostPublishing sets whether a post is a draft and when it is published
+func (p *DBPostPersister) UpdatePostPublishing(postID string, draft bool, publishAt *time.Time) (Post, error) {
+	postModel := &PostModel{ID: postID}
+	err := p.db.Model(postModel).Updates(map[string]interface{}{"draft": draft, "publish_at": publishAt}).Error
+	if err != nil {
+		log.Errorf("error updating post: %v", err)
+		return nil, err
+	}
+	return p.GetPost(postID)
+}
+
+// SetPostHidden hides a post from everyone but platform moderators, or restores it
+func (p *DBPostPersister) SetPostHidden(postID string, hidden bool) error {
+	err := p.db.Model(&PostModel{ID: postID}).Update("hidden", hidden).Error
+	if err != nil {
+		log.Errorf("error updating post: %v", err)
+		return err
 	}
+	return nil
+}
+
+// PublishDuePosts publishes the drafts scheduled to be published by `now` and returns them
+func (p *DBPostPersister) PublishDuePosts(now time.Time) ([]Post, error) {
+	var dbPosts []PostModel
+	err := p.db.Raw(`
+		UPDATE posts SET draft = false, updated_at = ?
+		WHERE draft AND deleted_at IS NULL AND publish_at <= ?
+		RETURNING *`, now, now).Scan(&dbPosts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	published := make([]Post, 0, len(dbPosts))
+	for i := range dbPosts {
+		post, err := BaseToPostInterface(&dbPosts[i])
+		if err != nil {
+			return nil, err
+		}
+		published = append(published, post)
+	}
+	return published, nil
+}
 
-	switch storyfeedViewName {
-	case chronologicalExternallinkViewName:
-		return p.getRawChannelChronologicalStoryfeedQuery(*channelID)
-	case chronologicalBoostViewName:
-		return p.getRawChannelChronologicalBoostfeedQuery(*channelID)
-	case fairThenChronologicalViewName:
-		return p.getRawChannelFairThenChronologicalStoryfeedQuery(*channelID)
-	case fairWithInterleavedBoostsViewName:
-		return p.getRawChannelFairWithInterleavedBoostsStoryfeedQuery(*channelID)
+// GetExternalLinksDueForRefresh retrieves ExternalLinks created after `createdAfter` whose OpenGraph data is due to be refreshed.
+// Posts that have never been refreshed are due once they were created before `unrefreshedBefore`
+func (p *DBPostPersister) GetExternalLinksDueForRefresh(createdAfter time.Time, unrefreshedBefore time.Time, limit int) ([]*ExternalLink, error) {
+	var dbPosts []PostModel
+	err := p.db.Table(PostModel{}.TableName()).
+		Select("posts.*").
+		Joins("LEFT JOIN "+ExternalLinkRefresh{}.TableName()+" r ON r.post_id = posts.id").
+		Where("posts.post_type = ? AND posts.deleted_at IS NULL AND posts.created_at > ?", TypeExternalLink, createdAfter).
+		Where("(r.post_id IS NULL AND posts.created_at <= ?) OR r.next_refresh_at <= ?", unrefreshedBefore, time.Now()).
+		Order("coalesce(r.next_refresh_at, posts.created_at)").
+		Limit(limit).
+		Scan(&dbPosts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	links := make([]*ExternalLink, 0, len(dbPosts))
+	for i := range dbPosts {
+		post, err := BaseToPostInterface(&dbPosts[i])
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, post.(*ExternalLink))
+	}
+	return links, nil
+}
+
+// GetExternalLinkRefresh retrieves the refresh state of an ExternalLink
+func (p *DBPostPersister) GetExternalLinkRefresh(postID string) (*ExternalLinkRefresh, error) {
+	refresh := &ExternalLinkRefresh{}
+	if p.db.Where(&ExternalLinkRefresh{PostID: postID}).First(refresh).RecordNotFound() {
+		return nil, ErrorNotFound
+	}
+	return refresh, nil
+}
+
+// SaveExternalLinkRefresh creates or updates the refresh state of an ExternalLink
+func (p *DBPostPersister) SaveExternalLinkRefresh(refresh *ExternalLinkRefresh) error {
+	return p.db.Save(refresh).Error
+}
+
+// UpdateExternalLinkMetadata stores the OpenGraph data and published time of an ExternalLink
+func (p *DBPostPersister) UpdateExternalLinkMetadata(link *ExternalLink) error {
+	base, err := PostInterfaceToBase(*link)
+	if err != nil {
+		return err
 	}
+	if err = p.db.Model(&PostModel{ID: link.ID}).Update("data", base.Data).Error; err != nil {
+		log.Errorf("error updating post: %v", err)
+		return err
+	}
+	return nil
+}
 
+// UpdateBoostCoverImage stores the cover image of a Boost
+func (p *DBPostPersister) UpdateBoostCoverImage(boost *Boost) error {
+	base, err := PostInterfaceToBase(*boost)
+	if err != nil {
+		return err
+	}
+	if err = p.db.Model(&PostModel{ID: boost.ID}).Update("data", base.Data).Error; err != nil {
+		log.Errorf("error updating post: %v", err)
+		return err
+	}
 	return nil
 }
 
+// GetUnsettledBoosts retrieves the Boosts that have not yet been settled as goal met or goal missed
+func (p *DBPostPersister) GetUnsettledBoosts() ([]*Boost, error) {
+	var dbPosts []PostModel
+	err := p.db.Table(PostModel{}.TableName()).
+		Select("posts.*").
+		Joins("LEFT JOIN "+BoostLifecycle{}.TableName()+" l ON l.post_id = posts.id").
+		Where("posts.post_type = ? AND posts.deleted_at IS NULL AND NOT posts.draft", TypeBoost).
+		Where("l.post_id IS NULL OR l.status NOT IN (?)", []string{BoostStatusGoalMet, BoostStatusGoalMissed}).
+		Order("posts.created_at").
+		Scan(&dbPosts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	boosts := make([]*Boost, 0, len(dbPosts))
+	for i := range dbPosts {
+		post, err := BaseToPostInterface(&dbPosts[i])
+		if err != nil {
+			return nil, err
+		}
+		boosts = append(boosts, post.(*Boost))
+	}
+	return boosts, nil
+}
+
+// GetBoostLifecycle retrieves the lifecycle state of a Boost
+func (p *DBPostPersister) GetBoostLifecycle(postID string) (*BoostLifecycle, error) {
+	lifecycle := &BoostLifecycle{}
+	if p.db.Where(&BoostLifecycle{PostID: postID}).First(lifecycle).RecordNotFound() {
+		return nil, ErrorNotFound
+	}
+	return lifecycle, nil
+}
+
+// SaveBoostLifecycle creates or updates the lifecycle state of a Boost
+func (p *DBPostPersister) SaveBoostLifecycle(lifecycle *BoostLifecycle) error {
+	return p.db.Save(lifecycle).Error
+}
+
+func (p *DBPostPersister) getRawStoryfeedQuery(limit int, after *StoryfeedCursor, storyfeedViewName string, channelID *string,
+	followerUserID string, tagSlug string) *gorm.DB {
+	alg, ok := p.storyfeeds.Algorithm(storyfeedViewName)
+	if !ok {
+		return nil
+	}
+
+	// later pages rank the posts that were published when the first page was fetched
+	var asOf *time.Time
+	if after != nil {
+		asOf = &after.AsOf
+	}
+	query, args := alg.GlobalQuery(asOf)
+	if channelID != nil {
+		query, args = alg.ChannelQuery(*channelID, asOf)
+	}
+	if followerUserID != "" {
+		query, args = followedChannelsQuery(query, args, followerUserID)
+	}
+	if tagSlug != "" {
+		query, args = taggedPostsQuery(query, args, tagSlug)
+	}
+
+	query, args = storyfeedPageQuery(query, args, alg.RankExpression(), limit, after)
+	return p.db.Raw(query, args...)
+}
+
+// followedChannelsQuery restricts a storyfeed query to posts from channels the user follows
+func followedChannelsQuery(query string, args []interface{}, userID string) (string, []interface{}) {
+	// nolint: gosec
+	followedQuery := fmt.Sprintf(`
+		SELECT * FROM (%s) followed
+		WHERE channel_id IN (SELECT channel_id FROM %s WHERE user_id = ?)`,
+		query, channels.ChannelFollow{}.TableName())
+	return followedQuery, append(append([]interface{}{}, args...), userID)
+}
+
+// taggedPostsQuery restricts a storyfeed query to posts with the tag of the given slug
+func taggedPostsQuery(query string, args []interface{}, tagSlug string) (string, []interface{}) {
+	// nolint: gosec
+	taggedQuery := fmt.Sprintf(`
+		SELECT * FROM (%s) tagged
+		WHERE %s`,
+		query, taggedPostsCondition("id"))
+	return taggedQuery, append(append([]interface{}{}, args...), tagSlug)
+}
+
+// taggedPostsCondition returns the condition that `idColumn` is a post with the tag of the slug given as its argument
+func taggedPostsCondition(idColumn string) string {
+	return fmt.Sprintf(`%s IN (
+		SELECT pt.post_id FROM %s pt JOIN %s t ON t.id = pt.tag_id WHERE t.slug = ?)`,
+		idColumn, postTagsTableName, Tag{}.TableName())
+}
+
+// storyfeedPageQuery wraps a storyfeed query so that it returns the `limit` posts that come after the cursor.
+// Posts are ordered by (feed_rank, sort_date desc, id desc), which is also the key stored in StoryfeedCursor
+func storyfeedPageQuery(query string, args []interface{}, rankExpression string, limit int, after *StoryfeedCursor) (string, []interface{}) {
+	pageArgs := append([]interface{}{}, args...)
+	where := ""
+	if after != nil {
+		where = `WHERE feed_rank > ?
+			OR (feed_rank = ? AND sort_date < ?)
+			OR (feed_rank = ? AND sort_date = ? AND id < ?)`
+		pageArgs = append(pageArgs, after.Rank, after.Rank, after.SortDate, after.Rank, after.SortDate, after.ID)
+	}
+	pageArgs = append(pageArgs, limit)
+
+	// nolint: gosec
+	pageQuery := fmt.Sprintf(`
+		SELECT * FROM (
+			SELECT *, %s AS feed_rank FROM (%s) base
+		) feed
+		%s
+		ORDER BY feed_rank, sort_date desc, id desc
+		LIMIT ?`, rankExpression, query, where)
+	return pageQuery, pageArgs
+}
+
+// storyfeedRow is a post returned from a storyfeed along with the keys used to order it
+type storyfeedRow struct {
+	PostModel
+	FeedRank int64
+	SortDate time.Time
+}
+
 // SearchPostsRanked retrieves most recent externallink post for each channel, followed by all the rest of the posts in reverse chronological order
-func (p *DBPostPersister) SearchPostsRanked(limit int, offset int, filter *StoryfeedFilter) (*PostSearchResult, error) {
-	var dbResults []PostModel
+func (p *DBPostPersister) SearchPostsRanked(limit int, after *StoryfeedCursor, filter *StoryfeedFilter) (*PostSearchResult, error) {
+	var dbResults []storyfeedRow
 
 	var channelID *string
+	var followerUserID string
+	var tagSlug string
 	storyfeedViewName := fairThenChronologicalViewName // backwards compatible for queries that don't include filter
 	if filter != nil {
-		storyfeedViewName = filter.Alg
+		if filter.Alg != "" {
+			storyfeedViewName = filter.Alg
+		}
 		channelID = filter.ChannelID
+		if filter.FollowedOnly != nil && *filter.FollowedOnly {
+			if filter.FollowerUserID == "" {
+				return nil, ErrorBadFilterProvided
+			}
+			followerUserID = filter.FollowerUserID
+		}
+		tagSlug = filter.Tag
 	}
 
-	stmt := p.getRawStoryfeedQuery(limit, offset, storyfeedViewName, channelID)
+	asOf := time.Now().UTC()
+	if after != nil {
+		asOf = after.AsOf
+	}
+
+	stmt := p.getRawStoryfeedQuery(limit, after, storyfeedViewName, channelID, followerUserID, tagSlug)
 	if stmt == nil {
 		return nil, ErrorBadFilterProvided
 	}
@@ -408,16 +711,23 @@ func (p *DBPostPersister) SearchPostsRanked(limit int, offset int, filter *Story
 	}
 
 	var posts []Post
+	var cursors []*StoryfeedCursor
 	for _, result := range dbResults {
-		post, err := BaseToPostInterface(&result)
+		post, err := BaseToPostInterface(&result.PostModel)
 		if err != nil {
 			log.Errorf("An error occurred: %v\n", err)
 			return nil, err
 		}
 		posts = append(posts, post)
+		cursors = append(cursors, &StoryfeedCursor{
+			Rank:     result.FeedRank,
+			SortDate: result.SortDate,
+			ID:       result.ID,
+			AsOf:     asOf,
+		})
 	}
 
-	response := &PostSearchResult{Posts: posts}
+	response := &PostSearchResult{Posts: posts, Cursors: cursors}
 
 	return response, nil
 }
@@ -427,10 +737,11 @@ func (p *DBPostPersister) SearchPostsMostRecentPerChannel(search *SearchInput) (
 	var dbResults []PostModel
 
 	pager := initModelPaginatorFrom(search.Paging)
-	stmt := p.db.Where("created_at IN(SELECT MAX(created_at) FROM posts WHERE deleted_at IS NULL GROUP BY channel_id)", search.PostType)
+	stmt := p.db.Where("created_at IN(SELECT MAX(created_at) FROM posts WHERE deleted_at IS NULL AND NOT draft AND NOT hidden GROUP BY channel_id)", search.PostType)
 	if search.PostType != "" {
-		stmt = p.db.Where("created_at IN(SELECT MAX(created_at) FROM posts WHERE deleted_at IS NULL AND post_type = ? GROUP BY channel_id)", search.PostType)
+		stmt = p.db.Where("created_at IN(SELECT MAX(created_at) FROM posts WHERE deleted_at IS NULL AND NOT draft AND NOT hidden AND post_type = ? GROUP BY channel_id)", search.PostType)
 	}
+	stmt = stmt.Where("draft = ? AND hidden = ?", false, false)
 
 	results := pager.Paginate(stmt, &dbResults)
 	if results.Error != nil {
@@ -457,42 +768,152 @@ func (p *DBPostPersister) SearchPostsMostRecentPerChannel(search *SearchInput) (
 
 // SearchPosts retrieves posts matching the search criteria
 func (p *DBPostPersister) SearchPosts(search *SearchInput) (*PostSearchResult, error) {
+	if search.Query != "" {
+		return p.searchPostsFullText(search)
+	}
+
 	var dbResults []PostModel
 	pager := initModelPaginatorFrom(search.Paging)
-	stmt := p.db
+	stmt := searchFilters(p.db, search)
+
+	results := pager.Paginate(stmt, &dbResults)
+	if results.Error != nil {
+		log.Errorf("An error occurred: %v\n", results.Error)
+		return nil, results.Error
+	}
+
+	var posts []Post
+	for _, result := range dbResults {
+		post, err := BaseToPostInterface(&result)
+		if err != nil {
+			log.Errorf("An error occurred: %v\n", err)
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	cursors := pager.GetNextCursors()
+
+	response := &PostSearchResult{Posts: posts, Pagination: Pagination{AfterCursor: cursors.AfterCursor, BeforeCursor: cursors.BeforeCursor}}
+
+	return response, nil
+}
 
+// postSearchRow is a post returned from a full-text search along with its rank and highlighted snippet
+type postSearchRow struct {
+	PostModel
+	SearchRank    float64
+	SearchSnippet string
+}
+
+// searchFilters applies the filters of a search, other than the query, to a statement on posts
+func searchFilters(stmt *gorm.DB, search *SearchInput) *gorm.DB {
 	if search.PostType != "" {
 		stmt = stmt.Where("post_type = ?", search.PostType)
 	}
 	if search.ChannelID != "" {
 		stmt = stmt.Where("channel_id = ?", search.ChannelID)
 	}
+	if search.AuthorID != "" {
+		stmt = stmt.Where("author_id = ?", search.AuthorID)
+	}
+	if !search.CreatedAfter.IsZero() {
+		stmt = stmt.Where("created_at > ?", search.CreatedAfter)
+	}
+	if search.Tag != "" {
+		stmt = stmt.Where(taggedPostsCondition("posts.id"), search.Tag)
+	}
+	if !search.IncludeDrafts {
+		stmt = stmt.Where("draft = ?", false)
+	}
+	return stmt.Where("hidden = ?", false)
+}
 
-	results := pager.Paginate(stmt, &dbResults)
+// searchPostsFullText retrieves posts matching search.Query ordered by relevance
+func (p *DBPostPersister) searchPostsFullText(search *SearchInput) (*PostSearchResult, error) {
+	var dbResults []postSearchRow
+
+	limit := defaultSearchLimit
+	if search.Limit != nil {
+		limit = *search.Limit
+	}
+	offset := 0
+	if search.AfterCursor != nil && *search.AfterCursor != "" {
+		var err error
+		offset, err = decodeSearchCursor(*search.AfterCursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	stmt := p.db.Table("posts").
+		Joins("CROSS JOIN plainto_tsquery('english', ?) search_query", search.Query).
+		Select(`posts.*,
+			ts_rank(post_search_document(data), search_query) AS search_rank,
+			ts_headline('english', post_search_snippet_text(data), search_query,
+				'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10') AS search_snippet`).
+		Where("posts.deleted_at IS NULL").
+		Where("post_search_document(data) @@ search_query")
+	stmt = searchFilters(stmt, search)
+
+	results := stmt.Order("search_rank desc, created_at desc, id").Limit(limit).Offset(offset).Scan(&dbResults)
 	if results.Error != nil {
 		log.Errorf("An error occurred: %v\n", results.Error)
 		return nil, results.Error
 	}
 
 	var posts []Post
+	var highlights []*PostSearchHighlight
 	for _, result := range dbResults {
-		post, err := BaseToPostInterface(&result)
+		post, err := BaseToPostInterface(&result.PostModel)
 		if err != nil {
 			log.Errorf("An error occurred: %v\n", err)
 			return nil, err
 		}
 		posts = append(posts, post)
+		highlights = append(highlights, &PostSearchHighlight{
+			PostID:  result.ID,
+			Rank:    result.SearchRank,
+			Snippet: result.SearchSnippet,
+		})
 	}
 
-	cursors := pager.GetNextCursors()
+	pagination := Pagination{}
+	if len(dbResults) == limit {
+		pagination.AfterCursor = encodeSearchCursor(offset + limit)
+	}
+	if offset > 0 {
+		before := offset - limit
+		if before < 0 {
+			before = 0
+		}
+		pagination.BeforeCursor = encodeSearchCursor(before)
+	}
 
-	response := &PostSearchResult{Posts: posts, Pagination: Pagination{AfterCursor: cursors.AfterCursor, BeforeCursor: cursors.BeforeCursor}}
+	response := &PostSearchResult{Posts: posts, Highlights: highlights, Pagination: pagination}
 
 	return response, nil
 }
 
+func encodeSearchCursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeSearchCursor(cursor string) (int, error) {
+	bys, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrorBadSearchCursor
+	}
+	offset, err := strconv.Atoi(string(bys))
+	if err != nil || offset < 0 {
+		return 0, ErrorBadSearchCursor
+	}
+	return offset, nil
+}
+
 func (p *DBPostPersister) getRawChildrenQuery(parentID string, limit int, offset int) *gorm.DB {
-	return p.db.Raw(fmt.Sprintf("select * from posts where parent_id = '%s' order by created_at limit %d offset %d", parentID, limit, offset))
+	return p.db.Raw(fmt.Sprintf("select * from posts where parent_id = '%s' and not draft and not hidden and id not in (select post_id from %s where hidden) order by created_at limit %d offset %d",
+		parentID, CommentModeration{}.TableName(), limit, offset))
 }
 
 // SearchChildren retrieves most recent children for the given parent post id
@@ -525,6 +946,197 @@ func (p *DBPostPersister) SearchChildren(parentID string, limit int, offset int)
 	return response, nil
 }
 
+// SearchComments retrieves the comments directly under a post, pinned comments first and then in the given sort order.
+// Hidden comments are only included if `includeHidden` is set
+func (p *DBPostPersister) SearchComments(parentID string, sort CommentSort, includeHidden bool, limit int, offset int) (*PostSearchResult, error) {
+	var order string
+	switch sort {
+	case CommentSortNewest, "":
+		order = "posts.created_at desc"
+	case CommentSortOldest:
+		order = "posts.created_at"
+	case CommentSortMostSupported:
+		order = "coalesce(support.usd_amount, 0) desc, posts.created_at desc"
+	default:
+		return nil, ErrorBadFilterProvided
+	}
+
+	// nolint: gosec
+	stmt := p.db.Table(PostModel{}.TableName()).
+		Select("posts.*").
+		Joins("LEFT JOIN "+CommentModeration{}.TableName()+" m ON m.post_id = posts.id").
+		Joins(fmt.Sprintf(`LEFT JOIN (
+			SELECT owner_id::uuid AS post_id, sum((amount - refunded_amount) * exchange_rate) AS usd_amount
+			FROM %s
+			WHERE owner_type = ? AND status IN ('complete', 'partially_refunded') AND deleted_at IS NULL
+			GROUP BY owner_id
+		) support ON support.post_id = posts.id`, payments.PaymentModel{}.TableName()), TypePost).
+		Where("posts.parent_id = ? AND posts.post_type = ?", parentID, TypeComment).
+		Where("posts.deleted_at IS NULL AND NOT posts.draft AND NOT posts.hidden")
+	if !includeHidden {
+		stmt = stmt.Where("NOT coalesce(m.hidden, false)")
+	}
+
+	var dbResults []PostModel
+	err := stmt.Order("coalesce(m.pinned, false) desc, " + order + ", posts.id").
+		Limit(limit).
+		Offset(offset).
+		Scan(&dbResults).Error
+	if err != nil {
+		log.Errorf("An error occurred: %v\n", err)
+		return nil, err
+	}
+
+	var posts []Post
+	for i := range dbResults {
+		post, err := BaseToPostInterface(&dbResults[i])
+		if err != nil {
+			log.Errorf("An error occurred: %v\n", err)
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return &PostSearchResult{Posts: posts}, nil
+}
+
+// GetCommentModeration retrieves the moderation applied to a comment
+func (p *DBPostPersister) GetCommentModeration(postID string) (*CommentModeration, error) {
+	moderation := &CommentModeration{}
+	if p.db.Where(&CommentModeration{PostID: postID}).First(moderation).RecordNotFound() {
+		return nil, ErrorNotFound
+	}
+	return moderation, nil
+}
+
+// SaveCommentModeration creates or updates the moderation applied to a comment
+func (p *DBPostPersister) SaveCommentModeration(moderation *CommentModeration) error {
+	return p.db.Save(moderation).Error
+}
+
+// AddReaction records a user reacting to a post with an emoji, reacting again with the same emoji has no effect
+func (p *DBPostPersister) AddReaction(userID string, postID string, emoji string) error {
+	reaction := &PostReaction{}
+	return p.db.Where(&PostReaction{PostID: postID, UserID: userID, Emoji: emoji}).FirstOrCreate(reaction).Error
+}
+
+// RemoveReaction removes a user's reaction to a post with an emoji
+func (p *DBPostPersister) RemoveReaction(userID string, postID string, emoji string) error {
+	return p.db.Where(&PostReaction{PostID: postID, UserID: userID, Emoji: emoji}).Delete(&PostReaction{}).Error
+}
+
+// GetReactionCounts retrieves the number of reactions with each emoji for each of the given posts, in the order
+// of `postIDs`, and whether `viewerUserID` reacted with them
+func (p *DBPostPersister) GetReactionCounts(postIDs []string, viewerUserID string) ([][]*ReactionCount, error) {
+	var counts []*ReactionCount
+	err := p.db.Table(PostReaction{}.TableName()).
+		Select("post_id, emoji, count(*) AS count, bool_or(user_id::text = ?) AS viewer_reacted", viewerUserID).
+		Where("post_id IN (?)", postIDs).
+		Group("post_id, emoji").
+		Order("min(created_at)").
+		Scan(&counts).Error
+	if err != nil {
+		log.Errorf("An error occurred: %v\n", err)
+		return nil, err
+	}
+
+	countsByPost := make(map[string][]*ReactionCount, len(postIDs))
+	for _, count := range counts {
+		countsByPost[count.PostID] = append(countsByPost[count.PostID], count)
+	}
+	results := make([][]*ReactionCount, len(postIDs))
+	for i, postID := range postIDs {
+		results[i] = countsByPost[postID]
+		if results[i] == nil {
+			results[i] = []*ReactionCount{}
+		}
+	}
+	return results, nil
+}
+
+// SetPostTags replaces the tags of a post, creating any tags that do not exist yet, and returns them
+func (p *DBPostPersister) SetPostTags(postID string, tags []Tag) ([]Tag, error) {
+	tx := p.db.Begin()
+	saved := make([]Tag, len(tags))
+	for i, tag := range tags {
+		if err := tx.Where(&Tag{Slug: tag.Slug}).Attrs(&Tag{Name: tag.Name}).FirstOrCreate(&saved[i]).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("error saving tag: %v", err)
+			return nil, err
+		}
+	}
+
+	association := tx.Model(&PostModel{ID: postID}).Association("Tags")
+	if len(saved) == 0 {
+		association = association.Clear()
+	} else {
+		association = association.Replace(saved)
+	}
+	if association.Error != nil {
+		tx.Rollback()
+		log.Errorf("error setting post tags: %v", association.Error)
+		return nil, association.Error
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// postTagRow is a tag along with the post it was retrieved for
+type postTagRow struct {
+	Tag
+	PostID string
+}
+
+// GetTagsForPosts retrieves the tags of each of the given posts, in the order of `postIDs`
+func (p *DBPostPersister) GetTagsForPosts(postIDs []string) ([][]*Tag, error) {
+	var rows []*postTagRow
+	err := p.db.Table(Tag{}.TableName()).
+		Select("tags.*, pt.post_id").
+		Joins("JOIN "+postTagsTableName+" pt ON pt.tag_id = tags.id").
+		Where("pt.post_id IN (?)", postIDs).
+		Order("tags.name").
+		Scan(&rows).Error
+	if err != nil {
+		log.Errorf("An error occurred: %v\n", err)
+		return nil, err
+	}
+
+	tagsByPost := make(map[string][]*Tag, len(postIDs))
+	for _, row := range rows {
+		tagsByPost[row.PostID] = append(tagsByPost[row.PostID], &row.Tag)
+	}
+	results := make([][]*Tag, len(postIDs))
+	for i, postID := range postIDs {
+		results[i] = tagsByPost[postID]
+		if results[i] == nil {
+			results[i] = []*Tag{}
+		}
+	}
+	return results, nil
+}
+
+// GetPopularTags retrieves the tags with the most published posts, along with the number of posts
+func (p *DBPostPersister) GetPopularTags(limit int) ([]*TagCount, error) {
+	var counts []*TagCount
+	err := p.db.Table(Tag{}.TableName()).
+		Select("tags.name, tags.slug, count(*) AS count").
+		Joins("JOIN " + postTagsTableName + " pt ON pt.tag_id = tags.id").
+		Joins("JOIN " + PostModel{}.TableName() + " ON posts.id = pt.post_id").
+		Where("posts.deleted_at IS NULL AND NOT posts.draft AND NOT posts.hidden").
+		Group("tags.id, tags.name, tags.slug").
+		Order("count desc, tags.name").
+		Limit(limit).
+		Scan(&counts).Error
+	if err != nil {
+		log.Errorf("An error occurred: %v\n", err)
+		return nil, err
+	}
+	return counts, nil
+}
+
 // initModelPaginatorFrom builds a new paginator
 func initModelPaginatorFrom(page Paging) paginator.Paginator {
 	p := paginator.New()
@@ -591,6 +1203,10 @@ func BaseToPostInterface(base *PostModel) (Post, error) {
 		post = &Comment{
 			PostModel: *base,
 		}
+	case TypeBoostUpdate:
+		post = &BoostUpdate{
+			PostModel: *base,
+		}
 	}
 	err := json.Unmarshal(base.Data.RawMessage, post)
 	if err != nil {
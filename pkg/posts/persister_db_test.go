@@ -2,9 +2,13 @@ package posts_test
 
 import (
 	"github.com/jinzhu/gorm"
+	"github.com/joincivil/civil-api-server/pkg/channels"
+	"github.com/joincivil/civil-api-server/pkg/payments"
 	"github.com/joincivil/civil-api-server/pkg/posts"
 	"github.com/joincivil/civil-api-server/pkg/testruntime"
 	"github.com/joincivil/civil-api-server/pkg/testutils"
+	uuid "github.com/satori/go.uuid"
+	"strings"
 	"testing"
 	"time"
 )
@@ -73,6 +77,8 @@ func makeBadEndDateBoost() posts.Boost {
 
 var db *gorm.DB
 
+var testStoryfeedConfig = posts.StoryfeedConfig{GlobalBoostInterleave: 5, ChannelBoostInterleave: 3}
+
 func initPersister(t *testing.T) posts.PostPersister {
 	if db == nil {
 		testDB, err := testutils.GetTestDBConnection()
@@ -86,7 +92,7 @@ func initPersister(t *testing.T) posts.PostPersister {
 		}
 	}
 
-	return posts.NewDBPostPersister(db)
+	return posts.NewDBPostPersister(db, posts.NewStoryfeedRegistry(testStoryfeedConfig))
 }
 
 func TestCreatePost(t *testing.T) {
@@ -180,6 +186,103 @@ func TestEditPost(t *testing.T) {
 
 }
 
+func TestEditPostRevisions(t *testing.T) {
+	persister := initPersister(t)
+
+	boost := makeValidBoost()
+	boostPost := helperCreatePost(t, persister, boost)
+
+	edited, err := persister.IsPostEditedAfterPayments(boostPost.GetID())
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if edited {
+		t.Fatalf("was not expecting a new boost to be flagged as edited")
+	}
+
+	patch := boost
+	patch.Title = "changed title"
+	_, err = persister.EditPost(aliceUserUUID, boostPost.GetID(), patch)
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+
+	err = db.Create(&payments.PaymentModel{
+		ID:           uuid.NewV4().String(),
+		CreatedAt:    time.Now(),
+		PaymentType:  payments.PaymentTypeStripe,
+		Reference:    uuid.NewV4().String(),
+		Status:       "complete",
+		CurrencyCode: "USD",
+		Amount:       20,
+		ExchangeRate: 1,
+		OwnerID:      boostPost.GetID(),
+		OwnerType:    posts.TypePost,
+	}).Error
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+
+	edited, err = persister.IsPostEditedAfterPayments(boostPost.GetID())
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if edited {
+		t.Fatalf("was not expecting an edit made before the payment to set the flag")
+	}
+
+	patch.GoalAmount = 5000
+	_, err = persister.EditPost(aliceUserUUID, boostPost.GetID(), patch)
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	// an edit that changes nothing does not create a revision
+	_, err = persister.EditPost(aliceUserUUID, boostPost.GetID(), patch)
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+
+	revisions, err := persister.GetPostRevisions(boostPost.GetID())
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("expected 2 revisions, got %v", len(revisions))
+	}
+	if revisions[0].Revision != 1 || revisions[1].Revision != 2 || revisions[1].EditorID != aliceUserUUID {
+		t.Fatalf("expected revisions to be numbered in order with the editor recorded")
+	}
+
+	changes, err := revisions[0].GetChanges()
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Field != "text" || string(changes[0].OldValue) != `"some title"` ||
+		string(changes[0].NewValue) != `"changed title"` {
+		t.Fatalf("expected a single title change, got %+v", changes)
+	}
+
+	revision, err := persister.GetPostRevision(boostPost.GetID(), 2)
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if !strings.Contains(string(revision.Data.RawMessage), "5000") {
+		t.Fatalf("expected revision to snapshot the edited data")
+	}
+	_, err = persister.GetPostRevision(boostPost.GetID(), 3)
+	if err != posts.ErrorNotFound {
+		t.Fatalf("expected ErrorNotFound for a missing revision, got %v", err)
+	}
+
+	edited, err = persister.IsPostEditedAfterPayments(boostPost.GetID())
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if !edited {
+		t.Fatalf("expected boost edited after a payment to be flagged")
+	}
+}
+
 func TestGetPost(t *testing.T) {
 
 	persister := initPersister(t)
@@ -218,3 +321,495 @@ func TestDelete(t *testing.T) {
 		t.Fatalf("expecting ErrorNotFound: %v", err)
 	}
 }
+
+func TestSearchPostsFullText(t *testing.T) {
+	persister := initPersister(t)
+	err := persister.CreateViews()
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+
+	boost := makeValidBoost()
+	boost.Title = "Investigating the watershed"
+	boost.Why = "Nobody else is covering groundwater contamination"
+	helperCreatePost(t, persister, boost)
+
+	markup := makeValidBoost()
+	markup.Title = "Testing wells"
+	markup.Why = "<script>alert(1)</script> groundwater samples"
+	helperCreatePost(t, persister, markup)
+
+	other := makeValidBoost()
+	other.Title = "Local election coverage"
+	helperCreatePost(t, persister, other)
+
+	results, err := persister.SearchPosts(&posts.SearchInput{Query: "groundwater"})
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if len(results.Posts) == 0 {
+		t.Fatalf("expected search to return at least one post")
+	}
+	for _, post := range results.Posts {
+		if post.(*posts.Boost).Title == "Local election coverage" {
+			t.Fatalf("was not expecting non-matching post in results")
+		}
+	}
+	if len(results.Highlights) != len(results.Posts) {
+		t.Fatalf("expected a highlight for every post")
+	}
+	if !strings.Contains(results.Highlights[0].Snippet, "<mark>groundwater</mark>") {
+		t.Fatalf("expected snippet to highlight query term: %v", results.Highlights[0].Snippet)
+	}
+	for _, highlight := range results.Highlights {
+		if strings.Contains(highlight.Snippet, "<script>") {
+			t.Fatalf("expected markup in the post to be escaped in the snippet: %v", highlight.Snippet)
+		}
+	}
+
+	results, err = persister.SearchPosts(&posts.SearchInput{Query: "groundwater", AuthorID: bobUserUUID})
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if len(results.Posts) != 0 {
+		t.Fatalf("expected search to be filtered by author, got %v posts", len(results.Posts))
+	}
+	results, err = persister.SearchPosts(&posts.SearchInput{Query: "groundwater", CreatedAfter: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if len(results.Posts) != 0 {
+		t.Fatalf("expected search to be filtered by creation time, got %v posts", len(results.Posts))
+	}
+
+	limit := 1
+	firstPage, err := persister.SearchPosts(&posts.SearchInput{Query: "groundwater", Paging: posts.Paging{Limit: &limit}})
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if len(firstPage.Posts) != 1 || firstPage.AfterCursor == "" {
+		t.Fatalf("expected a single post and a cursor to the next page")
+	}
+	secondPage, err := persister.SearchPosts(&posts.SearchInput{Query: "groundwater", Paging: posts.Paging{Limit: &limit, AfterCursor: &firstPage.AfterCursor}})
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if len(secondPage.Posts) != 1 || secondPage.Posts[0].GetID() == firstPage.Posts[0].GetID() {
+		t.Fatalf("expected the next page to continue after the first")
+	}
+
+	_, err = persister.SearchPosts(&posts.SearchInput{Query: "groundwater", Paging: posts.Paging{AfterCursor: &aliceUserUUID}})
+	if err != posts.ErrorBadSearchCursor {
+		t.Fatalf("expecting ErrorBadSearchCursor: %v", err)
+	}
+}
+
+func TestStoryfeedCursor(t *testing.T) {
+	cursor := &posts.StoryfeedCursor{
+		Rank:     5,
+		SortDate: time.Date(2019, 10, 1, 12, 30, 0, 123456000, time.UTC),
+		ID:       aliceUserUUID,
+		AsOf:     time.Date(2019, 10, 2, 8, 0, 0, 0, time.UTC),
+	}
+	parsed, err := posts.ParseStoryfeedCursor(cursor.String())
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if parsed.Rank != cursor.Rank || !parsed.SortDate.Equal(cursor.SortDate) || parsed.ID != cursor.ID ||
+		!parsed.AsOf.Equal(cursor.AsOf) {
+		t.Fatalf("expected parsed cursor to match: %v != %v", parsed, cursor)
+	}
+
+	_, err = posts.ParseStoryfeedCursor("10")
+	if err != posts.ErrorBadStoryfeedCursor {
+		t.Fatalf("expecting ErrorBadStoryfeedCursor: %v", err)
+	}
+}
+
+func TestSearchPostsRankedPagination(t *testing.T) {
+	persister := initPersister(t)
+	err := persister.CreateViews()
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		helperCreatePost(t, persister, makeValidBoost())
+	}
+	filter := &posts.StoryfeedFilter{Alg: "vw_post_boost_chronological", ChannelID: &aliceNewsroomUUID}
+
+	firstPage, err := persister.SearchPostsRanked(2, nil, filter)
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if len(firstPage.Posts) != 2 || len(firstPage.Cursors) != 2 {
+		t.Fatalf("expected 2 posts and cursors on the first page")
+	}
+
+	// a post created after the first page should not shift the second page
+	helperCreatePost(t, persister, makeValidBoost())
+
+	secondPage, err := persister.SearchPostsRanked(2, firstPage.Cursors[1], filter)
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	for _, post := range secondPage.Posts {
+		for _, seen := range firstPage.Posts {
+			if post.GetID() == seen.GetID() {
+				t.Fatalf("was not expecting post %v to be returned twice", post.GetID())
+			}
+		}
+	}
+}
+
+func helperCreateLink(t *testing.T, persister posts.PostPersister, channelID string, publishedTime time.Time) posts.Post {
+	return helperCreatePost(t, persister, &posts.ExternalLink{
+		PostModel:     posts.PostModel{ChannelID: channelID},
+		URL:           "https://totallylegitnews.com/" + uuid.NewV4().String(),
+		PublishedTime: &publishedTime,
+	})
+}
+
+func TestSearchPostsRankedPublishWhilePaging(t *testing.T) {
+	persister := initPersister(t)
+	err := persister.CreateViews()
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+
+	for _, alg := range []string{"vw_post_fair_with_interleaved_boosts_2", "vw_post_fair_then_chronological_2"} {
+		channelID := uuid.NewV4().String()
+		now := time.Now()
+		var expected []string
+		for i := 1; i <= 4; i++ {
+			link := helperCreateLink(t, persister, channelID, now.Add(-time.Duration(i)*time.Hour))
+			expected = append(expected, link.GetID())
+		}
+		boost := makeValidBoost()
+		boost.ChannelID = channelID
+		boostPost := helperCreatePost(t, persister, boost)
+		if alg == "vw_post_fair_with_interleaved_boosts_2" {
+			// with a channel interleave of 3 the boost follows the third link
+			expected = append(expected[:3], boostPost.GetID(), expected[3])
+		}
+
+		filter := &posts.StoryfeedFilter{Alg: alg, ChannelID: &channelID}
+		page, err := persister.SearchPostsRanked(2, nil, filter)
+		if err != nil {
+			t.Fatalf("was not expecting an error: %v", err)
+		}
+		var seen []string
+		for _, post := range page.Posts {
+			seen = append(seen, post.GetID())
+		}
+
+		// a new link would take the first rank from the channel's newest link and move every later link down a place
+		helperCreateLink(t, persister, channelID, now)
+
+		for len(page.Posts) > 0 {
+			page, err = persister.SearchPostsRanked(2, page.Cursors[len(page.Cursors)-1], filter)
+			if err != nil {
+				t.Fatalf("was not expecting an error: %v", err)
+			}
+			for _, post := range page.Posts {
+				seen = append(seen, post.GetID())
+			}
+		}
+
+		if len(seen) != len(expected) {
+			t.Fatalf("expected %v posts across the pages of %v, got %v", len(expected), alg, len(seen))
+		}
+		for i := range expected {
+			if seen[i] != expected[i] {
+				t.Fatalf("expected post %v of %v to be %v, got %v", i, alg, expected[i], seen[i])
+			}
+		}
+	}
+}
+
+func TestSearchPostsRankedTrending(t *testing.T) {
+	persister := initPersister(t)
+	err := persister.CreateViews()
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+
+	channelID := uuid.NewV4().String()
+	supported := makeValidBoost()
+	supported.ChannelID = channelID
+	supportedPost := helperCreatePost(t, persister, supported)
+	unsupported := makeValidBoost()
+	unsupported.ChannelID = channelID
+	helperCreatePost(t, persister, unsupported)
+
+	err = db.Create(&payments.PaymentModel{
+		ID:           uuid.NewV4().String(),
+		PaymentType:  payments.PaymentTypeStripe,
+		Reference:    uuid.NewV4().String(),
+		Status:       "complete",
+		CurrencyCode: "USD",
+		Amount:       20,
+		ExchangeRate: 1,
+		OwnerID:      supportedPost.GetID(),
+		OwnerType:    posts.TypePost,
+	}).Error
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+
+	filter := &posts.StoryfeedFilter{Alg: "vw_post_trending", ChannelID: &channelID}
+	results, err := persister.SearchPostsRanked(10, nil, filter)
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if len(results.Posts) != 2 {
+		t.Fatalf("expected both boosts in the trending storyfeed, got %v", len(results.Posts))
+	}
+	if results.Posts[0].GetID() != supportedPost.GetID() {
+		t.Fatalf("expected the boost with a payment to be ranked first")
+	}
+}
+
+func TestDraftAndScheduledPosts(t *testing.T) {
+	persister := initPersister(t)
+	err := persister.CreateViews()
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+
+	channelID := uuid.NewV4().String()
+	live := makeValidBoost()
+	live.ChannelID = channelID
+	livePost := helperCreatePost(t, persister, live)
+	draft := makeValidBoost()
+	draft.ChannelID = channelID
+	draft.Draft = true
+	draftPost := helperCreatePost(t, persister, draft)
+	scheduled := makeValidBoost()
+	scheduled.ChannelID = channelID
+	publishAt := time.Now().Add(time.Hour)
+	scheduled.PublishAt = &publishAt
+	scheduledPost := helperCreatePost(t, persister, scheduled)
+	if !scheduledPost.GetPostModel().Draft {
+		t.Fatalf("expected a post scheduled for the future to be a draft")
+	}
+
+	results, err := persister.SearchPosts(&posts.SearchInput{ChannelID: channelID})
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if len(results.Posts) != 1 || results.Posts[0].GetID() != livePost.GetID() {
+		t.Fatalf("expected only the published post, got %v posts", len(results.Posts))
+	}
+	results, err = persister.SearchPosts(&posts.SearchInput{ChannelID: channelID, IncludeDrafts: true})
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if len(results.Posts) != 3 {
+		t.Fatalf("expected drafts to be included, got %v posts", len(results.Posts))
+	}
+
+	filter := &posts.StoryfeedFilter{Alg: "vw_post_boost_chronological", ChannelID: &channelID}
+	feed, err := persister.SearchPostsRanked(10, nil, filter)
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if len(feed.Posts) != 1 {
+		t.Fatalf("expected drafts to be excluded from the storyfeed, got %v posts", len(feed.Posts))
+	}
+
+	published, err := persister.PublishDuePosts(time.Now())
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	for _, post := range published {
+		if post.GetID() == scheduledPost.GetID() || post.GetID() == draftPost.GetID() {
+			t.Fatalf("was not expecting posts to be published before their time")
+		}
+	}
+	published, err = persister.PublishDuePosts(publishAt.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	found := false
+	for _, post := range published {
+		if post.GetID() == draftPost.GetID() {
+			t.Fatalf("was not expecting an unscheduled draft to be published")
+		}
+		if post.GetID() == scheduledPost.GetID() {
+			found = !post.GetPostModel().Draft
+		}
+	}
+	if !found {
+		t.Fatalf("expected the scheduled post to be published")
+	}
+
+	_, err = persister.UpdatePostPublishing(draftPost.GetID(), false, &publishAt)
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	feed, err = persister.SearchPostsRanked(10, nil, filter)
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if len(feed.Posts) != 3 {
+		t.Fatalf("expected all posts in the storyfeed once published, got %v", len(feed.Posts))
+	}
+}
+
+func TestSearchPostsRankedFollowedOnly(t *testing.T) {
+	persister := initPersister(t)
+	err := persister.CreateViews()
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+
+	followerID := uuid.NewV4().String()
+	followed := makeValidBoost()
+	followed.ChannelID = uuid.NewV4().String()
+	followedPost := helperCreatePost(t, persister, followed)
+	notFollowed := makeValidBoost()
+	notFollowed.ChannelID = uuid.NewV4().String()
+	helperCreatePost(t, persister, notFollowed)
+
+	err = db.Create(&channels.ChannelFollow{ChannelID: followed.ChannelID, UserID: followerID}).Error
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+
+	followedOnly := true
+	filter := &posts.StoryfeedFilter{Alg: "vw_post_boost_chronological", FollowedOnly: &followedOnly}
+	_, err = persister.SearchPostsRanked(10, nil, filter)
+	if err != posts.ErrorBadFilterProvided {
+		t.Fatalf("expected ErrorBadFilterProvided without a follower: %v", err)
+	}
+
+	filter.FollowerUserID = followerID
+	results, err := persister.SearchPostsRanked(10, nil, filter)
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if len(results.Posts) != 1 || results.Posts[0].GetID() != followedPost.GetID() {
+		t.Fatalf("expected only the post from the followed channel")
+	}
+}
+
+func TestPostReactions(t *testing.T) {
+	persister := initPersister(t)
+
+	boost := helperCreatePost(t, persister, makeValidBoost())
+	other := helperCreatePost(t, persister, makeValidBoost())
+	quiet := helperCreatePost(t, persister, makeValidBoost())
+
+	reactions := []struct {
+		userID string
+		postID string
+		emoji  string
+	}{
+		{aliceUserUUID, boost.GetID(), "🔥"},
+		{aliceUserUUID, boost.GetID(), "🔥"}, // reacting twice with the same emoji counts once
+		{bobUserUUID, boost.GetID(), "🔥"},
+		{bobUserUUID, boost.GetID(), "👍"},
+		{bobUserUUID, other.GetID(), "😂"},
+	}
+	for _, reaction := range reactions {
+		if err := persister.AddReaction(reaction.userID, reaction.postID, reaction.emoji); err != nil {
+			t.Fatalf("was not expecting an error adding reaction: %v", err)
+		}
+	}
+
+	counts, err := persister.GetReactionCounts([]string{quiet.GetID(), boost.GetID(), other.GetID()}, aliceUserUUID)
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if len(counts) != 3 || len(counts[0]) != 0 || len(counts[1]) != 2 || len(counts[2]) != 1 {
+		t.Fatalf("expected reaction counts for each post in order")
+	}
+	fire := counts[1][0]
+	if fire.Emoji != "🔥" || fire.Count != 2 || !fire.ViewerReacted {
+		t.Fatalf("expected 2 fire reactions including the viewer's, got %+v", fire)
+	}
+	if counts[1][1].ViewerReacted || counts[2][0].ViewerReacted {
+		t.Fatalf("was not expecting the viewer to have reacted with other emoji")
+	}
+
+	if err = persister.RemoveReaction(aliceUserUUID, boost.GetID(), "🔥"); err != nil {
+		t.Fatalf("was not expecting an error removing reaction: %v", err)
+	}
+	counts, err = persister.GetReactionCounts([]string{boost.GetID()}, "")
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if counts[0][0].Count != 1 || counts[0][0].ViewerReacted {
+		t.Fatalf("expected the removed reaction to no longer be counted")
+	}
+}
+
+func TestPostTags(t *testing.T) {
+	persister := initPersister(t)
+
+	boost := helperCreatePost(t, persister, makeValidBoost())
+	other := helperCreatePost(t, persister, makeValidBoost())
+	untagged := helperCreatePost(t, persister, makeValidBoost())
+
+	elections := posts.Tag{Name: "Elections", Slug: "test-elections"}
+	water := posts.Tag{Name: "Water Rights", Slug: "test-water-rights"}
+	if _, err := persister.SetPostTags(boost.GetID(), []posts.Tag{elections, water}); err != nil {
+		t.Fatalf("was not expecting an error setting tags: %v", err)
+	}
+	saved, err := persister.SetPostTags(other.GetID(), []posts.Tag{{Name: "ELECTIONS", Slug: "test-elections"}})
+	if err != nil {
+		t.Fatalf("was not expecting an error setting tags: %v", err)
+	}
+	if saved[0].Name != "Elections" {
+		t.Fatalf("expected the existing tag to be reused, got %v", saved[0].Name)
+	}
+
+	tags, err := persister.GetTagsForPosts([]string{untagged.GetID(), boost.GetID(), other.GetID()})
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if len(tags) != 3 || len(tags[0]) != 0 || len(tags[1]) != 2 || len(tags[2]) != 1 {
+		t.Fatalf("expected tags for each post in order")
+	}
+	if tags[1][0].Slug != "test-elections" || tags[1][1].Slug != "test-water-rights" {
+		t.Fatalf("expected tags ordered by name, got %v and %v", tags[1][0].Slug, tags[1][1].Slug)
+	}
+
+	results, err := persister.SearchPosts(&posts.SearchInput{Tag: "test-water-rights"})
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if len(results.Posts) != 1 || results.Posts[0].GetID() != boost.GetID() {
+		t.Fatalf("expected only the post tagged with water rights")
+	}
+
+	counts, err := persister.GetPopularTags(100)
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	found := false
+	for _, count := range counts {
+		if count.Slug == "test-elections" {
+			found = true
+			if count.Count != 2 {
+				t.Fatalf("expected 2 posts tagged with elections, got %v", count.Count)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected elections to be a popular tag")
+	}
+
+	// replacing the tags removes those no longer given
+	if _, err = persister.SetPostTags(boost.GetID(), []posts.Tag{}); err != nil {
+		t.Fatalf("was not expecting an error clearing tags: %v", err)
+	}
+	tags, err = persister.GetTagsForPosts([]string{boost.GetID()})
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if len(tags[0]) != 0 {
+		t.Fatalf("expected the post's tags to be cleared")
+	}
+}
@@ -0,0 +1,85 @@
+package posts
+
+import (
+	"time"
+
+	log "github.com/golang/glog"
+
+	"github.com/joincivil/civil-api-server/pkg/utils"
+)
+
+// PublishPost publishes a draft post, which only admins of the post's channel can do. If `publishAt` is in the future
+// the post is scheduled to be published then instead
+func (s *Service) PublishPost(requestorUserID string, postID string, publishAt *time.Time) (Post, error) {
+	post, err := s.GetPost(postID)
+	if err != nil {
+		return nil, err
+	}
+	isAdmin, err := s.channelService.IsChannelAdmin(requestorUserID, post.GetChannelID())
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, ErrorNotAuthorized
+	}
+
+	now := time.Now()
+	draft := publishAt != nil && publishAt.After(now)
+	if !draft {
+		publishAt = &now
+	}
+	published, err := s.UpdatePostPublishing(postID, draft, publishAt)
+	if err != nil {
+		return nil, err
+	}
+	if post.GetPostModel().Draft && !draft {
+		s.notifyPublished(published)
+	}
+	return published, nil
+}
+
+// PublishScheduledPosts publishes the drafts whose scheduled time has come
+func (s *Service) PublishScheduledPosts() error {
+	published, err := s.PublishDuePosts(time.Now())
+	if err != nil {
+		return err
+	}
+	for _, post := range published {
+		s.notifyPublished(post)
+	}
+	return nil
+}
+
+// notifyPublished sends the notifications for a post going live
+func (s *Service) notifyPublished(post Post) {
+	update, ok := post.(*BoostUpdate)
+	if !ok || s.notifier == nil || update.ParentID == nil {
+		return
+	}
+	parentPost, err := s.GetPost(*update.ParentID)
+	if err != nil {
+		log.Errorf("error getting boost of update %v: %v", update.ID, err)
+		return
+	}
+	if boost, ok := parentPost.(*Boost); ok {
+		// emailing every supporter can be slow, so keep it out of the request that published the update
+		go s.notifier.NotifyBoostUpdate(boost, update)
+	}
+}
+
+// PostPublisherCron periodically publishes scheduled posts
+func PostPublisherCron(service *Service, config *utils.GraphQLConfig) {
+	if config.PostPublishPollSecs <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(config.PostPublishPollSecs) * time.Second)
+	go func() {
+		for range ticker.C {
+			err := service.PublishScheduledPosts()
+			if err != nil {
+				log.Errorf("error publishing scheduled posts: %v", err)
+			}
+		}
+	}()
+}
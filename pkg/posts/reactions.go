@@ -0,0 +1,51 @@
+package posts
+
+import (
+	"errors"
+)
+
+// errors
+var (
+	ErrBadReactionEmoji = errors.New("emoji is not one users can react with")
+)
+
+// React records a user reacting to a post with one of the ReactionEmojis
+func (s *Service) React(userID string, postID string, emoji string) (Post, error) {
+	if !IsReactionEmoji(emoji) {
+		return nil, ErrBadReactionEmoji
+	}
+	post, err := s.reactablePost(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.AddReaction(userID, postID, emoji); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// Unreact removes a user's reaction to a post
+func (s *Service) Unreact(userID string, postID string, emoji string) (Post, error) {
+	post, err := s.reactablePost(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.RemoveReaction(userID, postID, emoji); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// reactablePost returns the post if it is published and visible
+func (s *Service) reactablePost(postID string) (Post, error) {
+	post, err := s.GetPost(postID)
+	if err != nil {
+		return nil, err
+	}
+	if post.GetPostModel().Draft || post.GetPostModel().Hidden {
+		return nil, ErrorNotFound
+	}
+	return post, nil
+}
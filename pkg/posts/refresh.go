@@ -0,0 +1,216 @@
+package posts
+
+import (
+	"bytes"
+	"time"
+
+	log "github.com/golang/glog"
+
+	"github.com/joincivil/civil-api-server/pkg/utils"
+)
+
+const (
+	defaultRefreshInterval    = 6 * time.Hour
+	defaultMaxRefreshInterval = 7 * 24 * time.Hour
+	defaultRefreshWindow      = 30 * 24 * time.Hour
+	defaultRefreshBatchSize   = 50
+	maxRefreshErrorLength     = 500
+)
+
+// RefreshConfig configures how often ExternalLink OpenGraph data is refreshed
+type RefreshConfig struct {
+	// Interval is the time between refreshes of a post whose metadata recently changed
+	Interval time.Duration
+	// MaxInterval caps the backoff applied when a post's metadata is unchanged or cannot be fetched
+	MaxInterval time.Duration
+	// Window is how long after creation a post keeps being refreshed
+	Window    time.Duration
+	BatchSize int
+}
+
+// ExternalLinkRefresher re-fetches the OpenGraph data of recent ExternalLink posts
+type ExternalLinkRefresher struct {
+	persister PostPersister
+	fetcher   *utils.Fetcher
+	config    RefreshConfig
+}
+
+// NewExternalLinkRefresher builds an instance of ExternalLinkRefresher
+func NewExternalLinkRefresher(persister PostPersister, fetcher *utils.Fetcher, config RefreshConfig) *ExternalLinkRefresher {
+	if config.Interval <= 0 {
+		config.Interval = defaultRefreshInterval
+	}
+	if config.MaxInterval < config.Interval {
+		config.MaxInterval = defaultMaxRefreshInterval
+		if config.MaxInterval < config.Interval {
+			config.MaxInterval = config.Interval
+		}
+	}
+	if config.Window <= 0 {
+		config.Window = defaultRefreshWindow
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultRefreshBatchSize
+	}
+
+	return &ExternalLinkRefresher{
+		persister: persister,
+		fetcher:   fetcher,
+		config:    config,
+	}
+}
+
+// NewExternalLinkRefresherFromConfig builds an instance of ExternalLinkRefresher using the graphql config
+func NewExternalLinkRefresherFromConfig(persister PostPersister, fetcher *utils.Fetcher, config *utils.GraphQLConfig) *ExternalLinkRefresher {
+	return NewExternalLinkRefresher(persister, fetcher, RefreshConfig{
+		Interval:    time.Duration(config.OpenGraphRefreshIntervalMins) * time.Minute,
+		MaxInterval: time.Duration(config.OpenGraphRefreshMaxIntervalMins) * time.Minute,
+		Window:      time.Duration(config.OpenGraphRefreshWindowDays) * 24 * time.Hour,
+		BatchSize:   config.OpenGraphRefreshBatchSize,
+	})
+}
+
+// RefreshDue refreshes the ExternalLinks that are due for a refresh
+func (r *ExternalLinkRefresher) RefreshDue() error {
+	now := time.Now()
+	links, err := r.persister.GetExternalLinksDueForRefresh(now.Add(-r.config.Window), now.Add(-r.config.Interval), r.config.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, link := range links {
+		if _, err := r.Refresh(link); err != nil {
+			log.Errorf("error refreshing open graph data for post %v: %v", link.ID, err)
+		}
+	}
+	return nil
+}
+
+// GetRefresh returns the refresh state of an ExternalLink
+func (r *ExternalLinkRefresher) GetRefresh(postID string) (*ExternalLinkRefresh, error) {
+	return r.persister.GetExternalLinkRefresh(postID)
+}
+
+// RefreshPost refreshes the ExternalLink with the given ID, regardless of when it is next due
+func (r *ExternalLinkRefresher) RefreshPost(postID string) (*ExternalLink, error) {
+	post, err := r.persister.GetPost(postID)
+	if err != nil {
+		return nil, err
+	}
+	link, ok := post.(*ExternalLink)
+	if !ok {
+		return nil, ErrorNotExternalLink
+	}
+
+	return r.Refresh(link)
+}
+
+// Refresh re-fetches the OpenGraph data and published time of an ExternalLink. If the metadata changed the post is
+// updated and its previous metadata is kept on the refresh record. The next refresh is scheduled with backoff when the
+// metadata is unchanged or could not be fetched
+func (r *ExternalLinkRefresher) Refresh(link *ExternalLink) (*ExternalLink, error) {
+	refresh, err := r.persister.GetExternalLinkRefresh(link.ID)
+	if err == ErrorNotFound {
+		refresh = &ExternalLinkRefresh{PostID: link.ID}
+	} else if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	refresh.LastAttemptAt = &now
+
+	metadata, err := scrapeExternalLink(r.fetcher, link.URL)
+	if err != nil {
+		refresh.FailureCount++
+		refresh.LastError = truncate(err.Error(), maxRefreshErrorLength)
+		r.schedule(refresh, r.backoff(refresh.FailureCount), now)
+		if saveErr := r.persister.SaveExternalLinkRefresh(refresh); saveErr != nil {
+			log.Errorf("error saving external link refresh: %v", saveErr)
+		}
+		return nil, err
+	}
+
+	refresh.FailureCount = 0
+	refresh.LastError = ""
+	refresh.LastSuccessAt = &now
+
+	publishedTime := link.PublishedTime
+	if metadata.publishedTime != nil {
+		publishedTime = metadata.publishedTime
+	}
+
+	if err = importOpenGraphTags(r.persister, link.ID, metadata.tags); err != nil {
+		log.Errorf("error importing tags of external link %v: %v", link.ID, err)
+	}
+
+	if bytes.Equal(metadata.openGraphData, link.OpenGraphData) && sameTime(publishedTime, link.PublishedTime) {
+		// nothing changed, back off from the current interval
+		interval := time.Duration(refresh.IntervalSecs) * time.Second * 2
+		r.schedule(refresh, interval, now)
+		return link, r.persister.SaveExternalLinkRefresh(refresh)
+	}
+
+	refresh.PreviousOpenGraphData = link.OpenGraphData
+	refresh.PreviousPublishedTime = link.PublishedTime
+	refresh.LastChangedAt = &now
+
+	updated := *link
+	updated.OpenGraphData = metadata.openGraphData
+	updated.PublishedTime = publishedTime
+	if err = r.persister.UpdateExternalLinkMetadata(&updated); err != nil {
+		return nil, err
+	}
+
+	r.schedule(refresh, r.config.Interval, now)
+	return &updated, r.persister.SaveExternalLinkRefresh(refresh)
+}
+
+func (r *ExternalLinkRefresher) schedule(refresh *ExternalLinkRefresh, interval time.Duration, now time.Time) {
+	if interval < r.config.Interval {
+		interval = r.config.Interval
+	}
+	if interval > r.config.MaxInterval {
+		interval = r.config.MaxInterval
+	}
+	refresh.IntervalSecs = int64(interval / time.Second)
+	refresh.NextRefreshAt = now.Add(interval)
+}
+
+func (r *ExternalLinkRefresher) backoff(failures int) time.Duration {
+	interval := r.config.Interval
+	for i := 0; i < failures && interval < r.config.MaxInterval; i++ {
+		interval *= 2
+	}
+	return interval
+}
+
+// ExternalLinkRefreshCron refreshes ExternalLink OpenGraph data on a regular interval
+func ExternalLinkRefreshCron(refresher *ExternalLinkRefresher, config *utils.GraphQLConfig) {
+	if config.OpenGraphRefreshPollSecs <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(config.OpenGraphRefreshPollSecs) * time.Second)
+	go func() {
+		for range ticker.C {
+			err := refresher.RefreshDue()
+			if err != nil {
+				log.Errorf("error refreshing open graph data: %v", err)
+			}
+		}
+	}()
+}
+
+func sameTime(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func truncate(s string, length int) string {
+	if len(s) <= length {
+		return s
+	}
+	return s[:length]
+}
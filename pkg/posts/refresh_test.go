@@ -0,0 +1,134 @@
+package posts_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joincivil/civil-api-server/pkg/posts"
+	"github.com/joincivil/civil-api-server/pkg/utils"
+)
+
+func newOpenGraphServer(title *string, failing *bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if *failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, `<html><head>
+<link rel="canonical" href="https://example.com/story" />
+<meta property="og:type" content="article" />
+<meta property="og:title" content="%v" />
+<meta property="og:url" content="https://example.com/story" />
+<meta property="article:published_time" content="2019-09-01T10:00:00Z" />
+</head><body></body></html>`, *title) // nolint: errcheck
+	}))
+}
+
+func TestExternalLinkRefresh(t *testing.T) {
+	persister := initPersister(t)
+	title := "Original Headline"
+	failing := false
+	server := newOpenGraphServer(&title, &failing)
+	defer server.Close()
+
+	link := posts.ExternalLink{
+		PostModel:     posts.PostModel{ChannelID: aliceNewsroomUUID},
+		URL:           server.URL + "/story",
+		OpenGraphData: []byte(`{"title":"Stale Headline"}`),
+	}
+	created := helperCreatePost(t, persister, link)
+	err := db.Model(&posts.PostModel{ID: created.GetID()}).UpdateColumn("created_at", time.Now().Add(-2*time.Hour)).Error
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+
+	fetcher := utils.NewFetcher(utils.FetcherConfig{AllowPrivateNetworks: true})
+	refresher := posts.NewExternalLinkRefresher(persister, fetcher, posts.RefreshConfig{
+		Interval:    time.Hour,
+		MaxInterval: 4 * time.Hour,
+		Window:      24 * time.Hour,
+	})
+
+	due, err := persister.GetExternalLinksDueForRefresh(time.Now().Add(-24*time.Hour), time.Now().Add(-time.Hour), 1000)
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	found := false
+	for _, d := range due {
+		found = found || d.ID == created.GetID()
+	}
+	if !found {
+		t.Fatalf("expected never refreshed post to be due")
+	}
+
+	refreshed, err := refresher.RefreshPost(created.GetID())
+	if err != nil {
+		t.Fatalf("was not expecting an error refreshing: %v", err)
+	}
+	if !strings.Contains(string(refreshed.OpenGraphData), "Original Headline") || refreshed.PublishedTime == nil {
+		t.Fatalf("expected refreshed open graph data and published time")
+	}
+	refresh, err := refresher.GetRefresh(created.GetID())
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if string(refresh.PreviousOpenGraphData) != `{"title":"Stale Headline"}` {
+		t.Fatalf("expected previous open graph snapshot to be kept, got %v", string(refresh.PreviousOpenGraphData))
+	}
+	if refresh.IntervalSecs != int64(time.Hour/time.Second) {
+		t.Fatalf("expected changed metadata to use the base interval, got %v", refresh.IntervalSecs)
+	}
+	post, err := persister.GetPost(created.GetID())
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if !strings.Contains(string(post.(*posts.ExternalLink).OpenGraphData), "Original Headline") {
+		t.Fatalf("expected refreshed open graph data to be persisted")
+	}
+
+	_, err = refresher.RefreshPost(created.GetID())
+	if err != nil {
+		t.Fatalf("was not expecting an error refreshing: %v", err)
+	}
+	refresh, _ = refresher.GetRefresh(created.GetID()) // nolint: errcheck
+	if refresh.IntervalSecs != int64(2*time.Hour/time.Second) {
+		t.Fatalf("expected unchanged metadata to back off, got %v", refresh.IntervalSecs)
+	}
+	if string(refresh.PreviousOpenGraphData) != `{"title":"Stale Headline"}` {
+		t.Fatalf("expected snapshot to be kept when nothing changed")
+	}
+
+	failing = true
+	_, err = refresher.RefreshPost(created.GetID())
+	if err == nil {
+		t.Fatalf("expected an error refreshing a failing page")
+	}
+	refresh, _ = refresher.GetRefresh(created.GetID()) // nolint: errcheck
+	if refresh.FailureCount != 1 || refresh.LastError == "" || refresh.NextRefreshAt.Before(time.Now().Add(time.Hour)) {
+		t.Fatalf("expected failure to be recorded with backoff")
+	}
+
+	failing = false
+	title = "Edited Headline"
+	refreshed, err = refresher.RefreshPost(created.GetID())
+	if err != nil {
+		t.Fatalf("was not expecting an error refreshing: %v", err)
+	}
+	refresh, _ = refresher.GetRefresh(created.GetID()) // nolint: errcheck
+	if !strings.Contains(string(refresh.PreviousOpenGraphData), "Original Headline") || refresh.FailureCount != 0 {
+		t.Fatalf("expected snapshot to move forward after an edit")
+	}
+	if !strings.Contains(string(refreshed.OpenGraphData), "Edited Headline") {
+		t.Fatalf("expected edited headline")
+	}
+
+	boost := helperCreatePost(t, persister, makeValidBoost())
+	_, err = refresher.RefreshPost(boost.GetID())
+	if err != posts.ErrorNotExternalLink {
+		t.Fatalf("expected ErrorNotExternalLink refreshing a boost, got %v", err)
+	}
+}
@@ -1,33 +1,67 @@
 package posts
 
 import (
+	"bytes"
 	"errors"
 	"github.com/dyatlov/go-htmlinfo/htmlinfo"
 	"github.com/joincivil/civil-api-server/pkg/channels"
 	"github.com/joincivil/civil-api-server/pkg/newsrooms"
-	"github.com/joincivil/civil-events-processor/pkg/utils"
+	"github.com/joincivil/civil-api-server/pkg/utils"
+	cutils "github.com/joincivil/civil-events-processor/pkg/utils"
 	"golang.org/x/net/html"
-	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
 
+// BoostUpdateNotifier notifies the supporters of a Boost when an update is posted to it
+type BoostUpdateNotifier interface {
+	NotifyBoostUpdate(boost *Boost, update *BoostUpdate)
+}
+
+// ServiceConfig configures the rules applied to posts
+type ServiceConfig struct {
+	MaxCommentDepth int
+}
+
 // Service provides methods to interact with Posts
 type Service struct {
 	PostPersister
 	channelService  *channels.Service
 	newsroomService newsrooms.Service
+	fetcher         *utils.Fetcher
+	notifier        BoostUpdateNotifier
+	imageStorer     BoostImageStorer
+	maxCommentDepth int
 }
 
 // NewService builds an instance of posts.Service
-func NewService(persister PostPersister, channelSer *channels.Service, newsroomSer newsrooms.Service) *Service {
+func NewService(persister PostPersister, channelSer *channels.Service, newsroomSer newsrooms.Service, fetcher *utils.Fetcher,
+	notifier BoostUpdateNotifier, imageStorer BoostImageStorer, config ServiceConfig) *Service {
+	maxCommentDepth := config.MaxCommentDepth
+	if maxCommentDepth <= 0 {
+		maxCommentDepth = defaultMaxCommentDepth
+	}
+
 	return &Service{
 		PostPersister:   persister,
 		channelService:  channelSer,
 		newsroomService: newsroomSer,
+		fetcher:         fetcher,
+		notifier:        notifier,
+		imageStorer:     imageStorer,
+		maxCommentDepth: maxCommentDepth,
 	}
 }
 
+// NewServiceFromConfig builds an instance of posts.Service using the graphql config
+func NewServiceFromConfig(persister PostPersister, channelSer *channels.Service, newsroomSer newsrooms.Service, fetcher *utils.Fetcher,
+	notifier BoostUpdateNotifier, imageStorer BoostImageStorer, config *utils.GraphQLConfig) *Service {
+	return NewService(persister, channelSer, newsroomSer, fetcher, notifier, imageStorer, ServiceConfig{
+		MaxCommentDepth: config.CommentMaxDepth,
+	})
+}
+
 // errors
 var (
 	ErrBadParentID       = errors.New("bad parent ID")
@@ -47,8 +81,11 @@ func (s *Service) CreateExternalLinkEmbedded(post Post) (Post, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err = s.requireChannelNotSuspended(externalLink.ChannelID); err != nil {
+			return nil, err
+		}
 
-		return s.PostPersister.CreatePost(externalLink.ChannelID, *externalLink)
+		return s.createTaggedPost(externalLink.ChannelID, *externalLink, externalLink.TagNames)
 	}
 	return nil, ErrorNotImplemented
 }
@@ -61,14 +98,26 @@ func (s *Service) CreatePost(authorID string, post Post) (Post, error) {
 	}
 	postType := base.PostType
 	if postType == TypeBoost {
-		return s.PostPersister.CreatePost(authorID, post)
+		if err = s.requireChannelNotSuspended(base.ChannelID); err != nil {
+			return nil, err
+		}
+		if err = validateTagNames(base.TagNames); err != nil {
+			return nil, err
+		}
+		return s.createTaggedPost(authorID, post, base.TagNames)
 	} else if postType == TypeExternalLink {
+		if err = validateTagNames(base.TagNames); err != nil {
+			return nil, err
+		}
 		externalLink, err := s.getExternalLink(post)
 		if err != nil {
 			return nil, err
 		}
+		if err = s.requireChannelNotSuspended(externalLink.ChannelID); err != nil {
+			return nil, err
+		}
 
-		return s.PostPersister.CreatePost(authorID, *externalLink)
+		return s.createTaggedPost(authorID, *externalLink, externalLink.TagNames)
 	} else if postType == TypeComment {
 		parentID := base.ParentID
 		if parentID == nil {
@@ -102,16 +151,93 @@ func (s *Service) CreatePost(authorID string, post Post) (Post, error) {
 			if err != nil {
 				return nil, err
 			}
+			if userChannel.IsSuspended() {
+				return nil, ErrorChannelSuspended
+			}
 
 			comment.ChannelID = userChannel.ID
 
+			comment.Depth, err = s.commentDepth(parentPost)
+			if err != nil {
+				return nil, err
+			}
+
 			return s.PostPersister.CreatePost(authorID, comment)
 		}
 		return nil, ErrBadParentPostType
+	} else if postType == TypeBoostUpdate {
+		return s.createBoostUpdate(authorID, post.(BoostUpdate))
 	}
 	return nil, nil
 }
 
+// createTaggedPost creates a post and sets the tags named for it
+func (s *Service) createTaggedPost(authorID string, post Post, tagNames []string) (Post, error) {
+	created, err := s.PostPersister.CreatePost(authorID, post)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.setPostTags(created, tagNames); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// createBoostUpdate creates an update under a Boost, which only admins of the boost's channel can do,
+// and notifies the boost's supporters
+func (s *Service) createBoostUpdate(authorID string, update BoostUpdate) (Post, error) {
+	if update.ParentID == nil {
+		return nil, ErrBadParentID
+	}
+	parentPost, err := s.GetPost(*update.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	boost, ok := parentPost.(*Boost)
+	if !ok {
+		return nil, ErrBadParentPostType
+	}
+
+	isAdmin, err := s.channelService.IsChannelAdmin(authorID, boost.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, ErrorNotAuthorized
+	}
+	if err = s.requireChannelNotSuspended(boost.ChannelID); err != nil {
+		return nil, err
+	}
+
+	for _, image := range update.Images {
+		imageURL, err := url.Parse(image.URL)
+		if err != nil || (imageURL.Scheme != "http" && imageURL.Scheme != "https") || imageURL.Host == "" {
+			return nil, ErrorBadImageURL
+		}
+	}
+	update.ChannelID = boost.ChannelID
+
+	created, err := s.PostPersister.CreatePost(authorID, update)
+	if err != nil {
+		return nil, err
+	}
+	if !created.GetPostModel().Draft {
+		s.notifyPublished(created)
+	}
+	return created, nil
+}
+
+func (s *Service) requireChannelNotSuspended(channelID string) error {
+	channel, err := s.channelService.GetChannel(channelID)
+	if err != nil {
+		return err
+	}
+	if channel.IsSuspended() {
+		return ErrorChannelSuspended
+	}
+	return nil
+}
+
 // GetPostByReferenceSafe returns a post associated with the provided reference
 // cleans reference before checking to avoid "http://" vs "https://" issue
 func (s *Service) GetPostByReferenceSafe(reference string) (Post, error) {
@@ -143,7 +269,7 @@ func (s *Service) getExternalLink(post Post) (*ExternalLink, error) {
 	channelType := channel.ChannelType
 	if channelType == channels.TypeNewsroom {
 		externalLink := post.(ExternalLink)
-		cleanedSubmittedURL, err := utils.CleanURL(externalLink.URL)
+		cleanedSubmittedURL, err := cutils.CleanURL(externalLink.URL)
 		if err != nil {
 			return nil, err
 		}
@@ -152,7 +278,7 @@ func (s *Service) getExternalLink(post Post) (*ExternalLink, error) {
 		if err != nil {
 			return nil, err
 		}
-		cleanedChannelURL, err := utils.CleanURL(newsroom.Charter.NewsroomURL)
+		cleanedChannelURL, err := cutils.CleanURL(newsroom.Charter.NewsroomURL)
 		if err != nil {
 			return nil, err
 		}
@@ -161,69 +287,96 @@ func (s *Service) getExternalLink(post Post) (*ExternalLink, error) {
 			return nil, ErrorBadURLSubmitted
 		}
 
-		resp, err := http.Get(externalLink.URL)
+		metadata, err := scrapeExternalLink(s.fetcher, externalLink.URL)
 		if err != nil {
 			return nil, err
 		}
-		defer resp.Body.Close() // nolint: errcheck
 
-		// Just get this twice since we need 2 readers. Trying to duplicate readers is complicated and doesn't play nicely with htmlInfo parsing
-		resp2, err := http.Get(externalLink.URL)
-		if err != nil {
-			return nil, err
+		ref := TypeExternalLink + "+" + metadata.referenceURL
+		externalLink.Reference = &ref
+		externalLink.OpenGraphData = metadata.openGraphData
+		if metadata.publishedTime != nil {
+			externalLink.PublishedTime = metadata.publishedTime
+		}
+		if tagNames := openGraphTagNames(metadata.tags); len(tagNames) > 0 {
+			externalLink.TagNames = append(externalLink.TagNames, tagNames...)
 		}
-		defer resp2.Body.Close() // nolint: errcheck
 
-		htmlInfo := htmlinfo.NewHTMLInfo()
+		return &externalLink, nil
+	}
+	return nil, ErrorNotImplemented
+}
 
-		err = htmlInfo.Parse(resp.Body, &(externalLink.URL), nil)
-		if err != nil {
-			return nil, err
-		}
+// externalLinkMetadata is the metadata scraped from the page an ExternalLink points to
+type externalLinkMetadata struct {
+	referenceURL  string
+	openGraphData []byte
+	publishedTime *time.Time
+	// tags are the article:tag values of the page
+	tags []string
+}
 
-		var refURL string
-		if htmlInfo.CanonicalURL != "" {
-			refURL = htmlInfo.CanonicalURL
-		} else if htmlInfo.OGInfo.URL != "" {
-			refURL = htmlInfo.OGInfo.URL
-		} else {
-			return nil, ErrorNoReferenceURLFound
-		}
+func scrapeExternalLink(fetcher *utils.Fetcher, url string) (*externalLinkMetadata, error) {
+	result, err := fetcher.Fetch(url)
+	if err != nil {
+		return nil, err
+	}
+	if result.StatusCode >= 400 {
+		return nil, ErrorExternalLinkUnavailable
+	}
 
-		refURL = strings.Replace(refURL, "https://www.", "", 1)
-		refURL = strings.Replace(refURL, "http://www.", "", 1)
-		refURL = strings.Replace(refURL, "https://", "", 1)
-		refURL = strings.Replace(refURL, "http://", "", 1)
+	htmlInfo := htmlinfo.NewHTMLInfo()
 
-		ref := TypeExternalLink + "+" + refURL
-		externalLink.Reference = &ref
-		didFindCivilPublishedTime := false
-		z := html.NewTokenizer(resp2.Body)
-		timePublished, err := getPublishedTime(z)
+	// the body is buffered so it can be read by both htmlInfo and the tokenizer
+	err = htmlInfo.Parse(bytes.NewReader(result.Body), &url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var refURL string
+	if htmlInfo.CanonicalURL != "" {
+		refURL = htmlInfo.CanonicalURL
+	} else if htmlInfo.OGInfo.URL != "" {
+		refURL = htmlInfo.OGInfo.URL
+	} else {
+		return nil, ErrorNoReferenceURLFound
+	}
+
+	refURL = strings.Replace(refURL, "https://www.", "", 1)
+	refURL = strings.Replace(refURL, "http://www.", "", 1)
+	refURL = strings.Replace(refURL, "https://", "", 1)
+	refURL = strings.Replace(refURL, "http://", "", 1)
+
+	metadata := &externalLinkMetadata{referenceURL: refURL}
+
+	didFindCivilPublishedTime := false
+	z := html.NewTokenizer(bytes.NewReader(result.Body))
+	timePublished, err := getPublishedTime(z)
+	if err != nil {
+		return nil, err
+	}
+	if timePublished != nil {
+		metadata.publishedTime = timePublished
+		didFindCivilPublishedTime = true
+	}
+
+	if htmlInfo.OGInfo != nil {
+		ogJSON, err := htmlInfo.OGInfo.ToJSON()
 		if err != nil {
 			return nil, err
 		}
-		if timePublished != nil {
-			externalLink.PublishedTime = timePublished
-			didFindCivilPublishedTime = true
-		}
-
-		if htmlInfo.OGInfo != nil {
-			ogJSON, err := htmlInfo.OGInfo.ToJSON()
-			if err != nil {
-				return nil, err
-			}
-			externalLink.OpenGraphData = ogJSON
+		metadata.openGraphData = ogJSON
 
-			if !didFindCivilPublishedTime && htmlInfo.OGInfo.Article != nil && htmlInfo.OGInfo.Article.PublishedTime != nil {
-				time := htmlInfo.OGInfo.Article.PublishedTime
-				externalLink.PublishedTime = time
-			}
+		if !didFindCivilPublishedTime && htmlInfo.OGInfo.Article != nil && htmlInfo.OGInfo.Article.PublishedTime != nil {
+			time := htmlInfo.OGInfo.Article.PublishedTime
+			metadata.publishedTime = time
+		}
+		if htmlInfo.OGInfo.Article != nil {
+			metadata.tags = htmlInfo.OGInfo.Article.Tags
 		}
-
-		return &externalLink, nil
 	}
-	return nil, ErrorNotImplemented
+
+	return metadata, nil
 }
 
 func getPublishedTime(z *html.Tokenizer) (*time.Time, error) {
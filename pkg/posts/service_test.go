@@ -1,10 +1,14 @@
 package posts_test
 
 import (
+	"bytes"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/joincivil/civil-api-server/pkg/blobstore"
 	"github.com/joincivil/civil-api-server/pkg/channels"
+	"github.com/joincivil/civil-api-server/pkg/images"
 	"github.com/joincivil/civil-api-server/pkg/posts"
 	"github.com/joincivil/civil-api-server/pkg/testruntime"
 	"github.com/joincivil/civil-api-server/pkg/testutils"
@@ -12,8 +16,12 @@ import (
 	"github.com/joincivil/go-common/pkg/email"
 	"github.com/joincivil/go-common/pkg/newsroom"
 	uuid "github.com/satori/go.uuid"
+	"image"
+	"image/png"
+	"io/ioutil"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"strconv"
 	// "strings"
 	"testing"
@@ -140,6 +148,14 @@ func getSendGridKeyFromEnvVar() string {
 	return os.Getenv(sendGridKeyEnvVar)
 }
 
+func makePNGDataURL(t *testing.T, width int, height int) string {
+	var buff bytes.Buffer
+	if err := png.Encode(&buff, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("error encoding png: %v", err)
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buff.Bytes())
+}
+
 func TestPosts(t *testing.T) {
 	db, err := testutils.GetTestDBConnection()
 	if err != nil {
@@ -155,7 +171,13 @@ func TestPosts(t *testing.T) {
 
 	sendGridKey := getSendGridKeyFromEnvVar()
 	emailer := email.NewEmailerWithSandbox(sendGridKey, useSandbox)
-	channelService := channels.NewService(persister, MockGetNewsroomHelper{}, MockStripeConnector{}, generator, emailer, testSignupLoginProtoHost)
+	blobDir, err := ioutil.TempDir("", "posts-blobs")
+	if err != nil {
+		t.Fatalf("error creating blob dir: %v", err)
+	}
+	defer os.RemoveAll(blobDir) // nolint: errcheck
+	imagePipeline := images.NewPipeline(blobstore.NewLocalStore(blobDir, "http://localhost/blobs"))
+	channelService := channels.NewService(persister, MockGetNewsroomHelper{}, MockStripeConnector{}, generator, emailer, testSignupLoginProtoHost, imagePipeline)
 
 	randomInt := r.Int31()
 	groupHandle := fmt.Sprintf("gh%v", randomInt)
@@ -173,8 +195,10 @@ func TestPosts(t *testing.T) {
 		t.Fatalf("not expecting error creating group channel: %v", err)
 	}
 
-	postPersister := posts.NewDBPostPersister(db)
-	postService := posts.NewService(postPersister, channelService, MockNewsroomService{})
+	postPersister := posts.NewDBPostPersister(db, posts.NewStoryfeedRegistry(testStoryfeedConfig))
+	notifier := newRecordingBoostUpdateNotifier()
+	postService := posts.NewService(postPersister, channelService, MockNewsroomService{}, utils.NewFetcher(utils.FetcherConfig{}), notifier,
+		imagePipeline, posts.ServiceConfig{})
 
 	boost := makeValidChannelBoost(channel.ID)
 	post, err := postService.CreatePost(user1ID, boost)
@@ -182,6 +206,30 @@ func TestPosts(t *testing.T) {
 		t.Fatalf("not expecting error creating post: %v", err)
 	}
 
+	coverDataURL := makePNGDataURL(t, 2400, 1200)
+	_, err = postService.SetBoostCoverImage(user2ID, post.GetID(), &coverDataURL)
+	if err != posts.ErrorNotAuthorized {
+		t.Fatalf("was expecting not authorized error setting cover image: %v", err)
+	}
+	covered, err := postService.SetBoostCoverImage(user1ID, post.GetID(), &coverDataURL)
+	if err != nil {
+		t.Fatalf("not expecting error setting cover image: %v", err)
+	}
+	large := covered.CoverImage.Variant("large")
+	if large == nil || large.Width != 1920 || large.Height != 960 {
+		t.Fatalf("expected a large cover image variant resized to 1920x960")
+	}
+	if _, err = os.Stat(filepath.Join(blobDir, filepath.FromSlash(large.Key))); err != nil {
+		t.Fatalf("expected the cover image variant to be saved to the blob store: %v", err)
+	}
+	fetched, err := postService.GetPost(post.GetID())
+	if err != nil {
+		t.Fatalf("not expecting error getting post: %v", err)
+	}
+	if fetched.(*posts.Boost).CoverImage == nil || fetched.(*posts.Boost).CoverImage.Variant("small") == nil {
+		t.Fatalf("expected the cover image to be saved on the boost")
+	}
+
 	commentAnnouncementValid := makeValidPostComment(post.GetID(), posts.TypeCommentAnnouncement)
 	commentPost, err := postService.CreatePost(user1ID, commentAnnouncementValid)
 	if err != nil {
@@ -217,4 +265,180 @@ func TestPosts(t *testing.T) {
 	if err != nil {
 		t.Fatalf("was not expecting error creating valid default comment from non-admin: %v", err)
 	}
+
+	parentID := post.GetID()
+	update := posts.BoostUpdate{
+		PostModel: posts.PostModel{ParentID: &parentID},
+		Text:      "<p>we bought the microphones</p>",
+		Images:    []posts.BoostUpdateImage{{URL: "https://example.com/mics.png", Caption: "mics"}},
+	}
+	_, err = postService.CreatePost(user2ID, update)
+	if err != posts.ErrorNotAuthorized {
+		t.Fatalf("was expecting not authorized error creating boost update from non-admin: %v", err)
+	}
+	updatePost, err := postService.CreatePost(user1ID, update)
+	if err != nil {
+		t.Fatalf("was not expecting error creating boost update: %v", err)
+	}
+	if updatePost.GetType() != posts.TypeBoostUpdate || updatePost.GetChannelID() != channel.ID {
+		t.Fatalf("expected boost update in the boost's channel")
+	}
+	select {
+	case notified := <-notifier.updates:
+		if notified.ID != updatePost.GetID() {
+			t.Fatalf("expected supporters to be notified of the boost update")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected supporters to be notified of the boost update")
+	}
+
+	badImage := update
+	badImage.Images = []posts.BoostUpdateImage{{URL: "javascript:alert(1)"}}
+	_, err = postService.CreatePost(user1ID, badImage)
+	if err != posts.ErrorBadImageURL {
+		t.Fatalf("was expecting bad image URL error: %v", err)
+	}
+
+	commentParentID := commentPost.GetID()
+	update.ParentID = &commentParentID
+	_, err = postService.CreatePost(user1ID, update)
+	if err != posts.ErrBadParentPostType {
+		t.Fatalf("was expecting bad parent post type error for update outside a boost: %v", err)
+	}
+
+	_, err = channelService.SetChannelSuspended(channel.ID, true)
+	if err != nil {
+		t.Fatalf("was not expecting error suspending channel: %v", err)
+	}
+	_, err = postService.CreatePost(user1ID, makeValidChannelBoost(channel.ID))
+	if err != posts.ErrorChannelSuspended {
+		t.Fatalf("was expecting channel suspended error creating boost: %v", err)
+	}
+}
+
+func TestCommentThreads(t *testing.T) {
+	db, err := testutils.GetTestDBConnection()
+	if err != nil {
+		t.Fatalf("error getting DB: %v", err)
+	}
+	err = testruntime.RunMigrations(db)
+	if err != nil {
+		t.Fatalf("error cleaning DB: %v", err)
+	}
+
+	persister := channels.NewDBPersister(db)
+	generator := utils.NewJwtTokenGenerator([]byte("secret"))
+	emailer := email.NewEmailerWithSandbox(getSendGridKeyFromEnvVar(), useSandbox)
+	channelService := channels.NewService(persister, MockGetNewsroomHelper{}, MockStripeConnector{}, generator, emailer, testSignupLoginProtoHost, images.NewPipeline(nil))
+
+	adminID := randomUUID()
+	readerID := randomUUID()
+	for _, userID := range []string{adminID, readerID} {
+		if _, err = channelService.CreateUserChannel(userID); err != nil {
+			t.Fatalf("not expecting error creating user channel: %v", err)
+		}
+	}
+	channel, err := channelService.CreateGroupChannel(adminID, fmt.Sprintf("gh%v", r.Int31()))
+	if err != nil {
+		t.Fatalf("not expecting error creating group channel: %v", err)
+	}
+
+	postPersister := posts.NewDBPostPersister(db, posts.NewStoryfeedRegistry(testStoryfeedConfig))
+	postService := posts.NewService(postPersister, channelService, MockNewsroomService{}, utils.NewFetcher(utils.FetcherConfig{}),
+		newRecordingBoostUpdateNotifier(), nil, posts.ServiceConfig{MaxCommentDepth: 2})
+
+	boost, err := postService.CreatePost(adminID, makeValidChannelBoost(channel.ID))
+	if err != nil {
+		t.Fatalf("not expecting error creating post: %v", err)
+	}
+
+	first, err := postService.CreatePost(readerID, makeValidPostComment(boost.GetID(), posts.TypeCommentDefault))
+	if err != nil {
+		t.Fatalf("not expecting error creating comment: %v", err)
+	}
+	second, err := postService.CreatePost(readerID, makeValidPostComment(boost.GetID(), posts.TypeCommentDefault))
+	if err != nil {
+		t.Fatalf("not expecting error creating comment: %v", err)
+	}
+
+	reply, err := postService.CreatePost(readerID, makeValidPostComment(first.GetID(), posts.TypeCommentDefault))
+	if err != nil {
+		t.Fatalf("not expecting error creating reply: %v", err)
+	}
+	if reply.(*posts.Comment).Depth != 1 {
+		t.Fatalf("expected reply to have depth 1, got %v", reply.(*posts.Comment).Depth)
+	}
+	nested, err := postService.CreatePost(readerID, makeValidPostComment(reply.GetID(), posts.TypeCommentDefault))
+	if err != nil {
+		t.Fatalf("not expecting error creating nested reply: %v", err)
+	}
+	_, err = postService.CreatePost(readerID, makeValidPostComment(nested.GetID(), posts.TypeCommentDefault))
+	if err != posts.ErrCommentTooDeep {
+		t.Fatalf("was expecting too deep error replying past the max depth: %v", err)
+	}
+
+	result, err := postService.GetComments(readerID, boost.GetID(), posts.CommentSortOldest, 10, 0)
+	if err != nil {
+		t.Fatalf("not expecting error getting comments: %v", err)
+	}
+	if len(result.Posts) != 2 || result.Posts[0].GetID() != first.GetID() {
+		t.Fatalf("expected the top level comments oldest first")
+	}
+
+	_, err = postService.PinComment(readerID, second.GetID(), true)
+	if err != posts.ErrorNotAuthorized {
+		t.Fatalf("was expecting not authorized error pinning comment as non-admin: %v", err)
+	}
+	if _, err = postService.PinComment(adminID, second.GetID(), true); err != nil {
+		t.Fatalf("not expecting error pinning comment: %v", err)
+	}
+	result, err = postService.GetComments(readerID, boost.GetID(), posts.CommentSortOldest, 10, 0)
+	if err != nil {
+		t.Fatalf("not expecting error getting comments: %v", err)
+	}
+	if len(result.Posts) != 2 || result.Posts[0].GetID() != second.GetID() {
+		t.Fatalf("expected the pinned comment first")
+	}
+
+	if _, err = postService.HideComment(adminID, second.GetID(), true); err != nil {
+		t.Fatalf("not expecting error hiding comment: %v", err)
+	}
+	result, err = postService.GetComments(readerID, boost.GetID(), posts.CommentSortNewest, 10, 0)
+	if err != nil {
+		t.Fatalf("not expecting error getting comments: %v", err)
+	}
+	if len(result.Posts) != 1 || result.Posts[0].GetID() != first.GetID() {
+		t.Fatalf("expected the hidden comment to be excluded for readers")
+	}
+	result, err = postService.GetComments(adminID, boost.GetID(), posts.CommentSortNewest, 10, 0)
+	if err != nil {
+		t.Fatalf("not expecting error getting comments: %v", err)
+	}
+	if len(result.Posts) != 2 {
+		t.Fatalf("expected the hidden comment to be included for channel admins")
+	}
+
+	moderation, err := postService.LockCommentThread(adminID, first.GetID(), true)
+	if err != nil {
+		t.Fatalf("not expecting error locking comment thread: %v", err)
+	}
+	if !moderation.Locked || moderation.ModeratorID != adminID {
+		t.Fatalf("expected the thread to be locked by the admin")
+	}
+	_, err = postService.CreatePost(readerID, makeValidPostComment(reply.GetID(), posts.TypeCommentDefault))
+	if err != posts.ErrThreadLocked {
+		t.Fatalf("was expecting thread locked error replying beneath a locked comment: %v", err)
+	}
+}
+
+type recordingBoostUpdateNotifier struct {
+	updates chan *posts.BoostUpdate
+}
+
+func newRecordingBoostUpdateNotifier() *recordingBoostUpdateNotifier {
+	return &recordingBoostUpdateNotifier{updates: make(chan *posts.BoostUpdate, 10)}
+}
+
+func (n *recordingBoostUpdateNotifier) NotifyBoostUpdate(boost *posts.Boost, update *posts.BoostUpdate) {
+	n.updates <- update
 }
@@ -0,0 +1,465 @@
+package posts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joincivil/civil-api-server/pkg/payments"
+	"github.com/joincivil/civil-api-server/pkg/utils"
+)
+
+const (
+	defaultGlobalBoostInterleave  = 5
+	defaultChannelBoostInterleave = 3
+	defaultTrendingWindowHours    = 72
+	defaultTrendingHalfLifeHours  = 24
+)
+
+// StoryfeedAlgorithm ranks posts for the storyfeed. Algorithms are selected by
+// their Name using StoryfeedFilter.Alg
+type StoryfeedAlgorithm interface {
+	// Name returns the identifier used to select this algorithm
+	Name() string
+	// Description returns a short summary of how posts are ranked
+	Description() string
+	// CreateViewQuery returns the query to create the view backing the global storyfeed
+	CreateViewQuery() string
+	// GlobalQuery returns the query for the storyfeed across all channels,
+	// limited to the posts published by asOf if it is given
+	GlobalQuery(asOf *time.Time) (string, []interface{})
+	// ChannelQuery returns the query for the storyfeed of a single channel,
+	// limited to the posts published by asOf if it is given
+	ChannelQuery(channelID string, asOf *time.Time) (string, []interface{})
+	// RankExpression returns the expression used to order posts ahead of sort_date
+	RankExpression() string
+}
+
+// StoryfeedConfig contains the settings used by the built in storyfeed algorithms
+type StoryfeedConfig struct {
+	// GlobalBoostInterleave is the number of links shown between each boost in the global storyfeed
+	GlobalBoostInterleave int
+	// ChannelBoostInterleave is the number of links shown between each boost in a channel storyfeed
+	ChannelBoostInterleave int
+	// TrendingWindowHours is how far back payments are counted towards the trending storyfeed
+	TrendingWindowHours int
+	// TrendingHalfLifeHours is the age at which a payment counts for half as much in the trending storyfeed
+	TrendingHalfLifeHours int
+}
+
+// StoryfeedRegistry contains the storyfeed algorithms available to clients
+type StoryfeedRegistry struct {
+	algorithms map[string]StoryfeedAlgorithm
+	names      []string
+}
+
+// NewStoryfeedRegistryFromConfig builds a StoryfeedRegistry using the main graphql config
+func NewStoryfeedRegistryFromConfig(config *utils.GraphQLConfig) *StoryfeedRegistry {
+	return NewStoryfeedRegistry(StoryfeedConfig{
+		GlobalBoostInterleave:  config.StoryfeedGlobalBoostInterleave,
+		ChannelBoostInterleave: config.StoryfeedChannelBoostInterleave,
+		TrendingWindowHours:    config.StoryfeedTrendingWindowHours,
+		TrendingHalfLifeHours:  config.StoryfeedTrendingHalfLifeHours,
+	})
+}
+
+// NewStoryfeedRegistry builds a StoryfeedRegistry with the built in algorithms registered
+func NewStoryfeedRegistry(config StoryfeedConfig) *StoryfeedRegistry {
+	r := &StoryfeedRegistry{algorithms: map[string]StoryfeedAlgorithm{}}
+	r.Register(&chronologicalStoryfeed{})
+	r.Register(&chronologicalBoostfeed{})
+	r.Register(&fairThenChronologicalStoryfeed{})
+	interleaved := &fairWithInterleavedBoostsStoryfeed{
+		globalInterleave:  config.GlobalBoostInterleave,
+		channelInterleave: config.ChannelBoostInterleave,
+	}
+	if interleaved.globalInterleave <= 0 {
+		interleaved.globalInterleave = defaultGlobalBoostInterleave
+	}
+	if interleaved.channelInterleave <= 0 {
+		interleaved.channelInterleave = defaultChannelBoostInterleave
+	}
+	r.Register(interleaved)
+	trending := &trendingStoryfeed{
+		windowHours:   config.TrendingWindowHours,
+		halfLifeHours: config.TrendingHalfLifeHours,
+	}
+	if trending.windowHours <= 0 {
+		trending.windowHours = defaultTrendingWindowHours
+	}
+	if trending.halfLifeHours <= 0 {
+		trending.halfLifeHours = defaultTrendingHalfLifeHours
+	}
+	r.Register(trending)
+	return r
+}
+
+// Register adds an algorithm to the registry, replacing any algorithm with the same name
+func (r *StoryfeedRegistry) Register(alg StoryfeedAlgorithm) {
+	if _, ok := r.algorithms[alg.Name()]; !ok {
+		r.names = append(r.names, alg.Name())
+	}
+	r.algorithms[alg.Name()] = alg
+}
+
+// Algorithm returns the algorithm registered under the given name
+func (r *StoryfeedRegistry) Algorithm(name string) (StoryfeedAlgorithm, bool) {
+	alg, ok := r.algorithms[name]
+	return alg, ok
+}
+
+// Algorithms returns all registered algorithms in the order they were registered
+func (r *StoryfeedRegistry) Algorithms() []StoryfeedAlgorithm {
+	algs := make([]StoryfeedAlgorithm, len(r.names))
+	for i, name := range r.names {
+		algs[i] = r.algorithms[name]
+	}
+	return algs
+}
+
+// storyfeedScope restricts a storyfeed query to a channel and to the posts published by asOf.
+// Pages after the first are scoped to the time the first page was fetched, so that posts published
+// while paging can't shift the rank of the posts that follow the cursor
+type storyfeedScope struct {
+	channelID *string
+	asOf      *time.Time
+}
+
+// filter returns the conditions to add to the where clause of a query on posts
+func (s storyfeedScope) filter() (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	if s.channelID != nil {
+		conditions = append(conditions, "AND channel_id = ?")
+		args = append(args, *s.channelID)
+	}
+	if s.asOf != nil {
+		conditions = append(conditions, "AND coalesce(publish_at, created_at) <= ?")
+		args = append(args, *s.asOf)
+	}
+	return strings.Join(conditions, " "), args
+}
+
+// activeBoost returns the condition that a boost had not ended as of the scope
+func (s storyfeedScope) activeBoost() (string, []interface{}) {
+	if s.asOf == nil {
+		return "(data ->> 'date_end')::timestamp > now()", nil
+	}
+	return "(data ->> 'date_end')::timestamp > ?", []interface{}{*s.asOf}
+}
+
+// scoredAt returns the time that payments are scored as of, and the condition limiting payments to those made by then.
+// Unscoped queries score as of the start of the hour so the view stays stable while paging
+func (s storyfeedScope) scoredAt() (string, string, []interface{}) {
+	if s.asOf == nil {
+		return "date_trunc('hour', now())", "", nil
+	}
+	return "?::timestamptz", "and created_at <= ?", []interface{}{*s.asOf, *s.asOf, *s.asOf}
+}
+
+// scopedGlobalQuery selects from the view of the storyfeed unless it is scoped to an earlier time,
+// since the view always reflects the posts published now
+func scopedGlobalQuery(viewName string, asOf *time.Time, query func(storyfeedScope) (string, []interface{})) (string, []interface{}) {
+	if asOf == nil {
+		return selectFromViewQuery(viewName)
+	}
+	return query(storyfeedScope{asOf: asOf})
+}
+
+// chronologicalStoryfeed shows external links in reverse chronological order
+type chronologicalStoryfeed struct{}
+
+func (a *chronologicalStoryfeed) Name() string {
+	return chronologicalExternallinkViewName
+}
+
+func (a *chronologicalStoryfeed) Description() string {
+	return "External links in reverse chronological order"
+}
+
+func (a *chronologicalStoryfeed) query(scope storyfeedScope) (string, []interface{}) {
+	filter, args := scope.filter()
+	return fmt.Sprintf(`
+		select *, coalesce((data ->> 'published_time')::timestamptz, publish_at, created_at)::timestamptz as sort_date
+		from posts
+		where post_type = 'externallink' and not draft and not hidden and deleted_at is null %s
+		order by sort_date desc`, filter), args
+}
+
+func (a *chronologicalStoryfeed) CreateViewQuery() string {
+	query, _ := a.query(storyfeedScope{})
+	return createViewQuery(a.Name(), query)
+}
+
+func (a *chronologicalStoryfeed) GlobalQuery(asOf *time.Time) (string, []interface{}) {
+	return scopedGlobalQuery(a.Name(), asOf, a.query)
+}
+
+func (a *chronologicalStoryfeed) ChannelQuery(channelID string, asOf *time.Time) (string, []interface{}) {
+	return a.query(storyfeedScope{channelID: &channelID, asOf: asOf})
+}
+
+func (a *chronologicalStoryfeed) RankExpression() string {
+	return "0"
+}
+
+// chronologicalBoostfeed shows boosts in reverse chronological order
+type chronologicalBoostfeed struct{}
+
+func (a *chronologicalBoostfeed) Name() string {
+	return chronologicalBoostViewName
+}
+
+func (a *chronologicalBoostfeed) Description() string {
+	return "Boosts in reverse chronological order"
+}
+
+func (a *chronologicalBoostfeed) query(scope storyfeedScope) (string, []interface{}) {
+	filter, args := scope.filter()
+	return fmt.Sprintf(`
+		select *, coalesce(publish_at, created_at) as sort_date
+		from posts
+		where post_type = 'boost' and not draft and not hidden and deleted_at is null %s
+		order by sort_date desc`, filter), args
+}
+
+func (a *chronologicalBoostfeed) CreateViewQuery() string {
+	query, _ := a.query(storyfeedScope{})
+	return createViewQuery(a.Name(), query)
+}
+
+func (a *chronologicalBoostfeed) GlobalQuery(asOf *time.Time) (string, []interface{}) {
+	return scopedGlobalQuery(a.Name(), asOf, a.query)
+}
+
+func (a *chronologicalBoostfeed) ChannelQuery(channelID string, asOf *time.Time) (string, []interface{}) {
+	return a.query(storyfeedScope{channelID: &channelID, asOf: asOf})
+}
+
+func (a *chronologicalBoostfeed) RankExpression() string {
+	return "0"
+}
+
+// fairThenChronologicalStoryfeed shows the most recent external link from each channel,
+// followed by all the rest of the external links in reverse chronological order
+type fairThenChronologicalStoryfeed struct{}
+
+func (a *fairThenChronologicalStoryfeed) Name() string {
+	return fairThenChronologicalViewName
+}
+
+func (a *fairThenChronologicalStoryfeed) Description() string {
+	return "The most recent external link from each channel, then all other external links in reverse chronological order"
+}
+
+func (a *fairThenChronologicalStoryfeed) query(scope storyfeedScope) (string, []interface{}) {
+	filter, args := scope.filter()
+	return fmt.Sprintf(`
+		select *, (case when post_num = 1 then 1 ELSE null end) as rank  FROM
+		(
+			select
+				*,
+				count(1) over (partition by channel_id order by (sort_date) desc) as post_num
+				from
+				(
+				select
+					*,
+					coalesce((data ->> 'published_time')::timestamptz, publish_at, created_at)::timestamptz as sort_date
+					from posts
+					where post_type = 'externallink' and not draft and not hidden and deleted_at is null %s
+				) data2
+
+		) data
+		order by rank, sort_date desc`, filter), args
+}
+
+func (a *fairThenChronologicalStoryfeed) CreateViewQuery() string {
+	query, _ := a.query(storyfeedScope{})
+	return createViewQuery(a.Name(), query)
+}
+
+func (a *fairThenChronologicalStoryfeed) GlobalQuery(asOf *time.Time) (string, []interface{}) {
+	return scopedGlobalQuery(a.Name(), asOf, a.query)
+}
+
+// ChannelQuery for a single channel ranks identically to the chronological storyfeed
+func (a *fairThenChronologicalStoryfeed) ChannelQuery(channelID string, asOf *time.Time) (string, []interface{}) {
+	return a.query(storyfeedScope{channelID: &channelID, asOf: asOf})
+}
+
+// RankExpression puts the most recent link from each channel first. A channel's most recent link
+// only changes while paging if it publishes, which the scope of later pages excludes
+func (a *fairThenChronologicalStoryfeed) RankExpression() string {
+	return "coalesce(rank, 2)"
+}
+
+// fairWithInterleavedBoostsStoryfeed is the fair then chronological storyfeed with an active boost
+// shown after every `interleave` external links. The links are split into slots of `interleave` links,
+// and the nth oldest active boost follows the links of the nth slot
+type fairWithInterleavedBoostsStoryfeed struct {
+	globalInterleave  int
+	channelInterleave int
+}
+
+func (a *fairWithInterleavedBoostsStoryfeed) Name() string {
+	return fairWithInterleavedBoostsViewName
+}
+
+func (a *fairWithInterleavedBoostsStoryfeed) Description() string {
+	return "The fair then chronological storyfeed with active boosts interleaved"
+}
+
+func (a *fairWithInterleavedBoostsStoryfeed) query(scope storyfeedScope, interleave int) (string, []interface{}) {
+	filter, filterArgs := scope.filter()
+	active, activeArgs := scope.activeBoost()
+	args := append(append(append([]interface{}{}, filterArgs...), activeArgs...), filterArgs...)
+	// nolint: gosec
+	return fmt.Sprintf(`
+		SELECT * FROM (
+			SELECT * FROM (
+				SELECT *, (CASE WHEN post_num = 1 THEN 1 ELSE NULL END) AS rank,
+					(ROW_NUMBER() OVER (ORDER BY (CASE WHEN post_num = 1 THEN 1 ELSE NULL END), sort_date desc, id desc) - 1) / %d AS slot
+				FROM
+				(
+					SELECT
+						*,
+						COUNT(1) OVER (PARTITION BY channel_id order by (sort_date) desc) AS post_num
+						FROM
+						(
+						SELECT
+							*,
+							coalesce((data ->> 'published_time')::timestamptz, publish_at, created_at)::timestamptz AS sort_date
+							FROM posts
+							WHERE post_type = 'externallink' AND NOT draft AND NOT hidden AND deleted_at IS NULL %s
+						) data2
+
+				) data
+			) data3
+
+			UNION ALL
+
+			SELECT * FROM
+			(
+				SELECT *, 1 AS rank, ROW_NUMBER() OVER (ORDER BY sort_date, id) - 1 AS slot FROM
+				(
+					SELECT *, coalesce(publish_at, created_at) AS sort_date, 1 AS post_num
+					FROM posts
+					WHERE post_type = 'boost' AND NOT draft AND NOT hidden AND deleted_at IS NULL AND
+					%s %s
+
+				) data2
+			) data4
+		) feed
+		ORDER BY slot, post_type = 'boost', rank, sort_date desc`, interleave, filter, active, filter), args
+}
+
+func (a *fairWithInterleavedBoostsStoryfeed) CreateViewQuery() string {
+	query, _ := a.query(storyfeedScope{}, a.globalInterleave)
+	return createViewQuery(a.Name(), query)
+}
+
+func (a *fairWithInterleavedBoostsStoryfeed) GlobalQuery(asOf *time.Time) (string, []interface{}) {
+	return scopedGlobalQuery(a.Name(), asOf, func(scope storyfeedScope) (string, []interface{}) {
+		return a.query(scope, a.globalInterleave)
+	})
+}
+
+func (a *fairWithInterleavedBoostsStoryfeed) ChannelQuery(channelID string, asOf *time.Time) (string, []interface{}) {
+	return a.query(storyfeedScope{channelID: &channelID, asOf: asOf}, a.channelInterleave)
+}
+
+// RankExpression orders by slot, then the fair then chronological rank of the links in the slot,
+// then the boost that follows them
+func (a *fairWithInterleavedBoostsStoryfeed) RankExpression() string {
+	return "slot * 3 + (CASE WHEN post_type = 'boost' THEN 2 ELSE coalesce(rank, 2) - 1 END)"
+}
+
+// trendingStoryfeed ranks external links and active boosts by the completed payments they received, less refunds,
+// within the window. Each payment counts for 1 + ln(1 + usd), halved every halfLifeHours, so posts
+// with many recent or large payments surface first. Posts without payments follow in reverse chronological order
+type trendingStoryfeed struct {
+	windowHours   int
+	halfLifeHours int
+}
+
+func (a *trendingStoryfeed) Name() string {
+	return trendingViewName
+}
+
+func (a *trendingStoryfeed) Description() string {
+	return "External links and boosts ranked by the number and value of recent payments"
+}
+
+func (a *trendingStoryfeed) query(scope storyfeedScope) (string, []interface{}) {
+	scoredAt, paidBy, scoredArgs := scope.scoredAt()
+	active, activeArgs := scope.activeBoost()
+	filter, filterArgs := scope.filter()
+	args := append(append(scoredArgs, activeArgs...), filterArgs...)
+	// nolint: gosec
+	return fmt.Sprintf(`
+		select
+			posts.*,
+			coalesce((posts.data ->> 'published_time')::timestamptz, posts.publish_at, posts.created_at)::timestamptz as sort_date,
+			coalesce(support.payment_count, 0) as payment_count,
+			coalesce(support.usd_amount, 0) as usd_amount,
+			coalesce(support.score, 0) as trending_score
+		from posts
+		left join (
+			select
+				owner_id::uuid as post_id,
+				count(1) as payment_count,
+				sum((amount - refunded_amount) * exchange_rate) as usd_amount,
+				sum(
+					(1 + ln(1 + greatest((amount - refunded_amount) * exchange_rate, 0))) *
+					power(0.5, greatest(extract(epoch from %[1]s - created_at), 0) / 3600 / %[2]d)
+				) as score
+			from %[3]s
+			where owner_type = '%[4]s'
+			and status in ('complete', 'partially_refunded')
+			and deleted_at is null
+			and created_at > %[1]s - interval '%[5]d hours'
+			%[6]s
+			group by owner_id
+		) support on support.post_id = posts.id
+		where posts.deleted_at is null
+		and not posts.draft
+		and not posts.hidden
+		and (
+			posts.post_type = 'externallink' or
+			(posts.post_type = 'boost' and %[7]s)
+		) %[8]s
+		order by trending_score desc, sort_date desc`,
+		scoredAt, a.halfLifeHours, payments.PaymentModel{}.TableName(), TypePost, a.windowHours, paidBy, active, filter), args
+}
+
+func (a *trendingStoryfeed) CreateViewQuery() string {
+	query, _ := a.query(storyfeedScope{})
+	return createViewQuery(a.Name(), query)
+}
+
+func (a *trendingStoryfeed) GlobalQuery(asOf *time.Time) (string, []interface{}) {
+	return scopedGlobalQuery(a.Name(), asOf, a.query)
+}
+
+func (a *trendingStoryfeed) ChannelQuery(channelID string, asOf *time.Time) (string, []interface{}) {
+	return a.query(storyfeedScope{channelID: &channelID, asOf: asOf})
+}
+
+// RankExpression orders by score, to three decimal places, so that ties fall back to sort_date
+func (a *trendingStoryfeed) RankExpression() string {
+	return "-round(trending_score * 1000)::bigint"
+}
+
+// createViewQuery drops and recreates the view, since the columns selected by `*` change as the posts table gains columns
+func createViewQuery(viewName string, query string) string {
+	return fmt.Sprintf(`
+	DROP VIEW IF EXISTS %s;
+	CREATE VIEW %s as (
+		%s
+	)
+    `, viewName, viewName, query)
+}
+
+func selectFromViewQuery(viewName string) (string, []interface{}) {
+	return fmt.Sprintf("select * from %s", viewName), nil
+}
@@ -0,0 +1,96 @@
+package posts_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joincivil/civil-api-server/pkg/posts"
+)
+
+type testStoryfeedAlgorithm struct{}
+
+func (a *testStoryfeedAlgorithm) Name() string        { return "vw_post_boost_chronological" }
+func (a *testStoryfeedAlgorithm) Description() string { return "test" }
+func (a *testStoryfeedAlgorithm) CreateViewQuery() string {
+	return ""
+}
+func (a *testStoryfeedAlgorithm) GlobalQuery(asOf *time.Time) (string, []interface{}) {
+	return "select * from posts", nil
+}
+func (a *testStoryfeedAlgorithm) ChannelQuery(channelID string, asOf *time.Time) (string, []interface{}) {
+	return "select * from posts where channel_id = ?", []interface{}{channelID}
+}
+func (a *testStoryfeedAlgorithm) RankExpression() string { return "0" }
+
+func TestStoryfeedRegistry(t *testing.T) {
+	registry := posts.NewStoryfeedRegistry(posts.StoryfeedConfig{GlobalBoostInterleave: 7, ChannelBoostInterleave: 2})
+
+	algs := registry.Algorithms()
+	if len(algs) != 5 {
+		t.Fatalf("expected 5 built in algorithms, got %v", len(algs))
+	}
+
+	alg, ok := registry.Algorithm("vw_post_fair_with_interleaved_boosts_2")
+	if !ok {
+		t.Fatalf("expected interleaved boosts algorithm to be registered")
+	}
+	if !strings.Contains(alg.CreateViewQuery(), "/ 7 AS slot") {
+		t.Fatalf("expected global view to use configured interleave")
+	}
+	query, args := alg.ChannelQuery(aliceNewsroomUUID, nil)
+	if !strings.Contains(query, "/ 2 AS slot") {
+		t.Fatalf("expected channel query to use configured interleave")
+	}
+	if strings.Count(query, "?") != len(args) {
+		t.Fatalf("expected an argument for every placeholder in channel query")
+	}
+
+	asOf := time.Now()
+	for _, alg := range algs {
+		query, args = alg.GlobalQuery(&asOf)
+		if strings.Count(query, "?") != len(args) || len(args) == 0 {
+			t.Fatalf("expected %v to be scoped to the time of the first page", alg.Name())
+		}
+		query, args = alg.ChannelQuery(aliceNewsroomUUID, &asOf)
+		if strings.Count(query, "?") != len(args) {
+			t.Fatalf("expected an argument for every placeholder in %v channel query", alg.Name())
+		}
+		if !strings.Contains(strings.ToLower(query), "deleted_at is null") {
+			t.Fatalf("expected %v to exclude deleted posts", alg.Name())
+		}
+	}
+
+	trending, ok := registry.Algorithm("vw_post_trending")
+	if !ok {
+		t.Fatalf("expected trending algorithm to be registered")
+	}
+	query, _ = trending.GlobalQuery(&asOf)
+	if strings.Contains(query, "now()") || !strings.Contains(query, "and created_at <= ?") {
+		t.Fatalf("expected trending scores to be computed as of the time of the first page")
+	}
+
+	defaulted, _ := posts.NewStoryfeedRegistry(posts.StoryfeedConfig{ChannelBoostInterleave: -1}).
+		Algorithm("vw_post_fair_with_interleaved_boosts_2")
+	if !strings.Contains(defaulted.CreateViewQuery(), "/ 5 AS slot") {
+		t.Fatalf("expected global interleave to default when not configured")
+	}
+	query, _ = defaulted.ChannelQuery(aliceNewsroomUUID, nil)
+	if !strings.Contains(query, "/ 3 AS slot") {
+		t.Fatalf("expected channel interleave to default when not positive")
+	}
+
+	_, ok = registry.Algorithm("vw_does_not_exist")
+	if ok {
+		t.Fatalf("was not expecting unknown algorithm to be found")
+	}
+
+	registry.Register(&testStoryfeedAlgorithm{})
+	algs = registry.Algorithms()
+	if len(algs) != 5 {
+		t.Fatalf("expected registering an existing name to replace it, got %v algorithms", len(algs))
+	}
+	if algs[1].Description() != "test" {
+		t.Fatalf("expected replaced algorithm to keep its position")
+	}
+}
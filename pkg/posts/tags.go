@@ -0,0 +1,116 @@
+package posts
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gosimple/slug"
+)
+
+const (
+	maxPostTags = 10
+	maxTagName  = 50
+)
+
+// errors
+var (
+	ErrTooManyTags = errors.New("too many tags on post")
+	ErrBadTagName  = errors.New("tag name is empty or too long")
+)
+
+// normalizeTags converts tag names to Tags, merging names that have the same slug and dropping those that have none
+func normalizeTags(names []string) []Tag {
+	tags := []Tag{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		tagSlug := slug.Make(name)
+		if tagSlug == "" || seen[tagSlug] {
+			continue
+		}
+		seen[tagSlug] = true
+		tags = append(tags, Tag{Name: name, Slug: tagSlug})
+	}
+	return tags
+}
+
+// validateTagNames checks the tags a user submitted with a post
+func validateTagNames(names []string) error {
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if slug.Make(name) == "" || len(name) > maxTagName {
+			return ErrBadTagName
+		}
+	}
+	if len(normalizeTags(names)) > maxPostTags {
+		return ErrTooManyTags
+	}
+	return nil
+}
+
+// openGraphTagNames returns the article:tag values of scraped OpenGraph data that can be imported as tags
+func openGraphTagNames(names []string) []string {
+	var valid []string
+	for _, name := range names {
+		if validateTagNames([]string{name}) == nil {
+			valid = append(valid, name)
+		}
+	}
+	return valid
+}
+
+// setPostTags replaces the tags of a post with those named, when any are given
+func (s *Service) setPostTags(post Post, names []string) error {
+	if names == nil {
+		return nil
+	}
+	tags := normalizeTags(names)
+	if len(tags) > maxPostTags {
+		tags = tags[:maxPostTags]
+	}
+	_, err := s.SetPostTags(post.GetID(), tags)
+	return err
+}
+
+// EditPost applies a patch to a post, replacing its tags if the patch names any
+func (s *Service) EditPost(requestorUserID string, postID string, patch Post) (Post, error) {
+	names := patch.GetPostModel().TagNames
+	if err := validateTagNames(names); err != nil {
+		return nil, err
+	}
+
+	post, err := s.PostPersister.EditPost(requestorUserID, postID, patch)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.setPostTags(post, names); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// importOpenGraphTags adds the article:tag values of a link's OpenGraph data to the tags it already has
+func importOpenGraphTags(persister PostPersister, postID string, names []string) error {
+	names = openGraphTagNames(names)
+	if len(names) == 0 {
+		return nil
+	}
+	existing, err := persister.GetTagsForPosts([]string{postID})
+	if err != nil {
+		return err
+	}
+
+	var merged []string
+	for _, tag := range existing[0] {
+		merged = append(merged, tag.Name)
+	}
+	tags := normalizeTags(append(merged, names...))
+	if len(tags) == len(existing[0]) || len(existing[0]) >= maxPostTags {
+		return nil
+	}
+	if len(tags) > maxPostTags {
+		tags = tags[:maxPostTags]
+	}
+	_, err = persister.SetPostTags(postID, tags)
+	return err
+}
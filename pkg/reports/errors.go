@@ -0,0 +1,16 @@
+package reports
+
+import "errors"
+
+var (
+	// ErrorNotFound is returned when a report doesn't exist
+	ErrorNotFound = errors.New("report not found")
+	// ErrorInvalidReason is returned when a post is reported with an unknown reason
+	ErrorInvalidReason = errors.New("invalid report reason")
+	// ErrorInvalidAction is returned when a report is resolved with an unknown action
+	ErrorInvalidAction = errors.New("invalid moderation action")
+	// ErrorAlreadyReported is returned when a user reports a post they already have an open report for
+	ErrorAlreadyReported = errors.New("post already reported")
+	// ErrorAlreadyResolved is returned when acting on a report that has already been resolved
+	ErrorAlreadyResolved = errors.New("report already resolved")
+)
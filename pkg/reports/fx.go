@@ -0,0 +1,11 @@
+package reports
+
+import "go.uber.org/fx"
+
+// ReportModule builds report services
+var ReportModule = fx.Options(
+	fx.Provide(
+		NewDBPersister,
+		NewService,
+	),
+)
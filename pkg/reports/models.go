@@ -0,0 +1,112 @@
+package reports
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// Reason is why a user reported a post
+type Reason string
+
+// REASONS
+const (
+	ReasonSpam  Reason = "SPAM"
+	ReasonAbuse Reason = "ABUSE"
+	ReasonFraud Reason = "FRAUD"
+	ReasonOther Reason = "OTHER"
+)
+
+// IsValid returns whether the reason is a known one
+func (r Reason) IsValid() bool {
+	switch r {
+	case ReasonSpam, ReasonAbuse, ReasonFraud, ReasonOther:
+		return true
+	}
+	return false
+}
+
+// ActionType is an action a platform admin takes on a reported post
+type ActionType string
+
+// ACTIONS
+const (
+	// ActionDismiss closes the reports without changing the post
+	ActionDismiss ActionType = "DISMISS"
+	// ActionHidePost hides the post from everyone but platform admins
+	ActionHidePost ActionType = "HIDE_POST"
+	// ActionDeletePost soft deletes the post
+	ActionDeletePost ActionType = "DELETE_POST"
+	// ActionSuspendChannel suspends the channel the post was made in from posting
+	ActionSuspendChannel ActionType = "SUSPEND_CHANNEL"
+)
+
+// IsValid returns whether the action is a known one
+func (a ActionType) IsValid() bool {
+	switch a {
+	case ActionDismiss, ActionHidePost, ActionDeletePost, ActionSuspendChannel:
+		return true
+	}
+	return false
+}
+
+// STATUSES
+const (
+	// StatusOpen is the status of a report waiting in the moderation queue
+	StatusOpen = "OPEN"
+	// StatusDismissed is the status of a report closed without action
+	StatusDismissed = "DISMISSED"
+	// StatusActioned is the status of a report whose post was acted on
+	StatusActioned = "ACTIONED"
+)
+
+// Report is a user flagging a post for platform admins to review
+type Report struct {
+	ID               string `gorm:"type:uuid;primary_key"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	PostID           string `gorm:"type:uuid;not null;index:idx_report_post_id"`
+	ChannelID        string `gorm:"type:uuid;not null"` // channel the post was made in, kept in case the post is deleted
+	ReporterUserID   string `gorm:"type:uuid;not null;index:idx_report_reporter_user_id"`
+	Reason           Reason `gorm:"not null"`
+	Details          string
+	Status           string `gorm:"not null;index:idx_report_status"`
+	ResolvedAt       *time.Time
+	ResolvedByUserID *string `gorm:"type:uuid"`
+}
+
+// TableName returns the gorm table name for Report
+func (Report) TableName() string {
+	return "reports"
+}
+
+// BeforeCreate is a GORM hook that sets the ID before it its persisted
+func (r *Report) BeforeCreate() (err error) {
+	id := uuid.NewV4()
+	r.ID = id.String()
+	return
+}
+
+// ModerationAction is an entry in the audit trail of actions platform admins take on reported posts
+type ModerationAction struct {
+	ID              string `gorm:"type:uuid;primary_key"`
+	CreatedAt       time.Time
+	ModeratorUserID string     `gorm:"type:uuid;not null"`
+	Action          ActionType `gorm:"not null"`
+	ReportID        string     `gorm:"type:uuid;not null"`
+	PostID          string     `gorm:"type:uuid;not null;index:idx_moderation_action_post_id"`
+	ChannelID       *string    `gorm:"type:uuid"` // set when a channel is suspended
+	Note            string
+}
+
+// TableName returns the gorm table name for ModerationAction
+func (ModerationAction) TableName() string {
+	return "moderation_actions"
+}
+
+// BeforeCreate is a GORM hook that sets the ID before it its persisted
+func (a *ModerationAction) BeforeCreate() (err error) {
+	id := uuid.NewV4()
+	a.ID = id.String()
+	return
+}
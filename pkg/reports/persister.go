@@ -0,0 +1,11 @@
+package reports
+
+// Persister defines the methods needed to persist Reports and the moderation audit trail
+type Persister interface {
+	CreateReport(report *Report) error
+	GetReport(id string) (*Report, error)
+	GetOpenReport(postID string, reporterUserID string) (*Report, error)
+	GetReports(status string, limit int, offset int) ([]*Report, error)
+	ResolveReports(action *ModerationAction, status string) error
+	GetModerationActions(postID string, limit int, offset int) ([]*ModerationAction, error)
+}
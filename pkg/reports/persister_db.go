@@ -0,0 +1,105 @@
+package reports
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// DBPersister implements the Persister interface using GORM
+type DBPersister struct {
+	db *gorm.DB
+}
+
+// NewDBPersister instantiates a new DBPersister
+func NewDBPersister(db *gorm.DB) *DBPersister {
+	return &DBPersister{
+		db,
+	}
+}
+
+// CreateReport saves a new report
+func (p *DBPersister) CreateReport(report *Report) error {
+	if err := p.db.Create(report).Error; err != nil {
+		return errors.Wrap(err, "error creating report")
+	}
+
+	return nil
+}
+
+// GetReport retrieves a report by ID
+func (p *DBPersister) GetReport(id string) (*Report, error) {
+	report := &Report{}
+	if p.db.Where(&Report{ID: id}).First(report).RecordNotFound() {
+		return nil, ErrorNotFound
+	}
+
+	return report, nil
+}
+
+// GetOpenReport retrieves the open report a user made about a post
+func (p *DBPersister) GetOpenReport(postID string, reporterUserID string) (*Report, error) {
+	report := &Report{}
+	query := &Report{PostID: postID, ReporterUserID: reporterUserID, Status: StatusOpen}
+	if p.db.Where(query).First(report).RecordNotFound() {
+		return nil, ErrorNotFound
+	}
+
+	return report, nil
+}
+
+// GetReports retrieves reports with the given status, oldest first. All reports are returned if status is empty
+func (p *DBPersister) GetReports(status string, limit int, offset int) ([]*Report, error) {
+	var reports []*Report
+	stmt := p.db
+	if status != "" {
+		stmt = stmt.Where("status = ?", status)
+	}
+
+	err := stmt.Order("created_at, id").Limit(limit).Offset(offset).Find(&reports).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting reports")
+	}
+
+	return reports, nil
+}
+
+// ResolveReports records a moderation action and resolves all open reports on the action's post with the given status
+func (p *DBPersister) ResolveReports(action *ModerationAction, status string) error {
+	tx := p.db.Begin()
+	if err := tx.Create(action).Error; err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "error recording moderation action")
+	}
+
+	updates := map[string]interface{}{
+		"status":              status,
+		"resolved_at":         time.Now(),
+		"resolved_by_user_id": action.ModeratorUserID,
+	}
+	err := tx.Model(&Report{}).Where("post_id = ? AND status = ?", action.PostID, StatusOpen).Updates(updates).Error
+	if err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "error resolving reports")
+	}
+
+	return tx.Commit().Error
+}
+
+// GetModerationActions retrieves the audit trail of moderation actions, newest first.
+// All actions are returned if postID is empty
+func (p *DBPersister) GetModerationActions(postID string, limit int, offset int) ([]*ModerationAction, error) {
+	var actions []*ModerationAction
+	stmt := p.db
+	if postID != "" {
+		stmt = stmt.Where("post_id = ?", postID)
+	}
+
+	err := stmt.Order("created_at desc, id").Limit(limit).Offset(offset).Find(&actions).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting moderation actions")
+	}
+
+	return actions, nil
+}
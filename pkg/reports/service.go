@@ -0,0 +1,125 @@
+package reports
+
+import (
+	"github.com/joincivil/civil-api-server/pkg/channels"
+	"github.com/joincivil/civil-api-server/pkg/posts"
+)
+
+// PostHelper defines the post methods needed to report and moderate posts
+type PostHelper interface {
+	GetPost(id string) (posts.Post, error)
+	SetPostHidden(postID string, hidden bool) error
+	DeletePost(requestorUserID string, id string) error
+}
+
+// ChannelHelper defines the channel methods needed to suspend the channel of a reported post
+type ChannelHelper interface {
+	SetChannelSuspended(channelID string, suspended bool) (*channels.Channel, error)
+}
+
+// Service provides methods to report posts and work the moderation queue.
+// Callers are responsible for checking moderators are platform admins
+type Service struct {
+	persister     Persister
+	postHelper    PostHelper
+	channelHelper ChannelHelper
+}
+
+// NewService builds an instance of reports.Service
+func NewService(persister Persister, postHelper PostHelper, channelHelper ChannelHelper) *Service {
+	return &Service{
+		persister:     persister,
+		postHelper:    postHelper,
+		channelHelper: channelHelper,
+	}
+}
+
+// ReportPost adds a user's report about a post to the moderation queue
+func (s *Service) ReportPost(reporterUserID string, postID string, reason Reason, details string) (*Report, error) {
+	if !reason.IsValid() {
+		return nil, ErrorInvalidReason
+	}
+	post, err := s.postHelper.GetPost(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.persister.GetOpenReport(postID, reporterUserID)
+	if err == nil {
+		return nil, ErrorAlreadyReported
+	} else if err != ErrorNotFound {
+		return nil, err
+	}
+
+	report := &Report{
+		PostID:         postID,
+		ChannelID:      post.GetChannelID(),
+		ReporterUserID: reporterUserID,
+		Reason:         reason,
+		Details:        details,
+		Status:         StatusOpen,
+	}
+	if err = s.persister.CreateReport(report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// GetReport returns a report
+func (s *Service) GetReport(id string) (*Report, error) {
+	return s.persister.GetReport(id)
+}
+
+// GetQueue returns the reports with the given status, oldest first
+func (s *Service) GetQueue(status string, limit int, offset int) ([]*Report, error) {
+	return s.persister.GetReports(status, limit, offset)
+}
+
+// GetAuditTrail returns the moderation actions taken, optionally only those on a post, newest first
+func (s *Service) GetAuditTrail(postID string, limit int, offset int) ([]*ModerationAction, error) {
+	return s.persister.GetModerationActions(postID, limit, offset)
+}
+
+// Resolve takes an action on the post a report is about, resolving every open report on the post
+// and recording the action in the audit trail
+func (s *Service) Resolve(moderatorUserID string, reportID string, action ActionType, note string) (*Report, error) {
+	if !action.IsValid() {
+		return nil, ErrorInvalidAction
+	}
+	report, err := s.persister.GetReport(reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status != StatusOpen {
+		return nil, ErrorAlreadyResolved
+	}
+
+	moderationAction := &ModerationAction{
+		ModeratorUserID: moderatorUserID,
+		Action:          action,
+		ReportID:        report.ID,
+		PostID:          report.PostID,
+		Note:            note,
+	}
+	status := StatusActioned
+
+	switch action {
+	case ActionDismiss:
+		status = StatusDismissed
+	case ActionHidePost:
+		err = s.postHelper.SetPostHidden(report.PostID, true)
+	case ActionDeletePost:
+		err = s.postHelper.DeletePost(moderatorUserID, report.PostID)
+	case ActionSuspendChannel:
+		_, err = s.channelHelper.SetChannelSuspended(report.ChannelID, true)
+		moderationAction.ChannelID = &report.ChannelID
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.persister.ResolveReports(moderationAction, status); err != nil {
+		return nil, err
+	}
+	return s.persister.GetReport(reportID)
+}
@@ -0,0 +1,223 @@
+package reports_test
+
+import (
+	"testing"
+
+	"github.com/joincivil/civil-api-server/pkg/channels"
+	"github.com/joincivil/civil-api-server/pkg/posts"
+	"github.com/joincivil/civil-api-server/pkg/reports"
+)
+
+const (
+	testPostID      = "5b0a4a57-07d3-4f0f-a3cf-0c1e5d6f3a01"
+	testChannelID   = "0a7c4bd1-5dd2-4b0d-9b34-a7b1fdd3d2f1"
+	testReporterID  = "3d1b7f3c-2a6e-4c8e-9f51-7b2f0c8d9e12"
+	testReporter2ID = "9c4e2d1a-6b3f-4e7a-8d20-1f5a6b7c8d13"
+	testModeratorID = "8ef93e08-4ab8-4c39-a1f4-8f7a2a0c9d11"
+)
+
+type mockPersister struct {
+	reports []*reports.Report
+	actions []*reports.ModerationAction
+}
+
+func (p *mockPersister) CreateReport(report *reports.Report) error {
+	report.ID = mockReportID(len(p.reports))
+	p.reports = append(p.reports, report)
+	return nil
+}
+
+func (p *mockPersister) GetReport(id string) (*reports.Report, error) {
+	for _, report := range p.reports {
+		if report.ID == id {
+			return report, nil
+		}
+	}
+	return nil, reports.ErrorNotFound
+}
+
+func (p *mockPersister) GetOpenReport(postID string, reporterUserID string) (*reports.Report, error) {
+	for _, report := range p.reports {
+		if report.PostID == postID && report.ReporterUserID == reporterUserID && report.Status == reports.StatusOpen {
+			return report, nil
+		}
+	}
+	return nil, reports.ErrorNotFound
+}
+
+func (p *mockPersister) GetReports(status string, limit int, offset int) ([]*reports.Report, error) {
+	var results []*reports.Report
+	for _, report := range p.reports {
+		if status == "" || report.Status == status {
+			results = append(results, report)
+		}
+	}
+	return results, nil
+}
+
+func (p *mockPersister) ResolveReports(action *reports.ModerationAction, status string) error {
+	p.actions = append(p.actions, action)
+	for _, report := range p.reports {
+		if report.PostID == action.PostID && report.Status == reports.StatusOpen {
+			report.Status = status
+			report.ResolvedByUserID = &action.ModeratorUserID
+		}
+	}
+	return nil
+}
+
+func (p *mockPersister) GetModerationActions(postID string, limit int, offset int) ([]*reports.ModerationAction, error) {
+	return p.actions, nil
+}
+
+type mockPostHelper struct {
+	hidden  map[string]bool
+	deleted map[string]bool
+}
+
+func (h *mockPostHelper) GetPost(id string) (posts.Post, error) {
+	if id != testPostID || h.deleted[id] {
+		return nil, posts.ErrorNotFound
+	}
+	return &posts.Comment{PostModel: posts.PostModel{ID: id, ChannelID: testChannelID}}, nil
+}
+
+func (h *mockPostHelper) SetPostHidden(postID string, hidden bool) error {
+	h.hidden[postID] = hidden
+	return nil
+}
+
+func (h *mockPostHelper) DeletePost(requestorUserID string, id string) error {
+	h.deleted[id] = true
+	return nil
+}
+
+type mockChannelHelper struct {
+	suspended map[string]bool
+}
+
+func (h *mockChannelHelper) SetChannelSuspended(channelID string, suspended bool) (*channels.Channel, error) {
+	h.suspended[channelID] = suspended
+	return &channels.Channel{ID: channelID}, nil
+}
+
+func mockReportID(n int) string {
+	return string(rune('a' + n))
+}
+
+func newTestService() (*reports.Service, *mockPersister, *mockPostHelper, *mockChannelHelper) {
+	persister := &mockPersister{}
+	postHelper := &mockPostHelper{hidden: map[string]bool{}, deleted: map[string]bool{}}
+	channelHelper := &mockChannelHelper{suspended: map[string]bool{}}
+	return reports.NewService(persister, postHelper, channelHelper), persister, postHelper, channelHelper
+}
+
+func TestReportPost(t *testing.T) {
+	service, _, _, _ := newTestService()
+
+	report, err := service.ReportPost(testReporterID, testPostID, reports.ReasonSpam, "buy my coins")
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if report.Status != reports.StatusOpen || report.ChannelID != testChannelID {
+		t.Fatalf("expected an open report recording the post's channel")
+	}
+
+	_, err = service.ReportPost(testReporterID, testPostID, reports.ReasonAbuse, "")
+	if err != reports.ErrorAlreadyReported {
+		t.Fatalf("was expecting already reported error: %v", err)
+	}
+	_, err = service.ReportPost(testReporter2ID, testPostID, reports.Reason("BORING"), "")
+	if err != reports.ErrorInvalidReason {
+		t.Fatalf("was expecting invalid reason error: %v", err)
+	}
+	_, err = service.ReportPost(testReporter2ID, "not-a-post", reports.ReasonSpam, "")
+	if err != posts.ErrorNotFound {
+		t.Fatalf("was expecting not found error reporting a missing post: %v", err)
+	}
+}
+
+func TestResolveReports(t *testing.T) {
+	service, persister, postHelper, channelHelper := newTestService()
+
+	first, err := service.ReportPost(testReporterID, testPostID, reports.ReasonFraud, "")
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	second, err := service.ReportPost(testReporter2ID, testPostID, reports.ReasonFraud, "")
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+
+	_, err = service.Resolve(testModeratorID, first.ID, reports.ActionType("BAN"), "")
+	if err != reports.ErrorInvalidAction {
+		t.Fatalf("was expecting invalid action error: %v", err)
+	}
+
+	resolved, err := service.Resolve(testModeratorID, first.ID, reports.ActionHidePost, "fake fundraiser")
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if resolved.Status != reports.StatusActioned || second.Status != reports.StatusActioned {
+		t.Fatalf("expected every open report on the post to be actioned")
+	}
+	if !postHelper.hidden[testPostID] {
+		t.Fatalf("expected the post to be hidden")
+	}
+
+	_, err = service.Resolve(testModeratorID, second.ID, reports.ActionDismiss, "")
+	if err != reports.ErrorAlreadyResolved {
+		t.Fatalf("was expecting already resolved error: %v", err)
+	}
+
+	third, err := service.ReportPost(testReporterID, testPostID, reports.ReasonFraud, "still up")
+	if err != nil {
+		t.Fatalf("was not expecting an error reporting again after resolution: %v", err)
+	}
+	_, err = service.Resolve(testModeratorID, third.ID, reports.ActionSuspendChannel, "")
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if !channelHelper.suspended[testChannelID] {
+		t.Fatalf("expected the post's channel to be suspended")
+	}
+
+	fourth, err := service.ReportPost(testReporter2ID, testPostID, reports.ReasonOther, "")
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	dismissed, err := service.Resolve(testModeratorID, fourth.ID, reports.ActionDismiss, "")
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if dismissed.Status != reports.StatusDismissed {
+		t.Fatalf("expected the report to be dismissed, got %v", dismissed.Status)
+	}
+
+	trail, err := service.GetAuditTrail(testPostID, 10, 0)
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if len(trail) != 3 || trail[0].Action != reports.ActionHidePost || trail[0].ModeratorUserID != testModeratorID ||
+		trail[0].Note != "fake fundraiser" {
+		t.Fatalf("expected every action to be recorded in the audit trail")
+	}
+	if trail[1].ChannelID == nil || *trail[1].ChannelID != testChannelID {
+		t.Fatalf("expected the suspended channel to be recorded")
+	}
+	if len(persister.actions) != 3 {
+		t.Fatalf("expected 3 recorded actions, got %v", len(persister.actions))
+	}
+
+	fifth, err := service.ReportPost(testReporterID, testPostID, reports.ReasonSpam, "")
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	_, err = service.Resolve(testModeratorID, fifth.ID, reports.ActionDeletePost, "")
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if !postHelper.deleted[testPostID] {
+		t.Fatalf("expected the post to be deleted")
+	}
+}
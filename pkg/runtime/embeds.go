@@ -0,0 +1,20 @@
+package runtime
+
+import (
+	"github.com/joincivil/civil-api-server/pkg/embeds"
+	"github.com/joincivil/civil-api-server/pkg/payments"
+	"github.com/joincivil/civil-api-server/pkg/posts"
+	"go.uber.org/fx"
+)
+
+// EmbedsRuntime builds embeddable widget services with concrete implementations
+var EmbedsRuntime = fx.Options(
+	fx.Provide(
+		func(postService *posts.Service) embeds.PostSource {
+			return postService
+		},
+		func(paymentService *payments.Service) embeds.PaymentSource {
+			return paymentService
+		},
+	),
+)
@@ -0,0 +1,30 @@
+package runtime
+
+import (
+	"github.com/joincivil/civil-api-server/pkg/channels"
+	"github.com/joincivil/civil-api-server/pkg/feeds"
+	"github.com/joincivil/civil-api-server/pkg/newsrooms"
+	"github.com/joincivil/civil-api-server/pkg/posts"
+	"go.uber.org/fx"
+)
+
+// FeedsRuntime builds feed services with concrete implementations
+var FeedsRuntime = fx.Options(
+	fx.Provide(
+		func(dbPersister *feeds.DBPersister) feeds.Persister {
+			return dbPersister
+		},
+		func(postService *posts.Service) feeds.PostCreator {
+			return postService
+		},
+		func(channelService *channels.Service) feeds.ChannelHelper {
+			return channelService
+		},
+		func(postService *posts.Service) feeds.StoryfeedSource {
+			return postService
+		},
+		func(newsroomService newsrooms.Service) feeds.NewsroomHelper {
+			return newsroomService
+		},
+	),
+)
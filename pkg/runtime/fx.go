@@ -1,25 +1,37 @@
 package runtime
 
 import (
+	"github.com/joincivil/civil-api-server/pkg/blobstore"
 	"github.com/joincivil/civil-api-server/pkg/channels"
+	"github.com/joincivil/civil-api-server/pkg/embeds"
+	"github.com/joincivil/civil-api-server/pkg/feeds"
+	"github.com/joincivil/civil-api-server/pkg/images"
 	"github.com/joincivil/civil-api-server/pkg/jsonstore"
 	"github.com/joincivil/civil-api-server/pkg/newsrooms"
 	"github.com/joincivil/civil-api-server/pkg/nrsignup"
 	"github.com/joincivil/civil-api-server/pkg/payments"
 	"github.com/joincivil/civil-api-server/pkg/posts"
+	"github.com/joincivil/civil-api-server/pkg/reports"
 	"github.com/joincivil/civil-api-server/pkg/storefront"
 	"github.com/joincivil/civil-api-server/pkg/users"
+	"github.com/joincivil/civil-api-server/pkg/utils"
 	"go.uber.org/fx"
 )
 
 // Services provide abstract implementations
 var Services = fx.Options(
+	fx.Provide(utils.NewFetcherFromConfig),
 	storefront.RuntimeModule,
 	NewsroomRuntime,
 	NrSignupRuntime,
 	payments.PaymentModule,
+	blobstore.BlobModule,
+	images.ImageModule,
 	channels.ChannelModule,
 	posts.PostModule,
+	feeds.FeedModule,
+	embeds.EmbedModule,
+	reports.ReportModule,
 	users.UserModule,
 	storefront.StorefrontModule,
 	newsrooms.NewsroomModule,
@@ -34,5 +46,9 @@ var Module = fx.Options(
 	ChannelsRuntime,
 	UsersRuntime,
 	PaymentsRuntime,
+	FeedsRuntime,
+	EmbedsRuntime,
+	PostsRuntime,
+	ReportsRuntime,
 	JsonbRuntime,
 )
@@ -2,8 +2,11 @@ package runtime
 
 import (
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
 	"github.com/joincivil/civil-api-server/pkg/channels"
 	"github.com/joincivil/civil-api-server/pkg/payments"
+	"github.com/joincivil/civil-api-server/pkg/storefront"
+	"github.com/joincivil/civil-api-server/pkg/utils"
 	"github.com/joincivil/go-common/pkg/eth"
 	"go.uber.org/fx"
 )
@@ -14,12 +17,29 @@ var PaymentsRuntime = fx.Options(
 		func(helper *eth.Helper) ethereum.TransactionReader {
 			return helper.Blockchain.(ethereum.TransactionReader)
 		},
+		func(helper *eth.Helper) payments.ChainReader {
+			// the simulated backend used in development can't read headers
+			if backend, ok := helper.Blockchain.(*backends.SimulatedBackend); ok {
+				return payments.NewSimulatedChainReader(backend)
+			}
+			return helper.Blockchain.(payments.ChainReader)
+		},
 		func(channel *channels.Service) payments.ChannelHelper {
 			return channel
 		},
 		func(ethpay *payments.EthereumPaymentService) payments.EthereumValidator {
 			return ethpay
 		},
+		func(helper *eth.Helper, config *utils.GraphQLConfig) (payments.TokenPriceSource, error) {
+			pricing, err := storefront.NewTokenSalePricing(config.ContractAddresses["CVLToken"], config.TokenSaleAddresses, helper.Blockchain)
+			if err != nil {
+				return nil, err
+			}
+			return payments.NewTokenPrices(pricing, config.TokenPaymentPricesUsd), nil
+		},
+		func(tokenPay *payments.TokenPaymentService) payments.TokenValidator {
+			return tokenPay
+		},
 		func(stripe *payments.StripeService) payments.StripeCharger {
 			return stripe
 		},
@@ -0,0 +1,27 @@
+package runtime
+
+import (
+	"github.com/joincivil/civil-api-server/pkg/channels"
+	"github.com/joincivil/civil-api-server/pkg/images"
+	"github.com/joincivil/civil-api-server/pkg/payments"
+	"github.com/joincivil/civil-api-server/pkg/posts"
+	"go.uber.org/fx"
+)
+
+// PostsRuntime builds post services with concrete implementations
+var PostsRuntime = fx.Options(
+	fx.Provide(
+		func(paymentService *payments.Service) posts.BoostPaymentHelper {
+			return paymentService
+		},
+		func(channelService *channels.Service) posts.BoostChannelHelper {
+			return channelService
+		},
+		func(lifecycleService *posts.BoostLifecycleService) posts.BoostUpdateNotifier {
+			return lifecycleService
+		},
+		func(imagePipeline *images.Pipeline) posts.BoostImageStorer {
+			return imagePipeline
+		},
+	),
+)
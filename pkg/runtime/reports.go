@@ -0,0 +1,23 @@
+package runtime
+
+import (
+	"github.com/joincivil/civil-api-server/pkg/channels"
+	"github.com/joincivil/civil-api-server/pkg/posts"
+	"github.com/joincivil/civil-api-server/pkg/reports"
+	"go.uber.org/fx"
+)
+
+// ReportsRuntime builds report services with concrete implementations
+var ReportsRuntime = fx.Options(
+	fx.Provide(
+		func(dbPersister *reports.DBPersister) reports.Persister {
+			return dbPersister
+		},
+		func(postService *posts.Service) reports.PostHelper {
+			return postService
+		},
+		func(channelService *channels.Service) reports.ChannelHelper {
+			return channelService
+		},
+	),
+)
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	log "github.com/golang/glog"
 
@@ -57,6 +58,27 @@ type Service struct {
 func NewService(cvlTokenAddr string, tokenSaleAddrs []common.Address, ethHelper *eth.Helper,
 	userService *users.UserService, emailLists ServiceEmailLists) (*Service, error) {
 
+	var blockchain bind.ContractBackend
+	if ethHelper != nil {
+		blockchain = ethHelper.Blockchain
+	}
+	pricingManager, err := NewTokenSalePricing(cvlTokenAddr, tokenSaleAddrs, blockchain)
+	if err != nil {
+		return nil, err
+	}
+	currencyConversion := NewKrakenCurrencyConversion(defaultKrakenPollFreqSecs)
+
+	return &Service{
+		pricing:            pricingManager,
+		currencyConversion: currencyConversion,
+		userService:        userService,
+		emailLists:         emailLists,
+	}, nil
+}
+
+// NewTokenSalePricing constructs the PricingManager of the token sale. When the CVLToken and token sale
+// addresses are configured, a SupplyManager keeps the tokens sold, and so the price, up to date
+func NewTokenSalePricing(cvlTokenAddr string, tokenSaleAddrs []common.Address, blockchain bind.ContractBackend) (*PricingManager, error) {
 	initSupplyManager := true
 	cvlTokenAddress := common.HexToAddress(cvlTokenAddr)
 	if cvlTokenAddress == common.HexToAddress("") {
@@ -69,12 +91,11 @@ func NewService(cvlTokenAddr string, tokenSaleAddrs []common.Address, ethHelper
 	}
 
 	pricingManager := NewPricingManager(totalOffering, totalRaiseUSD, startingPrice)
-	currencyConversion := NewKrakenCurrencyConversion(defaultKrakenPollFreqSecs)
 
 	if initSupplyManager {
 		_, err := NewSupplyManager(
 			cvlTokenAddress,
-			ethHelper.Blockchain,
+			blockchain,
 			pricingManager,
 			tokenSaleAddrs,
 			tokenSupplyPollFreqSecs,
@@ -84,12 +105,7 @@ func NewService(cvlTokenAddr string, tokenSaleAddrs []common.Address, ethHelper
 		}
 	}
 
-	return &Service{
-		pricing:            pricingManager,
-		currencyConversion: currencyConversion,
-		userService:        userService,
-		emailLists:         emailLists,
-	}, nil
+	return pricingManager, nil
 }
 
 // BuildService makes a Service with the specified parameters
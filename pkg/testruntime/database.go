@@ -3,8 +3,10 @@ package testruntime
 import (
 	"github.com/jinzhu/gorm"
 	"github.com/joincivil/civil-api-server/pkg/channels"
+	"github.com/joincivil/civil-api-server/pkg/feeds"
 	"github.com/joincivil/civil-api-server/pkg/payments"
 	"github.com/joincivil/civil-api-server/pkg/posts"
+	"github.com/joincivil/civil-api-server/pkg/reports"
 	"github.com/pkg/errors"
 
 	// load postgres specific dialect
@@ -17,8 +19,20 @@ func RunMigrations(db *gorm.DB) error {
 	models := []interface{}{
 		&channels.Channel{},
 		&channels.ChannelMember{},
+		&channels.ChannelFollow{},
+		&feeds.ChannelFeed{},
+		&feeds.ChannelFeedItem{},
 		&posts.PostModel{},
+		&posts.ExternalLinkRefresh{},
+		&posts.PostRevision{},
+		&posts.BoostLifecycle{},
+		&posts.CommentModeration{},
+		&posts.PostReaction{},
+		&posts.Tag{},
 		&payments.PaymentModel{},
+		&payments.StripeSubscription{},
+		&reports.Report{},
+		&reports.ModerationAction{},
 	}
 
 	for _, model := range models {
@@ -31,6 +31,9 @@ var TestModule = fx.Options(
 	runtime.EthRuntime,
 	runtime.UsersRuntime,
 	runtime.ChannelsRuntime,
+	runtime.FeedsRuntime,
+	runtime.PostsRuntime,
+	runtime.ReportsRuntime,
 	runtime.JsonbRuntime,
 	fx.Provide(
 		testutils.GetTestDBConnection,
@@ -41,12 +44,21 @@ var TestModule = fx.Options(
 		func(mockTxReader *MockTransactionReader) ethereum.TransactionReader {
 			return mockTxReader
 		},
+		func(mockTxReader *MockTransactionReader) payments.ChainReader {
+			return mockTxReader
+		},
 		func() storefront.CurrencyConversion {
 			return storefront.StaticCurrencyConversion{PriceOfETH: 100}
 		},
 		func(ethPay *payments.EthereumPaymentService) payments.EthereumValidator {
 			return ethPay
 		},
+		func() payments.TokenPriceSource {
+			return payments.StaticTokenPrices{"CVL": 0.2, "DAI": 1, "USDC": 1}
+		},
+		func(tokenPay *payments.TokenPaymentService) payments.TokenValidator {
+			return tokenPay
+		},
 		func(paymentHelper *MockPaymentHelper) payments.StripeCharger {
 			return paymentHelper
 		},
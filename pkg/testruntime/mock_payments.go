@@ -8,12 +8,13 @@ import (
 
 // MockPaymentHelper implements payments.StripeCharger, and ChannelHelper interface
 type MockPaymentHelper struct {
-	txs *MockTransactionReader
+	txs     *MockTransactionReader
+	refunds map[string]int64
 }
 
 // NewMockPaymentHelper creates a new NewMockPaymentHelper
 func NewMockPaymentHelper(txs *MockTransactionReader) *MockPaymentHelper {
-	return &MockPaymentHelper{txs}
+	return &MockPaymentHelper{txs, map[string]int64{}}
 }
 
 // CreateCharge is a mock to create a stripe charge
@@ -51,6 +52,36 @@ func (p *MockPaymentHelper) RemovePaymentMethod(paymentMethodID string) error {
 	return nil
 }
 
+// CreateRefund is a mock to refund a charge, it keeps a total of the amount refunded from each charge
+func (p *MockPaymentHelper) CreateRefund(request payments.CreateRefundRequest) (payments.CreateRefundResponse, error) {
+	p.refunds[request.Reference] += request.Amount
+	return payments.CreateRefundResponse{ID: "re_" + request.Reference, AmountRefunded: p.refunds[request.Reference]}, nil
+}
+
+// CreateSubscription is a mock to create a subscription
+func (p *MockPaymentHelper) CreateSubscription(request payments.CreateSubscriptionRequest) (payments.CreateSubscriptionResponse, error) {
+	return payments.CreateSubscriptionResponse{
+		ID:         "sub_" + request.PaymentMethodID,
+		Status:     "active",
+		CustomerID: "cus_" + request.CustomerID,
+	}, nil
+}
+
+// PauseSubscription is a mock to pause a subscription
+func (p *MockPaymentHelper) PauseSubscription(request payments.SubscriptionRequest) error {
+	return nil
+}
+
+// ResumeSubscription is a mock to resume a subscription
+func (p *MockPaymentHelper) ResumeSubscription(request payments.SubscriptionRequest) error {
+	return nil
+}
+
+// CancelSubscription is a mock to cancel a subscription
+func (p *MockPaymentHelper) CancelSubscription(request payments.SubscriptionRequest) error {
+	return nil
+}
+
 // GetEthereumPaymentAddress returns a mock eth account for the channel address
 func (p *MockPaymentHelper) GetEthereumPaymentAddress(channelID string) (common.Address, error) {
 
@@ -2,16 +2,18 @@ package testruntime
 
 import (
 	"context"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
-// MockTransactionReader implements eth.TransactionReader interface
+// MockTransactionReader implements eth.TransactionReader and payments.ChainReader interfaces
 type MockTransactionReader struct {
 	Transactions map[string]*types.Transaction
 	Receipts     map[string]*types.Receipt
+	BlockNumber  uint64
 }
 
 // AddTransaction adds a transaction
@@ -24,6 +26,21 @@ func (r *MockTransactionReader) AddReceipt(txID common.Hash, receipt *types.Rece
 	r.Receipts[txID.String()] = receipt
 }
 
+// AdvanceBlocks mines `count` empty blocks, adding a confirmation to every receipt
+func (r *MockTransactionReader) AdvanceBlocks(count uint64) {
+	r.BlockNumber += count
+}
+
+// Reorg simulates a chain reorganization, replacing the receipt of a transaction with one in another block
+// or removing it when `receipt` is nil, as if the transaction was returned to the mempool
+func (r *MockTransactionReader) Reorg(txID common.Hash, receipt *types.Receipt) {
+	if receipt == nil {
+		delete(r.Receipts, txID.String())
+		return
+	}
+	r.Receipts[txID.String()] = receipt
+}
+
 // TransactionByHash gets the mock tx by hash
 func (r *MockTransactionReader) TransactionByHash(ctx context.Context, txHash common.Hash) (tx *types.Transaction, isPending bool, err error) {
 
@@ -48,6 +65,17 @@ func (r *MockTransactionReader) TransactionReceipt(ctx context.Context, txHash c
 	return receipt, nil
 }
 
+// HeaderByNumber gets a mock header, the latest block when `number` is nil
+func (r *MockTransactionReader) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil {
+		number = new(big.Int).SetUint64(r.BlockNumber)
+	}
+	if number.Uint64() > r.BlockNumber {
+		return nil, ethereum.NotFound
+	}
+	return &types.Header{Number: number}, nil
+}
+
 // NewMockTransactionReader creates a new MockTransactionReader
 func NewMockTransactionReader() *MockTransactionReader {
 	transactions := make(map[string]*types.Transaction)
@@ -28,6 +28,8 @@ type GraphQLConfig struct {
 	JwtSecret   string   `split_words:"true" desc:"Secret used to encode JWT tokens"`
 	AuthDomains []string `split_works:"true" required:"true" desc:"Domains that are allowed to authenticate"`
 
+	AdminUserIDs []string `split_words:"true" desc:"User IDs of platform admins, who can work the moderation queue"`
+
 	AuthEmailSignupTemplates map[string]string `split_words:"true" required:"false" desc:"<appname>:<template id>,..."`
 	AuthEmailLoginTemplates  map[string]string `split_words:"true" required:"false" desc:"<appname>:<template id>,..."`
 
@@ -63,6 +65,12 @@ type GraphQLConfig struct {
 	StripeApplePayDomains      []string `split_words:"true" desc:"Domains to enable Apple Pay on" default:"" `
 	StripeWebhookSigningSecret string   `envconfig:"stripe_webhook_signing_secret" split_words:"true" desc:"Signing Secret for Stripe Webhook Events"`
 
+	EthPaymentConfirmations          uint64 `split_words:"true" default:"12" desc:"Number of confirmations before an ETH or token payment is complete"`
+	EthPaymentTraceInternalTransfers bool   `split_words:"true" default:"false" desc:"Trace transactions with debug_traceTransaction on the Ethereum API to accept ETH payments sent by contract wallets"`
+
+	TokenPaymentTokens    []string           `split_words:"true" default:"DAI:0x6B175474E89094C44Da98b954EedeAC495271d0F:18,USDC:0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48:6" desc:"ERC-20 tokens accepted for payments as <symbol>:<contract address>:<decimals>, in addition to CVL"`
+	TokenPaymentPricesUsd map[string]float64 `split_words:"true" default:"DAI:1,USDC:1" desc:"USD price of each token accepted for payments as <symbol>:<price>, CVL is priced by the token sale. Payments in tokens without a price stay pending"`
+
 	TokenFoundryUser     string `split_words:"true" desc:"TokenFoundry User"`
 	TokenFoundryPassword string `split_words:"true" desc:"TokenFoundry Password"`
 
@@ -78,6 +86,51 @@ type GraphQLConfig struct {
 
 	RefreshTokenBlacklist []string `split_words:"true" desc:"List of refresh tokens to blacklist"`
 
+	StoryfeedGlobalBoostInterleave  int `split_words:"true" default:"5" desc:"Number of links between each boost in the global storyfeed"`
+	StoryfeedChannelBoostInterleave int `split_words:"true" default:"3" desc:"Number of links between each boost in a channel storyfeed"`
+	StoryfeedTrendingWindowHours    int `split_words:"true" default:"72" desc:"Hours of payments counted by the trending storyfeed"`
+	StoryfeedTrendingHalfLifeHours  int `split_words:"true" default:"24" desc:"Hours for a payment's weight to halve in the trending storyfeed"`
+
+	FetchTimeoutSecs          int   `split_words:"true" default:"15" desc:"Seconds to wait when fetching a user submitted URL"`
+	FetchMaxBodyBytes         int64 `split_words:"true" default:"5242880" desc:"Maximum size of a fetched response body"`
+	FetchMaxRedirects         int   `split_words:"true" default:"5" desc:"Maximum number of redirects followed when fetching a URL"`
+	FetchAllowPrivateNetworks bool  `split_words:"true" default:"false" desc:"Allow fetching private and loopback addresses, for local development only"`
+
+	FeedPollIntervalSecs int `split_words:"true" default:"900" desc:"Seconds between polls of newsroom RSS/Atom feeds"`
+	FeedMaxItemsPerPoll  int `split_words:"true" default:"20" desc:"Maximum number of posts created from a single feed poll"`
+
+	SyndicationSiteURL   string `split_words:"true" default:"https://registry.civil.co" desc:"Base URL of the site linked to from syndicated storyfeeds"`
+	SyndicationItemLimit int    `split_words:"true" default:"50" desc:"Number of posts included in syndicated storyfeeds"`
+
+	EmbedSiteURL      string `split_words:"true" default:"https://registry.civil.co" desc:"Base URL of the site the embeddable boost widget links to"`
+	EmbedAPIURL       string `split_words:"true" default:"http://localhost:8080/v1/embed" desc:"Base URL the embeddable boost widget and oEmbed endpoints are served from"`
+	EmbedCacheMaxAge  int    `split_words:"true" default:"60" desc:"Seconds embeddable boost widgets may be cached for"`
+	EmbedProviderName string `split_words:"true" default:"Civil" desc:"Provider name returned by the oEmbed endpoint"`
+
+	OpenGraphRefreshPollSecs        int `split_words:"true" default:"300" desc:"Seconds between checks for external links due an OpenGraph refresh"`
+	OpenGraphRefreshIntervalMins    int `split_words:"true" default:"360" desc:"Minutes between OpenGraph refreshes of an external link whose metadata changed"`
+	OpenGraphRefreshMaxIntervalMins int `split_words:"true" default:"10080" desc:"Maximum minutes between OpenGraph refreshes after backing off"`
+	OpenGraphRefreshWindowDays      int `split_words:"true" default:"30" desc:"Days after creation that an external link's OpenGraph data is refreshed"`
+	OpenGraphRefreshBatchSize       int `split_words:"true" default:"50" desc:"Maximum external links refreshed per check"`
+
+	PostPublishPollSecs int `split_words:"true" default:"60" desc:"Seconds between checks for scheduled posts due to be published"`
+	CommentMaxDepth     int `split_words:"true" default:"5" desc:"Maximum depth of replies in a comment thread"`
+
+	BoostLifecyclePollSecs          int    `split_words:"true" default:"300" desc:"Seconds between checks for boost status transitions"`
+	BoostLifecycleNotifyWindowHours int    `split_words:"true" default:"168" desc:"Hours after a boost ends that status emails are still sent"`
+	BoostEndedEmailTemplateID       string `split_words:"true" desc:"Sendgrid template emailed to channel admins when a boost ends. If not set, no email is sent"`
+	BoostGoalReachedEmailTemplateID string `split_words:"true" desc:"Sendgrid template emailed to supporters when a boost reaches its goal. If not set, no email is sent"`
+	BoostUpdateEmailTemplateID      string `split_words:"true" desc:"Sendgrid template emailed to supporters when a boost update is posted. If not set, no email is sent"`
+
+	BlobstoreBackend           string `split_words:"true" default:"local" desc:"Where blobs such as images are stored, either local or s3"`
+	BlobstoreBaseURL           string `split_words:"true" default:"http://localhost:8080/v1/blobs" desc:"Base URL blobs are served from, the local backend serves them under /v1/blobs"`
+	BlobstoreLocalDir          string `split_words:"true" default:"./blobs" desc:"Directory blobs are stored in by the local backend"`
+	BlobstoreS3Endpoint        string `split_words:"true" default:"https://s3.amazonaws.com" desc:"Base URL of the S3 compatible API used by the s3 backend"`
+	BlobstoreS3Region          string `split_words:"true" default:"us-east-1" desc:"Region of the s3 backend bucket"`
+	BlobstoreS3Bucket          string `split_words:"true" desc:"Bucket blobs are stored in by the s3 backend"`
+	BlobstoreS3AccessKeyID     string `split_words:"true" desc:"Access key ID used by the s3 backend"`
+	BlobstoreS3SecretAccessKey string `split_words:"true" desc:"Secret access key used by the s3 backend"`
+
 	FastPassRescueMultisig common.Address `split_words:"true" desc:"Address to add to FastPassed newsroom multisigs"`
 	TcrApplicationTokens   int64          `split_words:"true" desc:"Number of tokens needed to apply to registry"`
 
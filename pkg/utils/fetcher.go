@@ -0,0 +1,218 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	defaultFetchTimeout      = 15 * time.Second
+	defaultFetchMaxBodyBytes = 5 * 1024 * 1024
+	defaultFetchMaxRedirects = 5
+)
+
+var (
+	// ErrFetchBlockedAddress is returned when a URL resolves to a private, loopback or otherwise non-public address
+	ErrFetchBlockedAddress = errors.New("url resolves to a blocked address")
+	// ErrFetchBadScheme is returned when fetching a URL that is not http or https
+	ErrFetchBadScheme = errors.New("url scheme must be http or https")
+	// ErrFetchTooManyRedirects is returned when a URL redirects more times than allowed
+	ErrFetchTooManyRedirects = errors.New("too many redirects")
+	// ErrFetchBodyTooLarge is returned when a response body is larger than allowed
+	ErrFetchBodyTooLarge = errors.New("response body too large")
+
+	blockedNetworks = mustParseCIDRs(
+		"0.0.0.0/8",      // "this" network
+		"10.0.0.0/8",     // private
+		"100.64.0.0/10",  // carrier-grade NAT
+		"127.0.0.0/8",    // loopback
+		"169.254.0.0/16", // link local, includes cloud metadata endpoints
+		"172.16.0.0/12",  // private
+		"192.0.0.0/24",   // IETF protocol assignments
+		"192.168.0.0/16", // private
+		"198.18.0.0/15",  // benchmarking
+		"224.0.0.0/4",    // multicast
+		"240.0.0.0/4",    // reserved, includes broadcast
+		"::/128",         // unspecified
+		"::1/128",        // loopback
+		"64:ff9b::/96",   // IPv4/IPv6 translation
+		"fc00::/7",       // unique local
+		"fe80::/10",      // link local
+		"ff00::/8",       // multicast
+	)
+)
+
+// FetcherConfig configures a Fetcher
+type FetcherConfig struct {
+	Timeout      time.Duration
+	MaxBodyBytes int64
+	MaxRedirects int
+	// AllowPrivateNetworks disables address filtering, only for local development and tests
+	AllowPrivateNetworks bool
+}
+
+// FetchResult is a fully read response to a Fetch
+type FetchResult struct {
+	// URL is the final URL after redirects
+	URL        string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Fetcher retrieves user submitted URLs. Only public addresses are dialed, and requests are bounded
+// by a timeout, a maximum body size and a maximum number of redirects
+type Fetcher struct {
+	client       *http.Client
+	maxBodyBytes int64
+}
+
+// NewFetcher builds a new Fetcher
+func NewFetcher(config FetcherConfig) *Fetcher {
+	if config.Timeout <= 0 {
+		config.Timeout = defaultFetchTimeout
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = defaultFetchMaxBodyBytes
+	}
+	if config.MaxRedirects <= 0 {
+		config.MaxRedirects = defaultFetchMaxRedirects
+	}
+
+	dialer := &net.Dialer{Timeout: config.Timeout, KeepAlive: 30 * time.Second}
+	transport := &http.Transport{
+		// never use an environment proxy, it would be dialed instead of the checked address
+		Proxy:                 nil,
+		DialContext:           safeDialContext(dialer, net.DefaultResolver, config.AllowPrivateNetworks),
+		MaxIdleConns:          20,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	maxRedirects := config.MaxRedirects
+	return &Fetcher{
+		client: &http.Client{
+			Timeout:   config.Timeout,
+			Transport: transport,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) > maxRedirects {
+					return ErrFetchTooManyRedirects
+				}
+				if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+					return ErrFetchBadScheme
+				}
+				return nil
+			},
+		},
+		maxBodyBytes: config.MaxBodyBytes,
+	}
+}
+
+// NewFetcherFromConfig builds a new Fetcher using the graphql config
+func NewFetcherFromConfig(config *GraphQLConfig) *Fetcher {
+	return NewFetcher(FetcherConfig{
+		Timeout:              time.Duration(config.FetchTimeoutSecs) * time.Second,
+		MaxBodyBytes:         config.FetchMaxBodyBytes,
+		MaxRedirects:         config.FetchMaxRedirects,
+		AllowPrivateNetworks: config.FetchAllowPrivateNetworks,
+	})
+}
+
+// Fetch performs a GET request for the URL and reads the whole response body
+func (f *Fetcher) Fetch(rawURL string) (*FetchResult, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, ErrFetchBadScheme
+	}
+
+	resp, err := f.client.Get(parsed.String())
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			return nil, unwrapFetchError(urlErr.Err)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, f.maxBodyBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > f.maxBodyBytes {
+		return nil, ErrFetchBodyTooLarge
+	}
+
+	return &FetchResult{
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	}, nil
+}
+
+// IsPublicIP returns whether the address is routable on the public internet
+func IsPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// safeDialContext resolves the host itself and dials the checked address, so a second DNS lookup
+// cannot swap in a private address after the check
+func safeDialContext(dialer *net.Dialer, resolver *net.Resolver, allowPrivate bool) func(ctx context.Context, network string, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network string, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		ips, err := resolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		if len(ips) == 0 {
+			return nil, &net.DNSError{Err: "no such host", Name: host}
+		}
+		for _, ip := range ips {
+			if !allowPrivate && !IsPublicIP(ip.IP) {
+				return nil, ErrFetchBlockedAddress
+			}
+		}
+
+		return dialer.DialContext(ctx, network, net.JoinHostPort(ips[0].IP.String(), port))
+	}
+}
+
+func unwrapFetchError(err error) error {
+	if opErr, ok := err.(*net.OpError); ok && opErr.Err == ErrFetchBlockedAddress {
+		return ErrFetchBlockedAddress
+	}
+	return err
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
@@ -0,0 +1,105 @@
+package utils_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joincivil/civil-api-server/pkg/utils"
+)
+
+func newFetcherTestServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><head><title>hello</title></head></html>")) // nolint: errcheck
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("a", 2048))) // nolint: errcheck
+	})
+	mux.HandleFunc("/redirect/1", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/page", http.StatusFound)
+	})
+	mux.HandleFunc("/redirect/2", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/redirect/1", http.StatusFound)
+	})
+	mux.HandleFunc("/redirect/ftp", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "ftp://example.com/file", http.StatusFound)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestFetcherBlocksPrivateAddresses(t *testing.T) {
+	server := newFetcherTestServer()
+	defer server.Close()
+
+	fetcher := utils.NewFetcher(utils.FetcherConfig{})
+	_, err := fetcher.Fetch(server.URL + "/page")
+	if err != utils.ErrFetchBlockedAddress {
+		t.Fatalf("expected loopback address to be blocked, got %v", err)
+	}
+	_, err = fetcher.Fetch("http://localhost:1/page")
+	if err != utils.ErrFetchBlockedAddress {
+		t.Fatalf("expected localhost to be blocked, got %v", err)
+	}
+	_, err = fetcher.Fetch("file:///etc/passwd")
+	if err != utils.ErrFetchBadScheme {
+		t.Fatalf("expected non http scheme to be rejected, got %v", err)
+	}
+
+	blocked := []string{"127.0.0.1", "10.1.2.3", "172.20.0.1", "192.168.1.1", "169.254.169.254", "100.64.0.1", "0.0.0.0", "::1", "fd00::1", "fe80::1", "::ffff:127.0.0.1"}
+	for _, addr := range blocked {
+		if utils.IsPublicIP(net.ParseIP(addr)) {
+			t.Fatalf("expected %v to be blocked", addr)
+		}
+	}
+	public := []string{"8.8.8.8", "151.101.1.69", "2606:4700:4700::1111"}
+	for _, addr := range public {
+		if !utils.IsPublicIP(net.ParseIP(addr)) {
+			t.Fatalf("expected %v to be allowed", addr)
+		}
+	}
+}
+
+func TestFetcherLimits(t *testing.T) {
+	server := newFetcherTestServer()
+	defer server.Close()
+
+	fetcher := utils.NewFetcher(utils.FetcherConfig{
+		MaxBodyBytes:         1024,
+		MaxRedirects:         1,
+		AllowPrivateNetworks: true,
+	})
+
+	result, err := fetcher.Fetch(server.URL + "/page")
+	if err != nil {
+		t.Fatalf("was not expecting an error: %v", err)
+	}
+	if result.StatusCode != http.StatusOK || !strings.Contains(string(result.Body), "hello") {
+		t.Fatalf("expected page body to be read")
+	}
+
+	_, err = fetcher.Fetch(server.URL + "/large")
+	if err != utils.ErrFetchBodyTooLarge {
+		t.Fatalf("expected body too large error, got %v", err)
+	}
+
+	result, err = fetcher.Fetch(server.URL + "/redirect/1")
+	if err != nil {
+		t.Fatalf("was not expecting an error following one redirect: %v", err)
+	}
+	if result.URL != server.URL+"/page" {
+		t.Fatalf("expected final url after redirect, got %v", result.URL)
+	}
+
+	_, err = fetcher.Fetch(server.URL + "/redirect/2")
+	if err != utils.ErrFetchTooManyRedirects {
+		t.Fatalf("expected too many redirects error, got %v", err)
+	}
+
+	_, err = fetcher.Fetch(server.URL + "/redirect/ftp")
+	if err != utils.ErrFetchBadScheme {
+		t.Fatalf("expected redirect to non http scheme to be rejected, got %v", err)
+	}
+}
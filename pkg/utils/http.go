@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NotModified checks the conditional GET headers of a request against the current ETag and last modified
+// time of a resource, If-None-Match takes precedence over If-Modified-Since. A zero `lastModified` is ignored
+func NotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if since := r.Header.Get("If-Modified-Since"); since != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(since)
+		return err == nil && !lastModified.After(t)
+	}
+	return false
+}
+
+// RequestURL returns the absolute URL of a request without its query string, using X-Forwarded-Proto
+// for the scheme when the server is behind a proxy
+func RequestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return fmt.Sprintf("%v://%v%v", scheme, r.Host, r.URL.Path)
+}
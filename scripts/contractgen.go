@@ -0,0 +1,74 @@
+// +build ignore
+
+// This script assembles the contracts in /contracts with the go-ethereum assembler and generates
+// their Go bindings with the abigen library, as solc isn't needed for these small contracts.
+// Run from the repo root: go run scripts/contractgen.go
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/asm"
+)
+
+const outputDir = "pkg/generated/contract"
+
+var contracts = []struct {
+	name string
+	file string
+}{
+	{"PaymentForwarderContract", "paymentforwarder.go"},
+}
+
+func main() {
+	for _, contract := range contracts {
+		source := filepath.Join("contracts", contract.name[:len(contract.name)-len("Contract")])
+		runtime, err := assemble(source + ".easm")
+		if err != nil {
+			log.Fatalf("error assembling %v: %v", source, err)
+		}
+		abi, err := ioutil.ReadFile(source + ".abi")
+		if err != nil {
+			log.Fatalf("error reading abi: %v", err)
+		}
+
+		code, err := bind.Bind([]string{contract.name}, []string{string(abi)}, []string{deployCode(runtime)}, nil, "contract", bind.LangGo, nil)
+		if err != nil {
+			log.Fatalf("error generating binding for %v: %v", contract.name, err)
+		}
+		if err = os.MkdirAll(outputDir, 0755); err != nil {
+			log.Fatalf("error creating %v: %v", outputDir, err)
+		}
+		if err = ioutil.WriteFile(filepath.Join(outputDir, contract.file), []byte(code), 0644); err != nil {
+			log.Fatalf("error writing binding: %v", err)
+		}
+	}
+}
+
+// assemble compiles an easm file to hex runtime code
+func assemble(path string) (string, error) {
+	source, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	compiler := asm.NewCompiler(false)
+	compiler.Feed(asm.Lex(source, false))
+	runtime, errs := compiler.Compile()
+	if len(errs) > 0 {
+		return "", fmt.Errorf("%v", errs)
+	}
+	return runtime, nil
+}
+
+// deployCode prefixes runtime code with a constructor that returns it:
+// PUSH2 size DUP1 PUSH1 12 PUSH1 0 CODECOPY PUSH1 0 RETURN
+func deployCode(runtime string) string {
+	size := len(runtime) / 2
+	return fmt.Sprintf("61%04x80600c6000396000f3%s", size, runtime)
+}